@@ -104,6 +104,16 @@ func (d *Device) ScanInput(ctx context.Context) error {
 	}
 }
 
+// Fd returns the file descriptor of the device, so that it can be polled (e.g. with epoll) instead of scanned
+func (d *Device) Fd() int {
+	return int(d.handle.Fd())
+}
+
+// ReadEvent reads a single event from the device, blocking until one is available
+func (d *Device) ReadEvent() (*InputEvent, error) {
+	return readOneInputEvent(d.handle)
+}
+
 func (d *Device) SetLedOn(led Led) error {
 	return d.writeEvent(EvLed, uint16(led), 0x01)
 }
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"image"
 	"os"
 	"os/exec"
+	"os/signal"
 
 	//"os/exec"
 	"runtime/debug"
+	"syscall"
 	"time"
 
 	"nyctal-dri/evdev"
@@ -21,7 +24,97 @@ import (
 var POINTER model.Pointer
 var KEYBOARD = model.NewKeyboardModel()
 
-func SetupMouse(workspace model.Workspace) func() error {
+// mouseHandler returns a function that converts mouse device events into workspace pointer events
+func mouseHandler(workspace model.Workspace) func(ev evdev.InputEvent) {
+	localX := float32(0.0)
+	localY := float32(0.0)
+	return func(ev evdev.InputEvent) {
+		utils.Debug(0, "mouse handler", fmt.Sprintf("type %d code %d", ev.Type, ev.Code))
+		switch ev.Type {
+		case evdev.EvKey:
+			pev := model.PointerEvent{
+				Button: &model.PointerButtonEvent{Time: uint32(time.Now().UnixMilli()), Button: uint32(ev.Code), State: uint32(ev.Value)}}
+			POINTER.ProcessPointerEvent(pev)
+			workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
+		case evdev.EvRel:
+			if ev.Code == 0x00 {
+				// abs x
+				localX += float32(ev.Value)
+				if localX < 0 {
+					localX = 0
+				}
+				pev := model.PointerEvent{
+					Move: &model.PointerMoveEvent{Time: uint32(time.Now().UnixMilli()), MX: localX, MY: localY},
+				}
+				POINTER.ProcessPointerEvent(pev)
+				workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
+			}
+			if ev.Code == 0x01 {
+				// abs y
+				localY += float32(ev.Value)
+				if localY < 0 {
+					localY = 0
+				}
+				pev := model.PointerEvent{
+					Move: &model.PointerMoveEvent{Time: uint32(time.Now().UnixMilli()), MX: localX, MY: localY},
+				}
+				POINTER.ProcessPointerEvent(pev)
+				workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
+			}
+			if ev.Code == 0x08 {
+				pev := model.PointerEvent{
+					Axis: &model.PointerAxisEvent{Time: uint32(time.Now().UnixMilli()), Value: float32(ev.Value)},
+				}
+				POINTER.ProcessPointerEvent(pev)
+				workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
+			}
+		}
+	}
+}
+
+// keyboardHandler returns a function that converts keyboard device events into workspace keyboard events
+// see include/uapi/linux/input-event-codes.h
+func keyboardHandler(workspace model.Workspace) func(ev evdev.InputEvent) {
+	return func(ev evdev.InputEvent) {
+		utils.Debug(0, "input handler", fmt.Sprintf("type %d code %d value: %d", ev.Type, ev.Code, ev.Value))
+		kev := model.KeyboardEvent{Time: uint32(time.Now().UnixMilli()), Key: uint32(ev.Code), State: uint32(ev.Value)}
+		KEYBOARD.ProcessKeyboardEvent(kev)
+		workspace.ProcessKeyboardEvent(POINTER, *KEYBOARD, kev)
+	}
+}
+
+// scanDevice feeds every event from the device to handler. If loop is not nil the device is
+// read from the event loop, otherwise it is scanned on its own goroutines.
+func scanDevice(dev *evdev.Device, loop *wayland.EventLoop, handler func(ev evdev.InputEvent)) {
+	if loop != nil {
+		err := loop.AddSource(dev.Fd(), func() error {
+			ev, err := dev.ReadEvent()
+			if err != nil {
+				return err
+			}
+			if ev != nil {
+				handler(*ev)
+			}
+			return nil
+		})
+		if err != nil {
+			utils.Debug(0, "input handler", fmt.Sprintf("failed to poll input: %v", err))
+		}
+		return
+	}
+	go func() {
+		err := dev.ScanInput(context.Background())
+		utils.Debug(0, "input handler", fmt.Sprintf("failed to scan input: %v", err))
+	}()
+	go func() {
+		utils.Debug(0, "input handler", "starting input handler")
+		for {
+			handler(<-dev.Input)
+		}
+	}()
+}
+
+func SetupMouse(workspace model.Workspace, loop *wayland.EventLoop) func() error {
 
 	mouseDev := evdev.FindMouseDevice()
 	if mouseDev != "" {
@@ -29,60 +122,7 @@ func SetupMouse(workspace model.Workspace) func() error {
 		if err != nil {
 			fmt.Printf("[error] %s", err)
 		} else {
-			go func() {
-				err := dev.ScanInput(context.Background())
-				utils.Debug(0, "input handler", fmt.Sprintf("failed to scan input: %v", err))
-			}()
-			go func() {
-				utils.Debug(0, "mouse handler", "starting input handler")
-				localX := float32(0.0)
-				localY := float32(0.0)
-				for {
-					//	utils.Debug("input handler", "waiting...")
-					ev := <-dev.Input
-					utils.Debug(0, "mouse handler", fmt.Sprintf("type %d code %d", ev.Type, ev.Code))
-					switch ev.Type {
-					case evdev.EvKey:
-						pev := model.PointerEvent{
-							Button: &model.PointerButtonEvent{Time: uint32(time.Now().UnixMilli()), Button: uint32(ev.Code), State: uint32(ev.Value)}}
-						POINTER.ProcessPointerEvent(pev)
-						workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
-					case evdev.EvRel:
-						if ev.Code == 0x00 {
-							// abs x
-							localX += float32(ev.Value)
-							if localX < 0 {
-								localX = 0
-							}
-							pev := model.PointerEvent{
-								Move: &model.PointerMoveEvent{Time: uint32(time.Now().UnixMilli()), MX: localX, MY: localY},
-							}
-							POINTER.ProcessPointerEvent(pev)
-							workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
-						}
-						if ev.Code == 0x01 {
-							// abs y
-							localY += float32(ev.Value)
-							if localY < 0 {
-								localY = 0
-							}
-							pev := model.PointerEvent{
-								Move: &model.PointerMoveEvent{Time: uint32(time.Now().UnixMilli()), MX: localX, MY: localY},
-							}
-							POINTER.ProcessPointerEvent(pev)
-							workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
-						}
-						if ev.Code == 0x08 {
-							pev := model.PointerEvent{
-								Axis: &model.PointerAxisEvent{Time: uint32(time.Now().UnixMilli()), Value: float32(ev.Value)},
-							}
-							POINTER.ProcessPointerEvent(pev)
-							workspace.ProcessPointerEvent(POINTER, *KEYBOARD, pev)
-						}
-					}
-
-				}
-			}()
+			scanDevice(dev, loop, mouseHandler(workspace))
 			return f
 		}
 
@@ -92,8 +132,7 @@ func SetupMouse(workspace model.Workspace) func() error {
 	return func() error { return nil }
 }
 
-// see include/uapi/linux/input-event-codes.h
-func SetupInput(workspace model.Workspace) func() error {
+func SetupInput(workspace model.Workspace, loop *wayland.EventLoop) func() error {
 
 	kdev := evdev.FindAllKeyboardDevices()
 	if len(kdev) > 0 {
@@ -101,22 +140,7 @@ func SetupInput(workspace model.Workspace) func() error {
 		if err != nil {
 			fmt.Printf("[error] %s", err)
 		} else {
-			go func() {
-				err := dev.ScanInput(context.Background())
-				utils.Debug(0, "input handler", fmt.Sprintf("failed to scan input: %v", err))
-			}()
-			go func() {
-				utils.Debug(0, "input handler", "starting input handler")
-
-				for {
-					//	utils.Debug("input handler", "waiting...")
-					ev := <-dev.Input
-					utils.Debug(0, "input handler", fmt.Sprintf("type %d code %d value: %d", ev.Type, ev.Code, ev.Value))
-					kev := model.KeyboardEvent{Time: uint32(time.Now().UnixMilli()), Key: uint32(ev.Code), State: uint32(ev.Value)}
-					KEYBOARD.ProcessKeyboardEvent(kev)
-					workspace.ProcessKeyboardEvent(POINTER, *KEYBOARD, kev)
-				}
-			}()
+			scanDevice(dev, loop, keyboardHandler(workspace))
 			return f
 		}
 	}
@@ -124,6 +148,9 @@ func SetupInput(workspace model.Workspace) func() error {
 }
 
 func main() {
+	useEpoll := flag.Bool("epoll", false, "dispatch clients, input and rendering from a single epoll loop")
+	display := flag.String("display", "", "socket name in XDG_RUNTIME_DIR (default: first free wayland-N)")
+	flag.Parse()
 
 	debug.SetPanicOnFault(false)
 
@@ -141,42 +168,97 @@ func main() {
 		utils.Debug(0, "nyctal", fmt.Sprintf("dri established %v %v", width, height))
 	}
 
-	// reset UDS
-	os.RemoveAll("/tmp/nyctal/")
-	os.Mkdir("/tmp/nyctal/", 0700)
+	if os.Getenv("XDG_RUNTIME_DIR") == "" {
+		os.Mkdir("/tmp/nyctal/", 0700)
+		os.Setenv("XDG_RUNTIME_DIR", "/tmp/nyctal/")
+	}
 
-	ws, err := wayland.NewServer("/tmp/nyctal/nyctal-0", wspace)
+	var ws *wayland.WaylandServer
+	var err error
+	if *display != "" {
+		ws, err = wayland.NewNamedServer(*display, wspace)
+	} else {
+		ws, err = wayland.NewAutoServer(wspace)
+	}
 	if err != nil {
 		fmt.Printf("[error] %s\n", err)
 		os.Exit(1)
 	}
-	go ws.Listen()
-	closeInput := SetupInput(wspace)
+	// the render loop never returns, so shut down cleanly (removing the socket and lock) on a signal
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ws.Shutdown(ctx)
+		os.Exit(0)
+	}()
+	// the default output is resized to the display
+	for _, name := range ws.Outputs() {
+		ws.ResizeOutput(name, image.Rect(0, 0, int(width), int(height)))
+	}
+	// launched apps inherit the display
+	os.Setenv("WAYLAND_DISPLAY", ws.Display())
+	fmt.Printf("listening on XDG_RUNTIME_DIR=%s WAYLAND_DISPLAY=%s\n", os.Getenv("XDG_RUNTIME_DIR"), ws.Display())
+	var loop *wayland.EventLoop
+	if *useEpoll {
+		loop, err = ws.NewEventLoop()
+		if err != nil {
+			fmt.Printf("[error] %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		go ws.Listen()
+	}
+	closeInput := SetupInput(wspace, loop)
 	defer closeInput()
-	closeMInput := SetupMouse(wspace)
+	closeMInput := SetupMouse(wspace, loop)
 	defer closeMInput()
 
 	fmt.Printf("Starting Nyctal...\n")
 	lastFrame := time.Now()
 
+	// the launcher is connected directly (through WAYLAND_SOCKET) so it needs no display socket
 	cmd := exec.Command("/bin/elope")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if loop != nil {
+		_, err = loop.LaunchClient(cmd)
+	} else {
+		_, err = ws.LaunchClient(cmd)
+	}
+	if err != nil {
+		fmt.Printf("[error] could not launch elope: %s\n", err)
+		os.Exit(1)
+	}
 	go func() {
-		time.Sleep(time.Second * 2)
-		stdoutStderr, err := cmd.CombinedOutput()
-		if err != nil {
-		}
-		fmt.Printf("%s\n", stdoutStderr)
+		err := cmd.Wait()
+		fmt.Printf("elope exited: %v\n", err)
 		os.Exit(1)
 	}()
 
+	render := func() {
+		buffer := model.EmptyBGRA(image.Rect(0, 0, int(width), int(height)))
+		wspace.Buffer(buffer, int(width), int(height))
+		output.RenderBuffer(buffer)
+	}
+
+	if loop != nil {
+		// rendering happens on the event loop, so it never races with clients
+		loop.AddTimer(time.Millisecond*5, render)
+		if err := loop.Run(); err != nil {
+			fmt.Printf("[error] %s\n", err)
+			os.Exit(1)
+		}
+	}
+
 	for {
 		// time check is here to prevent spamming the workspace render buffer (which may attempt to e.g. reconfigure windwows)
 		// there is no point in attempting to generate frames any faster than 200fps
 		// todo: in the future we should replace this with a NeedsRender() check
 		if time.Since(lastFrame) >= time.Millisecond*5 {
-			buffer := model.EmptyBGRA(image.Rect(0, 0, int(width), int(height)))
-			wspace.Buffer(buffer, int(width), int(height))
-			output.RenderBuffer(buffer)
+			render()
 			lastFrame = time.Now()
 		}
 	}
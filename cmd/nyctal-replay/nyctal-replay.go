@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nyctal/wayland"
+	"nyctal/workspace"
+)
+
+// replay runs a capture against a fresh server, returning whether it behaved as recorded
+func replay(path string, verbose bool) (bool, error) {
+	capture, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer capture.Close()
+
+	dir, err := os.MkdirTemp("", "nyctal-replay")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(dir)
+
+	// the server logs every request to stdout
+	stdout := os.Stdout
+	if !verbose {
+		if devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			os.Stdout = devnull
+			defer devnull.Close()
+		}
+	}
+	ws, err := wayland.NewServer(filepath.Join(dir, "wayland-replay"), workspace.NewDragOverlay())
+	if err != nil {
+		os.Stdout = stdout
+		return false, err
+	}
+	report, err := ws.Replay(capture)
+	ws.Close()
+	os.Stdout = stdout
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("%s: %d requests, %d events, %d frames\n", path, report.Requests, report.Events, report.Frames)
+	if !report.Ok() {
+		fmt.Printf("%s: mismatch at %s\n", path, report.Mismatch)
+	}
+	return report.Ok(), nil
+}
+
+func main() {
+	verbose := flag.Bool("v", false, "print the server's debug output")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: nyctal-replay [-v] capture.wlcap...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		ok, err := replay(path, *verbose)
+		if err != nil {
+			fmt.Printf("[error] %s: %s\n", path, err)
+		}
+		failed = failed || !ok
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
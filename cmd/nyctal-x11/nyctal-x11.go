@@ -15,6 +15,7 @@ extern void ResizeWindow(struct mfb_window*, int, int);
 */
 import "C"
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -121,6 +122,19 @@ var lock sync.Mutex
 var WIDTH int
 var HEIGHT int
 
+// server is set once the wayland server has started, its outputs follow the size of the window
+var server *wayland.WaylandServer
+
+// resizeOutputs makes the outputs match the window, lock must be held
+func resizeOutputs() {
+	if server == nil {
+		return
+	}
+	for _, name := range server.Outputs() {
+		server.ResizeOutput(name, image.Rect(0, 0, WIDTH, HEIGHT))
+	}
+}
+
 var POINTER model.Pointer
 var KEYBOARD = model.NewKeyboardModel()
 
@@ -135,6 +149,7 @@ func ResizeWindow(window *C.struct_mfb_window, width C.int, height C.int) {
 	buffer_len = new_buffer_len
 	WIDTH = w
 	HEIGHT = h
+	resizeOutputs()
 }
 
 // helper function to convert a image/color to a C.uint used by minifb's buffer
@@ -189,15 +204,28 @@ func main() {
 	C.mfb_set_mouse_scroll_callback(window, (C.mfb_mouse_scroll_func)(C.MouseScroll))
 	C.mfb_set_resize_callback(window, (C.mfb_resize_func)(C.ResizeWindow))
 
-	// reset UDS
-	os.RemoveAll("/tmp/nyctal/")
-	os.Mkdir("/tmp/nyctal/", 0700)
+	if os.Getenv("XDG_RUNTIME_DIR") == "" {
+		os.Mkdir("/tmp/nyctal/", 0700)
+		os.Setenv("XDG_RUNTIME_DIR", "/tmp/nyctal/")
+	}
 
-	ws, err := wayland.NewServer("/tmp/nyctal/nyctal-0", wspace)
+	ws, err := wayland.NewAutoServer(wspace)
 	if err != nil {
 		utils.Error(0, "nyctal", err.Error())
 		os.Exit(1)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ws.Shutdown(ctx)
+	}()
+	lock.Lock()
+	server = ws
+	resizeOutputs()
+	lock.Unlock()
+	// launched apps inherit the display
+	os.Setenv("WAYLAND_DISPLAY", ws.Display())
+	utils.Debug(0, "nyctal", fmt.Sprintf("listening on XDG_RUNTIME_DIR=%s WAYLAND_DISPLAY=%s", os.Getenv("XDG_RUNTIME_DIR"), ws.Display()))
 	go ws.Listen()
 	//wspace.ProcessFocus()
 
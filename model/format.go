@@ -3,15 +3,121 @@ package model
 import (
 	"image"
 	"image/color"
+	"maps"
+	"slices"
 )
 
+// Format is a wl_shm pixel format, the values are those of the wl_shm.format enum (DRM fourcc codes
+// other than for ARGB8888 and XRGB8888)
 type Format int
 
 const (
-	FormatARGB = Format(0)
-	FormatXRGB = Format(1)
+	FormatARGB        = Format(0)
+	FormatXRGB        = Format(1)
+	FormatXRGB4444    = Format(0x32315258)
+	FormatARGB4444    = Format(0x32315241)
+	FormatXRGB1555    = Format(0x35315258)
+	FormatARGB1555    = Format(0x35315241)
+	FormatRGB565      = Format(0x36314752)
+	FormatBGR565      = Format(0x36314742)
+	FormatRGB888      = Format(0x34324752)
+	FormatBGR888      = Format(0x34324742)
+	FormatXBGR8888    = Format(0x34324258)
+	FormatABGR8888    = Format(0x34324241)
+	FormatRGBX8888    = Format(0x34325852)
+	FormatRGBA8888    = Format(0x34324152)
+	FormatBGRX8888    = Format(0x34325842)
+	FormatBGRA8888    = Format(0x34324142)
+	FormatXRGB2101010 = Format(0x30335258)
+	FormatARGB2101010 = Format(0x30335241)
+	FormatXBGR2101010 = Format(0x30334258)
+	FormatABGR2101010 = Format(0x30334241)
 )
 
+// channel is where a color channel is in a pixel, bits is 0 for a channel the format does not have
+type channel struct {
+	shift uint8
+	bits  uint8
+}
+
+// formatLayout describes a packed format, each pixel is a little endian value of bytesPerPixel bytes.
+// Formats without alpha are opaque.
+type formatLayout struct {
+	bytesPerPixel int
+	r, g, b, a    channel
+}
+
+var formats = map[Format]formatLayout{
+	FormatARGB:        {4, channel{16, 8}, channel{8, 8}, channel{0, 8}, channel{24, 8}},
+	FormatXRGB:        {4, channel{16, 8}, channel{8, 8}, channel{0, 8}, channel{}},
+	FormatXRGB4444:    {2, channel{8, 4}, channel{4, 4}, channel{0, 4}, channel{}},
+	FormatARGB4444:    {2, channel{8, 4}, channel{4, 4}, channel{0, 4}, channel{12, 4}},
+	FormatXRGB1555:    {2, channel{10, 5}, channel{5, 5}, channel{0, 5}, channel{}},
+	FormatARGB1555:    {2, channel{10, 5}, channel{5, 5}, channel{0, 5}, channel{15, 1}},
+	FormatRGB565:      {2, channel{11, 5}, channel{5, 6}, channel{0, 5}, channel{}},
+	FormatBGR565:      {2, channel{0, 5}, channel{5, 6}, channel{11, 5}, channel{}},
+	FormatRGB888:      {3, channel{16, 8}, channel{8, 8}, channel{0, 8}, channel{}},
+	FormatBGR888:      {3, channel{0, 8}, channel{8, 8}, channel{16, 8}, channel{}},
+	FormatXBGR8888:    {4, channel{0, 8}, channel{8, 8}, channel{16, 8}, channel{}},
+	FormatABGR8888:    {4, channel{0, 8}, channel{8, 8}, channel{16, 8}, channel{24, 8}},
+	FormatRGBX8888:    {4, channel{24, 8}, channel{16, 8}, channel{8, 8}, channel{}},
+	FormatRGBA8888:    {4, channel{24, 8}, channel{16, 8}, channel{8, 8}, channel{0, 8}},
+	FormatBGRX8888:    {4, channel{8, 8}, channel{16, 8}, channel{24, 8}, channel{}},
+	FormatBGRA8888:    {4, channel{8, 8}, channel{16, 8}, channel{24, 8}, channel{0, 8}},
+	FormatXRGB2101010: {4, channel{20, 10}, channel{10, 10}, channel{0, 10}, channel{}},
+	FormatARGB2101010: {4, channel{20, 10}, channel{10, 10}, channel{0, 10}, channel{30, 2}},
+	FormatXBGR2101010: {4, channel{0, 10}, channel{10, 10}, channel{20, 10}, channel{}},
+	FormatABGR2101010: {4, channel{0, 10}, channel{10, 10}, channel{20, 10}, channel{30, 2}},
+}
+
+// Formats returns every supported format, ARGB and XRGB (which every client can rely on) first
+func Formats() []Format {
+	return slices.Sorted(maps.Keys(formats))
+}
+
+// Supported reports whether pixels of the format can be converted to BGRA
+func (f Format) Supported() bool {
+	_, ok := formats[f]
+	return ok
+}
+
+// Opaque reports whether the format has no alpha channel, so that every pixel is opaque
+func (f Format) Opaque() bool {
+	layout, ok := formats[f]
+	return ok && layout.a.bits == 0
+}
+
+// BytesPerPixel returns the size of a pixel of the format, or 0 if the format is not supported
+func (f Format) BytesPerPixel() int {
+	return formats[f].bytesPerPixel
+}
+
+// scale expands a channel to 8 bits
+func (c channel) scale(v uint32) byte {
+	if c.bits == 0 {
+		return 0xff
+	}
+	mask := uint32(1)<<c.bits - 1
+	return byte((((v>>c.shift)&mask)*255 + mask/2) / mask)
+}
+
+// convertRow converts width pixels of the format in src to BGRA in dst
+func (f Format) convertRow(dst, src []byte, width int) {
+	layout := formats[f]
+	bpp := layout.bytesPerPixel
+	for x := 0; x < width; x++ {
+		var v uint32
+		for i := bpp - 1; i >= 0; i-- {
+			v = v<<8 | uint32(src[x*bpp+i])
+		}
+		d := dst[x*4 : x*4+4 : x*4+4]
+		d[0] = layout.b.scale(v)
+		d[1] = layout.g.scale(v)
+		d[2] = layout.r.scale(v)
+		d[3] = layout.a.scale(v)
+	}
+}
+
 // This file is subject to a 1-clause BSD license.
 // Its contents can be found in the enclosed LICENSE file.
 
@@ -62,28 +168,61 @@ func (i *BGRA) DrawRect(x1, y1, x2, y2 int, col color.RGBA) {
 	i.VLine(x2, y1, y2, col)
 }
 
+// Update copies the damaged area of pixels (laid out with stride) into the image, damage outside of
+// the image or beyond the end of pixels is ignored
 func (i *BGRA) Update(pixels []byte, damage image.Rectangle, stride int) {
-
-	if i.Bounds().Max.X < damage.Bounds().Min.X {
+	damage = damage.Intersect(i.Rect)
+	if damage.Empty() {
 		return
 	}
+	rowLen := damage.Dx() * 4
+	for y := damage.Min.Y; y < damage.Max.Y; y++ {
+		src := (y-i.Rect.Min.Y)*stride + (damage.Min.X-i.Rect.Min.X)*4
+		if src < 0 || src+rowLen > len(pixels) {
+			return
+		}
+		dst := i.PixOffset(damage.Min.X, y)
+		copy(i.Pix[dst:dst+rowLen], pixels[src:src+rowLen])
+	}
+}
 
-	minY := min(i.Rect.Bounds().Max.Y, damage.Min.Y)
-	maxY := min(i.Rect.Bounds().Max.Y, damage.Max.Y)
-
-	for sy := minY; sy < maxY; sy++ {
-		damageY := sy * i.Stride
-
-		damageStartX := min(i.Rect.Bounds().Max.X, damage.Min.X)
-		damageEndX := min(i.Rect.Bounds().Max.X, damage.Max.X)
-
-		damageStart := damageY + (damageStartX * 4)
-		damageEnd := damageY + (damageEndX * 4)
+// NewBGRAFormat is NewBGRA for pixels of any supported format
+func NewBGRAFormat(pixels []byte, rect image.Rectangle, stride int, format Format) *BGRA {
+	if format == FormatARGB {
+		return NewBGRA(pixels, rect, stride)
+	}
+	img := EmptyBGRA(rect)
+	img.UpdateFormat(pixels, rect, stride, format)
+	return img
+}
 
-		copy(i.Pix[damageStart:damageEnd], pixels[damageStart:damageEnd])
+// UpdateFormat is Update for pixels of any supported format, which are converted to BGRA
+func (i *BGRA) UpdateFormat(pixels []byte, damage image.Rectangle, stride int, format Format) {
+	if format == FormatARGB {
+		i.Update(pixels, damage, stride)
+		return
+	}
+	bpp := format.BytesPerPixel()
+	damage = damage.Intersect(i.Rect)
+	if damage.Empty() || bpp == 0 {
+		return
+	}
+	rowLen := damage.Dx() * bpp
+	for y := damage.Min.Y; y < damage.Max.Y; y++ {
+		src := (y-i.Rect.Min.Y)*stride + (damage.Min.X-i.Rect.Min.X)*bpp
+		if src < 0 || src+rowLen > len(pixels) {
+			return
+		}
+		dst := i.PixOffset(damage.Min.X, y)
+		format.convertRow(i.Pix[dst:dst+damage.Dx()*4], pixels[src:src+rowLen], damage.Dx())
 	}
 }
 
+// Clone returns a copy of the image that does not share its pixels
+func (i *BGRA) Clone() *BGRA {
+	return NewBGRA(i.Pix, i.Rect, i.Stride)
+}
+
 func (i *BGRA) SubImage(bounds image.Rectangle) *BGRA {
 	bounds = bounds.Intersect(i.Rect)
 	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to be inside
@@ -195,6 +334,14 @@ func DrawCopyOver(dst *BGRA, r image.Rectangle, src *BGRA, sp image.Point) {
 		spix := src.Pix[s0:]
 		for i := i0; i != i1; i += idelta {
 			s := spix[i : i+4 : i+4] // Small cap improves performance, see https://golang.org/issue/27857
+			d := dpix[i : i+4 : i+4] // Small cap improves performance, see https://golang.org/issue/27857
+			// opaque and fully transparent pixels need no blending
+			if s[3] == 0xff {
+				copy(d, s)
+				continue
+			} else if s[0]|s[1]|s[2]|s[3] == 0 {
+				continue
+			}
 			sr := uint32(s[0]) * 0x101
 			sg := uint32(s[1]) * 0x101
 			sb := uint32(s[2]) * 0x101
@@ -203,7 +350,6 @@ func DrawCopyOver(dst *BGRA, r image.Rectangle, src *BGRA, sp image.Point) {
 			// The 0x101 is here for the same reason as in drawRGBA.
 			a := (m - sa) * 0x101
 
-			d := dpix[i : i+4 : i+4] // Small cap improves performance, see https://golang.org/issue/27857
 			d[0] = uint8((uint32(d[0])*a/m + sr) >> 8)
 			d[1] = uint8((uint32(d[1])*a/m + sg) >> 8)
 			d[2] = uint8((uint32(d[2])*a/m + sb) >> 8)
@@ -213,3 +359,17 @@ func DrawCopyOver(dst *BGRA, r image.Rectangle, src *BGRA, sp image.Point) {
 		s0 += sdelta
 	}
 }
+
+// DrawCopy copies src into r of dst without blending, for areas of src known to be opaque
+func DrawCopy(dst *BGRA, r image.Rectangle, src *BGRA, sp image.Point) {
+	clip(dst, &r, src, &sp)
+	if r.Empty() {
+		return
+	}
+	rowLen := r.Dx() * 4
+	for y := 0; y < r.Dy(); y++ {
+		d := dst.PixOffset(r.Min.X, r.Min.Y+y)
+		s := src.PixOffset(sp.X, sp.Y+y)
+		copy(dst.Pix[d:d+rowLen], src.Pix[s:s+rowLen])
+	}
+}
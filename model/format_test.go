@@ -0,0 +1,75 @@
+package model
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestFormatConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		src    []byte // one pixel, little endian
+		want   []byte // BGRA
+	}{
+		{"argb8888", FormatARGB, []byte{0x33, 0x22, 0x11, 0x80}, []byte{0x33, 0x22, 0x11, 0x80}},
+		{"xrgb8888", FormatXRGB, []byte{0x33, 0x22, 0x11, 0x80}, []byte{0x33, 0x22, 0x11, 0xff}},
+		{"abgr8888", FormatABGR8888, []byte{0x11, 0x22, 0x33, 0x80}, []byte{0x33, 0x22, 0x11, 0x80}},
+		{"xbgr8888", FormatXBGR8888, []byte{0x11, 0x22, 0x33, 0x80}, []byte{0x33, 0x22, 0x11, 0xff}},
+		{"rgba8888", FormatRGBA8888, []byte{0x80, 0x33, 0x22, 0x11}, []byte{0x33, 0x22, 0x11, 0x80}},
+		{"rgbx8888", FormatRGBX8888, []byte{0x80, 0x33, 0x22, 0x11}, []byte{0x33, 0x22, 0x11, 0xff}},
+		{"bgra8888", FormatBGRA8888, []byte{0x80, 0x11, 0x22, 0x33}, []byte{0x33, 0x22, 0x11, 0x80}},
+		{"bgrx8888", FormatBGRX8888, []byte{0x80, 0x11, 0x22, 0x33}, []byte{0x33, 0x22, 0x11, 0xff}},
+		{"rgb888", FormatRGB888, []byte{0x33, 0x22, 0x11}, []byte{0x33, 0x22, 0x11, 0xff}},
+		{"bgr888", FormatBGR888, []byte{0x11, 0x22, 0x33}, []byte{0x33, 0x22, 0x11, 0xff}},
+		// r 31, g 0, b 1
+		{"rgb565", FormatRGB565, []byte{0x01, 0xf8}, []byte{0x08, 0x00, 0xff, 0xff}},
+		{"bgr565", FormatBGR565, []byte{0x01, 0xf8}, []byte{0xff, 0x00, 0x08, 0xff}},
+		// a 10, r 15, g 2, b 1
+		{"argb4444", FormatARGB4444, []byte{0x21, 0xaf}, []byte{0x11, 0x22, 0xff, 0xaa}},
+		{"xrgb4444", FormatXRGB4444, []byte{0x21, 0xaf}, []byte{0x11, 0x22, 0xff, 0xff}},
+		// a 0, r 31, g 0, b 16
+		{"argb1555", FormatARGB1555, []byte{0x10, 0x7c}, []byte{0x84, 0x00, 0xff, 0x00}},
+		{"xrgb1555", FormatXRGB1555, []byte{0x10, 0x7c}, []byte{0x84, 0x00, 0xff, 0xff}},
+		// a 1, r 1023, g 0, b 512 (swapped for the bgr formats)
+		{"argb2101010", FormatARGB2101010, []byte{0x00, 0x02, 0xf0, 0x7f}, []byte{0x80, 0x00, 0xff, 0x55}},
+		{"xrgb2101010", FormatXRGB2101010, []byte{0x00, 0x02, 0xf0, 0x7f}, []byte{0x80, 0x00, 0xff, 0xff}},
+		{"abgr2101010", FormatABGR2101010, []byte{0x00, 0x02, 0xf0, 0x7f}, []byte{0xff, 0x00, 0x80, 0x55}},
+		{"xbgr2101010", FormatXBGR2101010, []byte{0x00, 0x02, 0xf0, 0x7f}, []byte{0xff, 0x00, 0x80, 0xff}},
+	}
+	if len(tests) != len(formats) {
+		t.Errorf("%d formats are tested, %d are supported", len(tests), len(formats))
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.format.BytesPerPixel() != len(test.src) {
+				t.Fatalf("expected %d bytes per pixel, got %d", len(test.src), test.format.BytesPerPixel())
+			}
+			// every pixel with alpha is translucent, so only the formats without alpha are opaque
+			if opaque := test.want[3] == 0xff; test.format.Opaque() != opaque {
+				t.Errorf("expected Opaque to be %v", opaque)
+			}
+			img := NewBGRAFormat(test.src, image.Rect(0, 0, 1, 1), len(test.src), test.format)
+			if !bytes.Equal(img.Pix[:4], test.want) {
+				t.Errorf("expected % x, got % x", test.want, img.Pix[:4])
+			}
+		})
+	}
+}
+
+// TestFormatStride checks that rows are read at the stride, which can be larger than the row
+func TestFormatStride(t *testing.T) {
+	src := []byte{
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0xee, 0xee,
+		0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0xee, 0xee,
+	}
+	want := []byte{
+		0x01, 0x02, 0x03, 0xff, 0x04, 0x05, 0x06, 0xff,
+		0x07, 0x08, 0x09, 0xff, 0x0a, 0x0b, 0x0c, 0xff,
+	}
+	img := NewBGRAFormat(src, image.Rect(0, 0, 2, 2), 8, FormatRGB888)
+	if !bytes.Equal(img.Pix, want) {
+		t.Errorf("expected % x, got % x", want, img.Pix)
+	}
+}
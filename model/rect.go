@@ -0,0 +1,39 @@
+package model
+
+import "image"
+
+// Subtract returns the parts of r not covered by any of holes, as non-overlapping rectangles
+func Subtract(r image.Rectangle, holes []image.Rectangle) []image.Rectangle {
+	if r.Empty() {
+		return nil
+	}
+	parts := []image.Rectangle{r}
+	for _, hole := range holes {
+		var remaining []image.Rectangle
+		for _, part := range parts {
+			cut := part.Intersect(hole)
+			if cut.Empty() {
+				remaining = append(remaining, part)
+				continue
+			}
+			// the bands above and below the hole, then either side of it
+			if cut.Min.Y > part.Min.Y {
+				remaining = append(remaining, image.Rect(part.Min.X, part.Min.Y, part.Max.X, cut.Min.Y))
+			}
+			if cut.Max.Y < part.Max.Y {
+				remaining = append(remaining, image.Rect(part.Min.X, cut.Max.Y, part.Max.X, part.Max.Y))
+			}
+			if cut.Min.X > part.Min.X {
+				remaining = append(remaining, image.Rect(part.Min.X, cut.Min.Y, cut.Min.X, cut.Max.Y))
+			}
+			if cut.Max.X < part.Max.X {
+				remaining = append(remaining, image.Rect(cut.Max.X, cut.Min.Y, part.Max.X, cut.Max.Y))
+			}
+		}
+		parts = remaining
+		if len(parts) == 0 {
+			break
+		}
+	}
+	return parts
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSubtract(t *testing.T) {
+	r := image.Rect(0, 0, 10, 10)
+	tests := []struct {
+		name  string
+		holes []image.Rectangle
+		area  int
+		parts int
+	}{
+		{"none", nil, 100, 1},
+		{"disjoint", []image.Rectangle{image.Rect(20, 20, 30, 30)}, 100, 1},
+		{"touching", []image.Rectangle{image.Rect(10, 0, 20, 10)}, 100, 1},
+		{"covered", []image.Rectangle{image.Rect(-5, -5, 15, 15)}, 0, 0},
+		{"contained", []image.Rectangle{image.Rect(3, 3, 7, 7)}, 84, 4},
+		{"overlapping corner", []image.Rectangle{image.Rect(5, 5, 15, 15)}, 75, 2},
+		{"overlapping edge", []image.Rectangle{image.Rect(-5, 2, 15, 4)}, 80, 2},
+		{"overlapping holes", []image.Rectangle{image.Rect(0, 0, 6, 6), image.Rect(4, 4, 10, 10)}, 32, 2},
+		{"covered by holes", []image.Rectangle{image.Rect(0, 0, 10, 5), image.Rect(0, 5, 10, 10)}, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts := Subtract(r, test.holes)
+			if len(parts) != test.parts {
+				t.Errorf("expected %d parts, got %v", test.parts, parts)
+			}
+			// the parts must exactly cover the pixels of r outside the holes
+			covered := make(map[image.Point]int)
+			area := 0
+			for _, part := range parts {
+				if !part.In(r) || part.Empty() {
+					t.Errorf("part %v is empty or outside %v", part, r)
+				}
+				for y := part.Min.Y; y < part.Max.Y; y++ {
+					for x := part.Min.X; x < part.Max.X; x++ {
+						covered[image.Pt(x, y)] += 1
+						area += 1
+					}
+				}
+			}
+			for p, n := range covered {
+				if n > 1 {
+					t.Errorf("%v is in %d parts", p, n)
+				}
+				for _, hole := range test.holes {
+					if p.In(hole) {
+						t.Errorf("%v is in hole %v", p, hole)
+					}
+				}
+			}
+			if area != test.area {
+				t.Errorf("expected an area of %d, got %d", test.area, area)
+			}
+		})
+	}
+	if parts := Subtract(image.Rectangle{}, nil); len(parts) != 0 {
+		t.Errorf("expected nothing left of an empty rectangle, got %v", parts)
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import "image"
+
+// Transform is a rotation (counter-clockwise) and flip applied to a buffer, the values are those of the
+// wl_output.transform enum
+type Transform int
+
+const (
+	TransformNormal = Transform(iota)
+	Transform90
+	Transform180
+	Transform270
+	TransformFlipped
+	TransformFlipped90
+	TransformFlipped180
+	TransformFlipped270
+)
+
+func (t Transform) Valid() bool {
+	return t >= TransformNormal && t <= TransformFlipped270
+}
+
+// Invert returns the transform that undoes t
+func (t Transform) Invert() Transform {
+	switch t {
+	case Transform90:
+		return Transform270
+	case Transform270:
+		return Transform90
+	}
+	return t
+}
+
+// Size returns the size of a width x height area once transformed
+func (t Transform) Size(width, height int) (int, int) {
+	if t%2 == 1 {
+		return height, width
+	}
+	return width, height
+}
+
+// Point maps a point of a width x height area through the transform
+func (t Transform) Point(x, y, width, height int) (int, int) {
+	switch t {
+	case TransformFlipped:
+		return width - x, y
+	case Transform90:
+		return y, width - x
+	case TransformFlipped90:
+		return y, x
+	case Transform180:
+		return width - x, height - y
+	case TransformFlipped180:
+		return x, height - y
+	case Transform270:
+		return height - y, x
+	case TransformFlipped270:
+		return height - y, width - x
+	}
+	return x, y
+}
+
+// Rect maps a rectangle of a width x height area through the transform
+func (t Transform) Rect(r image.Rectangle, width, height int) image.Rectangle {
+	x0, y0 := t.Point(r.Min.X, r.Min.Y, width, height)
+	x1, y1 := t.Point(r.Max.X, r.Max.Y, width, height)
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// Transformed returns the surface contents of a buffer that has been drawn with transform t and scale, i.e.
+// the image is transformed back with the inverse of t and shrunk by scale, averaging each scale x scale block
+func (i *BGRA) Transformed(t Transform, scale int) *BGRA {
+	if scale < 1 {
+		scale = 1
+	}
+	if t == TransformNormal && scale == 1 {
+		return i
+	}
+	// the size of the surface, in buffer pixels
+	width, height := t.Invert().Size(i.Rect.Dx(), i.Rect.Dy())
+	img := EmptyBGRA(image.Rect(0, 0, width/scale, height/scale))
+	i.transformInto(img, t, scale, img.Rect)
+	return img
+}
+
+// UpdateTransformed redraws area (in surface coordinates) of img, which was returned by Transformed for a
+// buffer of the same size, transform and scale as i
+func (i *BGRA) UpdateTransformed(img *BGRA, t Transform, scale int, area image.Rectangle) {
+	if scale < 1 {
+		scale = 1
+	}
+	i.transformInto(img, t, scale, area.Intersect(img.Rect))
+}
+
+func (i *BGRA) transformInto(img *BGRA, t Transform, scale int, area image.Rectangle) {
+	width, height := t.Invert().Size(i.Rect.Dx(), i.Rect.Dy())
+	block := scale * scale
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			var sum [4]int
+			for sy := y * scale; sy < (y+1)*scale; sy++ {
+				for sx := x * scale; sx < (x+1)*scale; sx++ {
+					// pixel centres, so that width - x lands on the last pixel rather than past it
+					bx, by := t.Point(2*sx+1, 2*sy+1, 2*width, 2*height)
+					s := i.PixOffset(i.Rect.Min.X+bx/2, i.Rect.Min.Y+by/2)
+					sum[0] += int(i.Pix[s])
+					sum[1] += int(i.Pix[s+1])
+					sum[2] += int(i.Pix[s+2])
+					sum[3] += int(i.Pix[s+3])
+				}
+			}
+			d := img.PixOffset(x, y)
+			img.Pix[d] = byte(sum[0] / block)
+			img.Pix[d+1] = byte(sum[1] / block)
+			img.Pix[d+2] = byte(sum[2] / block)
+			img.Pix[d+3] = byte(sum[3] / block)
+		}
+	}
+}
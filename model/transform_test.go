@@ -0,0 +1,127 @@
+package model
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// numbered returns a width x height image where every pixel has a different value
+func numbered(width, height int) *BGRA {
+	img := EmptyBGRA(image.Rect(0, 0, width, height))
+	for i := 0; i < width*height; i++ {
+		copy(img.Pix[i*4:], []byte{byte(i), byte(i), byte(i), 0xff})
+	}
+	return img
+}
+
+// mapPixels returns a width x height image where each pixel (x, y) of img is moved to f(x, y)
+func mapPixels(img *BGRA, width, height int, f func(x, y int) (int, int)) *BGRA {
+	out := EmptyBGRA(image.Rect(0, 0, width, height))
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			ox, oy := f(x, y)
+			copy(out.Pix[out.PixOffset(ox, oy):out.PixOffset(ox, oy)+4], img.Pix[img.PixOffset(x, y):])
+		}
+	}
+	return out
+}
+
+// rotate turns img 90 degrees counter-clockwise
+func rotate(img *BGRA) *BGRA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	return mapPixels(img, h, w, func(x, y int) (int, int) { return y, w - 1 - x })
+}
+
+// flip mirrors img around the vertical axis
+func flip(img *BGRA) *BGRA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	return mapPixels(img, w, h, func(x, y int) (int, int) { return w - 1 - x, y })
+}
+
+// upscale draws every pixel of img as a scale x scale block
+func upscale(img *BGRA, scale int) *BGRA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := EmptyBGRA(image.Rect(0, 0, w*scale, h*scale))
+	for y := 0; y < h*scale; y++ {
+		for x := 0; x < w*scale; x++ {
+			copy(out.Pix[out.PixOffset(x, y):out.PixOffset(x, y)+4], img.Pix[img.PixOffset(x/scale, y/scale):])
+		}
+	}
+	return out
+}
+
+// render draws the surface contents into a buffer as a client would for the transform, i.e. flipped and
+// then rotated counter-clockwise
+func render(surface *BGRA, t Transform, scale int) *BGRA {
+	buffer := upscale(surface, scale)
+	if t >= TransformFlipped {
+		buffer = flip(buffer)
+	}
+	for i := 0; i < int(t%4); i++ {
+		buffer = rotate(buffer)
+	}
+	return buffer
+}
+
+func TestTransformed(t *testing.T) {
+	names := []string{"normal", "90", "180", "270", "flipped", "flipped-90", "flipped-180", "flipped-270"}
+	surface := numbered(2, 3)
+	for transform := TransformNormal; transform <= TransformFlipped270; transform++ {
+		for _, scale := range []int{1, 2} {
+			buffer := render(surface, transform, scale)
+			t.Run(names[transform], func(t *testing.T) {
+				if w, h := transform.Size(2*scale, 3*scale); buffer.Rect.Dx() != w || buffer.Rect.Dy() != h {
+					t.Errorf("scale %d: expected a %dx%d buffer, got %v", scale, w, h, buffer.Rect)
+				}
+				got := buffer.Transformed(transform, scale)
+				if got.Rect != surface.Rect || !bytes.Equal(got.Pix, surface.Pix) {
+					t.Errorf("scale %d: expected %v % x, got %v % x", scale, surface.Rect, surface.Pix, got.Rect, got.Pix)
+				}
+			})
+		}
+	}
+}
+
+func TestTransformRect(t *testing.T) {
+	// the top left 1x1 of a 2x3 surface
+	r := image.Rect(0, 0, 1, 1)
+	tests := []struct {
+		transform Transform
+		want      image.Rectangle
+	}{
+		{TransformNormal, image.Rect(0, 0, 1, 1)},
+		{Transform90, image.Rect(0, 1, 1, 2)},
+		{Transform180, image.Rect(1, 2, 2, 3)},
+		{Transform270, image.Rect(2, 0, 3, 1)},
+		{TransformFlipped, image.Rect(1, 0, 2, 1)},
+		{TransformFlipped90, image.Rect(0, 0, 1, 1)},
+		{TransformFlipped180, image.Rect(0, 2, 1, 3)},
+		{TransformFlipped270, image.Rect(2, 1, 3, 2)},
+	}
+	for _, test := range tests {
+		if got := test.transform.Rect(r, 2, 3).Canon(); got != test.want {
+			t.Errorf("transform %d: expected %v, got %v", test.transform, test.want, got)
+		}
+	}
+}
+
+func TestUpdateTransformed(t *testing.T) {
+	surface := numbered(2, 3)
+	damage := image.Rect(1, 1, 2, 3)
+	changed := numbered(2, 3)
+	for y := damage.Min.Y; y < damage.Max.Y; y++ {
+		for x := damage.Min.X; x < damage.Max.X; x++ {
+			changed.Pix[changed.PixOffset(x, y)] = 0xaa
+		}
+	}
+	for transform := TransformNormal; transform <= TransformFlipped270; transform++ {
+		for _, scale := range []int{1, 2} {
+			img := render(surface, transform, scale).Transformed(transform, scale)
+			render(changed, transform, scale).UpdateTransformed(img, transform, scale, damage)
+			if !bytes.Equal(img.Pix, changed.Pix) {
+				t.Errorf("transform %d scale %d: expected % x, got % x", transform, scale, changed.Pix, img.Pix)
+			}
+		}
+	}
+}
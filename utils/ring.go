@@ -0,0 +1,65 @@
+package utils
+
+// RingBuffer is a fixed size byte ring buffer. Data is written directly into the
+// free space returned by Free (e.g. by a readv/recvmsg call) and then committed.
+type RingBuffer struct {
+	data []byte
+	head uint
+	tail uint
+}
+
+// NewRingBuffer creates a ring buffer, size must be a power of two
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 || size&(size-1) != 0 {
+		panic("ring buffer size must be a power of two")
+	}
+	return &RingBuffer{data: make([]byte, size)}
+}
+
+// Len returns the number of bytes available to read
+func (rb *RingBuffer) Len() int {
+	return int(rb.head - rb.tail)
+}
+
+// Cap returns the total size of the buffer
+func (rb *RingBuffer) Cap() int {
+	return len(rb.data)
+}
+
+func (rb *RingBuffer) mask(i uint) int {
+	return int(i & uint(len(rb.data)-1))
+}
+
+// Free returns the unused space in the buffer as (up to) two slices
+func (rb *RingBuffer) Free() [][]byte {
+	free := rb.Cap() - rb.Len()
+	if free == 0 {
+		return nil
+	}
+	start := rb.mask(rb.head)
+	if start+free <= rb.Cap() {
+		return [][]byte{rb.data[start : start+free]}
+	}
+	return [][]byte{rb.data[start:], rb.data[:start+free-rb.Cap()]}
+}
+
+// Commit marks n bytes of the space returned by Free as written
+func (rb *RingBuffer) Commit(n int) {
+	rb.head += uint(n)
+}
+
+// Peek copies up to len(p) bytes into p without consuming them
+func (rb *RingBuffer) Peek(p []byte) int {
+	n := min(len(p), rb.Len())
+	start := rb.mask(rb.tail)
+	copied := copy(p[:n], rb.data[start:])
+	copy(p[copied:n], rb.data)
+	return n
+}
+
+// Read copies up to len(p) bytes into p and consumes them
+func (rb *RingBuffer) Read(p []byte) int {
+	n := rb.Peek(p)
+	rb.tail += uint(n)
+	return n
+}
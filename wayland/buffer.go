@@ -1,7 +1,8 @@
 package wayland
 
 import (
-	"fmt"
+	"sync"
+
 	"nyctal/model"
 )
 
@@ -15,29 +16,46 @@ type Buffer struct {
 	stride      uint32
 	offset      uint32
 	format      model.Format
-	destroyed   bool
+
+	lock      sync.Mutex
+	destroyed bool
 }
 
+// Destroy marks the buffer as destroyed, a surface it is still attached to loses its content on the next
+// commit and no release is sent
 func (u *Buffer) Destroy() {
-	if !u.destroyed {
-		// u.wsc.SendMessage(
-		// 	NewPacketBuilder(u.id, 0x00).
-		// 		Build())
-		// u.wsc.SendMessage(
-		// 	NewPacketBuilder(0x1, 0x01).WithUint(u.id).
-		// 		Build())
-		u.destroyed = true
+	u.lock.Lock()
+	if u.destroyed {
+		u.lock.Unlock()
+		return
 	}
+	u.destroyed = true
+	u.lock.Unlock()
+	if u.backingPool != nil {
+		u.backingPool.unref()
+	}
+}
 
+func (u *Buffer) isDestroyed() bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.destroyed
+}
+
+// release is called once the contents of a commit have been copied, the client may then reuse the buffer
+func (u *Buffer) release() {
+	if !u.isDestroyed() {
+		SendWlBufferRelease(u.wsc, u.id)
+	}
 }
 
 func (u *Buffer) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlBufferRequestDestroy:
 		wsc.registry.Destroy(u.id)
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on buffer: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlBufferInterface)
 	}
 }
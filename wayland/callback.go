@@ -0,0 +1,17 @@
+package wayland
+
+// Callback is a wl_callback, it has no requests and is destroyed once done has been sent
+type Callback struct {
+	BaseObject
+	id uint32
+}
+
+func (u *Callback) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
+	return UnknownOpcode(packet, WlCallbackInterface)
+}
+
+// Done sends the done event and releases the callback
+func (u *Callback) Done(wsc *WaylandServerConn, data uint32) {
+	SendWlCallbackDone(wsc, u.id, data)
+	wsc.registry.Destroy(u.id)
+}
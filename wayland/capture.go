@@ -0,0 +1,235 @@
+package wayland
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"nyctal/model"
+	"nyctal/utils"
+
+	"golang.org/x/sys/unix"
+)
+
+// A capture records everything needed to replay a client's session against a fresh server (see Replay):
+// the requests it sent, the sizes of the fds it passed, the contents of the shm buffers it committed and
+// the input and frames the compositor gave it. The events sent in response and a hash of every frame are
+// recorded too, so that a replay can check that the compositor still behaves the same way.
+//
+// Setting NYCTAL_CAPTURE to a directory captures every client to <dir>/client-N.wlcap
+
+const captureMagic = "nyctal-capture-v1\n"
+
+var captureDir = os.Getenv("NYCTAL_CAPTURE")
+
+type CaptureKind uint8
+
+const (
+	// CaptureRequest and CaptureEvent hold a message, including its header
+	CaptureRequest CaptureKind = iota + 1
+	CaptureEvent
+	// CaptureFd holds the size of an fd received from the client
+	CaptureFd
+	// CaptureSHM holds the contents of a committed shm buffer: pool id, offset and data
+	CaptureSHM
+	// CaptureFrame holds the xdg_surface id, width and height a client was rendered at and the hash of its surface
+	CaptureFrame
+	// CapturePointer, CaptureKeyboard, CaptureFocus and CapturePointerLeave hold input for an xdg_surface
+	CapturePointer
+	CaptureKeyboard
+	CaptureFocus
+	CapturePointerLeave
+)
+
+type CaptureRecord struct {
+	Kind    CaptureKind
+	Elapsed time.Duration
+	Data    []byte
+}
+
+type captureHeader struct {
+	Kind    CaptureKind
+	_       [3]byte
+	Elapsed int64
+	Length  uint32
+}
+
+type captureWriter struct {
+	lock  sync.Mutex
+	w     io.Writer
+	owned io.Closer
+	start time.Time
+}
+
+// SetCapture starts recording the connection to w, nil stops recording
+func (c *WaylandServerConn) SetCapture(w io.Writer) error {
+	return c.setCapture(w, nil)
+}
+
+// setCapture records to w, owned (if any) is closed once recording stops
+func (c *WaylandServerConn) setCapture(w io.Writer, owned io.Closer) error {
+	var cw *captureWriter
+	if w != nil {
+		if _, err := io.WriteString(w, captureMagic); err != nil {
+			return err
+		}
+		cw = &captureWriter{w: w, owned: owned, start: time.Now()}
+	}
+	if old := c.capture.Swap(cw); old != nil && old.owned != nil {
+		old.lock.Lock()
+		old.owned.Close()
+		old.lock.Unlock()
+	}
+	return nil
+}
+
+// captureToDir records the connection to a file in dir, which is closed when the connection is
+func (c *WaylandServerConn) captureToDir(dir string) {
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("client-%d.wlcap", c.id)))
+	if err != nil {
+		utils.Error(int(c.id), "capture", err.Error())
+		return
+	}
+	if err := c.setCapture(f, f); err != nil {
+		utils.Error(int(c.id), "capture", err.Error())
+		f.Close()
+	}
+}
+
+func (c *WaylandServerConn) capturing() bool {
+	return c.capture.Load() != nil
+}
+
+// captureRecord appends a record to the capture (if any), recording stops if it cannot be written
+func (c *WaylandServerConn) captureRecord(kind CaptureKind, payload ...[]byte) {
+	cw := c.capture.Load()
+	if cw == nil {
+		return
+	}
+	cw.lock.Lock()
+	defer cw.lock.Unlock()
+
+	header := captureHeader{Kind: kind, Elapsed: int64(time.Since(cw.start))}
+	for _, p := range payload {
+		header.Length += uint32(len(p))
+	}
+	err := binary.Write(cw.w, binary.LittleEndian, header)
+	for _, p := range payload {
+		if err == nil {
+			_, err = cw.w.Write(p)
+		}
+	}
+	if err != nil {
+		utils.Error(int(c.id), "capture", fmt.Sprintf("stopped capturing: %v", err))
+		c.capture.CompareAndSwap(cw, nil)
+	}
+}
+
+func captureUints(values ...uint32) []byte {
+	buf := make([]byte, 0, len(values)*4)
+	for _, v := range values {
+		buf = binary.LittleEndian.AppendUint32(buf, v)
+	}
+	return buf
+}
+
+func (c *WaylandServerConn) captureMessage(kind CaptureKind, packet *WaylandMessage) {
+	c.captureRecord(kind, captureUints(packet.Address, uint32(len(packet.Data)+8)<<16|uint32(packet.Opcode)), packet.Data)
+}
+
+// captureFd records the size of a received fd, replays pass a memfd of the same size in its place
+func (c *WaylandServerConn) captureFd(fd int) {
+	var st unix.Stat_t
+	size := int64(0)
+	if unix.Fstat(fd, &st) == nil {
+		size = st.Size
+	}
+	c.captureRecord(CaptureFd, binary.LittleEndian.AppendUint64(nil, uint64(size)))
+}
+
+// captureBuffer records the contents of a committed buffer
+func (c *WaylandServerConn) captureBuffer(buffer *Buffer) {
+	pool := buffer.backingPool
+	if pool == nil || pool.mappedData == nil {
+		return
+	}
+	end := int(buffer.offset) + int(buffer.stride)*int(buffer.height)
+	if end > len(pool.mappedData) {
+		return
+	}
+	c.captureRecord(CaptureSHM, captureUints(pool.id, buffer.offset), pool.mappedData[buffer.offset:end])
+}
+
+// captureFrame records a client being rendered along with a hash of its surface's contents
+func (c *WaylandServerConn) captureFrame(surface uint32, width, height int, img *model.BGRA) {
+	c.captureRecord(CaptureFrame, captureUints(surface, uint32(width), uint32(height)), binary.LittleEndian.AppendUint64(nil, hashImage(img)))
+}
+
+func (c *WaylandServerConn) capturePointer(surface uint32, ev model.PointerEvent) {
+	switch {
+	case ev.Move != nil:
+		c.captureRecord(CapturePointer, captureUints(surface, 0, ev.Move.Time, math.Float32bits(ev.Move.MX), math.Float32bits(ev.Move.MY)))
+	case ev.Button != nil:
+		c.captureRecord(CapturePointer, captureUints(surface, 1, ev.Button.Time, ev.Button.Button, ev.Button.State))
+	case ev.Axis != nil:
+		c.captureRecord(CapturePointer, captureUints(surface, 2, ev.Axis.Time, ev.Axis.Axis, math.Float32bits(ev.Axis.Value)))
+	}
+}
+
+func (c *WaylandServerConn) captureKeyboard(surface uint32, ev model.KeyboardEvent) {
+	c.captureRecord(CaptureKeyboard, captureUints(surface, ev.Time, ev.Key, ev.State, ev.Modifiers))
+}
+
+// hashImage hashes the visible pixels of an image, 0 is returned for no image
+func hashImage(img *model.BGRA) uint64 {
+	if img == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	bounds := img.Bounds()
+	h.Write(captureUints(uint32(bounds.Dx()), uint32(bounds.Dy())))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		start := img.PixOffset(bounds.Min.X, y)
+		h.Write(img.Pix[start : start+bounds.Dx()*4])
+	}
+	return h.Sum64()
+}
+
+// ReadCapture reads every record of a capture
+func ReadCapture(r io.Reader) ([]CaptureRecord, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != captureMagic {
+		return nil, fmt.Errorf("not a capture")
+	}
+	var records []CaptureRecord
+	for {
+		record, err := readRecord(br)
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, fmt.Errorf("could not read record %d: %v", len(records), err)
+		}
+		records = append(records, record)
+	}
+}
+
+// readRecord reads the next record of a capture, io.EOF is returned if there are no more
+func readRecord(r io.Reader) (CaptureRecord, error) {
+	var header captureHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return CaptureRecord{}, err
+	}
+	data := make([]byte, header.Length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return CaptureRecord{}, err
+	}
+	return CaptureRecord{Kind: header.Kind, Elapsed: time.Duration(header.Elapsed), Data: data}, nil
+}
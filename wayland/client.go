@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"runtime/debug"
 
 	"nyctal/model"
 	"nyctal/utils"
@@ -64,46 +65,133 @@ func (wc *WaylandClient) RemoveChildren(id int) {
 
 }
 
-func (wc *WaylandClient) Subsurfaces(subsurface *SubSurface, wg image.Point, buffer *model.BGRA, width int, height int) {
+// layer is a surface drawn as part of the window, the image of current is drawn into dst starting at the
+// image point sp. border is a debug outline drawn around it (none if zero).
+type layer struct {
+	surface *Surface
+	current *surfaceSnapshot
+	dst     image.Rectangle
+	sp      image.Point
+	border  color.RGBA
+}
+
+// area returns the part of dst the image covers
+func (l *layer) area() image.Rectangle {
+	return l.dst.Intersect(l.current.image.Bounds().Add(l.dst.Min.Sub(l.sp)))
+}
+
+// opaque returns the parts of the area known to be opaque, in the same coordinates as dst
+func (l *layer) opaque() []image.Rectangle {
+	area := l.area()
+	if l.current.opaque {
+		return []image.Rectangle{area}
+	}
+	if l.current.opaqueRegion == nil {
+		return nil
+	}
+	// surface coordinates are offset from image coordinates by the attach offset
+	origin := l.dst.Min.Sub(l.sp).Sub(l.current.offset)
+	var rects []image.Rectangle
+	for _, rect := range l.current.opaqueRegion.Rects() {
+		if rect = rect.Add(origin).Intersect(area); !rect.Empty() {
+			rects = append(rects, rect)
+		}
+	}
+	return rects
+}
+
+func (wc *WaylandClient) Subsurfaces(layers []layer, subsurface *SubSurface, wg image.Point) []layer {
 	//utils.Debug("client", fmt.Sprintf("drawing subsurface: %d %v", i, subsurface.id))
-	subsurface.surface.RenderBuffer()
-	pimg := subsurface.surface.cached
+	current := subsurface.surface.Snapshot()
+	pimg := current.image
 	if pimg != nil {
 		offset := subsurface.position.Add(wg)
+		imgOffset := offset.Add(current.offset)
 
-		atZero := image.Rect(offset.X, offset.Y, offset.X+pimg.Rect.Dx(), offset.Y+pimg.Rect.Dy())
+		atZero := image.Rect(imgOffset.X, imgOffset.Y, imgOffset.X+pimg.Rect.Dx(), imgOffset.Y+pimg.Rect.Dy())
 		//utils.Debug("client", fmt.Sprintf("rendering at %v %v\n", atZero, pimg.Bounds()))
-		model.DrawCopyOver(buffer, atZero, pimg, image.Pt(0, 0))
-		buffer.DrawRect(atZero.Min.X, atZero.Min.Y, atZero.Max.X, atZero.Max.Y, color.RGBA{R: 255})
+		layers = append(layers, layer{surface: subsurface.surface, current: current, dst: atZero, border: color.RGBA{R: 255}})
 
 		for _, subsurface := range subsurface.surface.children {
-			wc.Subsurfaces(subsurface, offset, buffer, width, height)
+			layers = wc.Subsurfaces(layers, subsurface, offset)
 		}
-		subsurface.surface.RenderFrame(wc.wsc, []byte{0, 0, 0, 0})
 	} else {
 		utils.Debug(int(wc.wsc.id), "client", "could not render subsurface...")
 	}
+	return layers
+}
 
+// drawLayers draws the surfaces of the window from the bottom up. Only the parts of each surface that are
+// not hidden by opaque surfaces above it are drawn, and the parts that are themselves opaque are copied
+// rather than blended.
+//
+// Culling only covers the surfaces of this window. Panels only draw their top window and split panels
+// never overlap, so the only other window that can cover it is the one being dragged, which is drawn
+// over it afterwards. TODO: cull across windows in the workspace if windows are allowed to overlap.
+func (wc *WaylandClient) drawLayers(buffer *model.BGRA, layers []layer) {
+	visible := make([][]image.Rectangle, len(layers))
+	opaque := make([][]image.Rectangle, len(layers))
+	var above []image.Rectangle
+	for i := len(layers) - 1; i >= 0; i-- {
+		visible[i] = model.Subtract(layers[i].area().Intersect(buffer.Bounds()), above)
+		opaque[i] = layers[i].opaque()
+		above = append(above, opaque[i]...)
+	}
+
+	for i, l := range layers {
+		if len(visible[i]) == 0 {
+			utils.Debug(int(wc.wsc.id), "client", fmt.Sprintf("surface#%d is hidden", l.surface.id))
+		}
+		for _, part := range visible[i] {
+			for _, rect := range model.Subtract(part, opaque[i]) {
+				model.DrawCopyOver(buffer, rect, l.current.image, l.sp.Add(rect.Min.Sub(l.dst.Min)))
+			}
+			for _, rect := range opaque[i] {
+				if rect = rect.Intersect(part); !rect.Empty() {
+					model.DrawCopy(buffer, rect, l.current.image, l.sp.Add(rect.Min.Sub(l.dst.Min)))
+				}
+			}
+		}
+		if l.border != (color.RGBA{}) && len(visible[i]) > 0 {
+			buffer.DrawRect(l.dst.Min.X, l.dst.Min.Y, l.dst.Max.X, l.dst.Max.Y, l.border)
+		}
+		// hidden surfaces are still on the output, and still get their frame callbacks
+		l.surface.updateOutputs(wc.wsc, l.dst)
+		l.surface.RenderFrame(wc.wsc, []byte{0, 0, 0, 0})
+	}
 }
 
 func (wc *WaylandClient) Buffer(buffer *model.BGRA, width int, height int) {
+	defer wc.wsc.Flush()
+	// rendering reads client memory (e.g. a shm pool that has been truncated), so as in handle a
+	// broken client is disconnected rather than taking down the render loop
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			debug.PrintStack()
+			utils.Debug(int(wc.wsc.id), "client", fmt.Sprintf("recovered panic while rendering %v", r))
+			wc.wsc.disconnect(fmt.Errorf("panic while rendering: %v", r))
+		}
+	}()
 	utils.Debug(int(wc.wsc.id), "client", "preparing buffer")
 	wc.Resize(width, height)
 	utils.Debug(int(wc.wsc.id), "client", "ongoing...")
 	wl_surface := wc.surface.surface
-	wl_surface.RenderBuffer()
-	img := wl_surface.cached
+	current := wl_surface.Snapshot()
+	img := current.image
+	if wc.wsc.capturing() {
+		wc.wsc.captureFrame(wc.surface.id, width, height, img)
+	}
 	if img != nil {
 		wg := wc.surface.windowGeometry
 		if wg.Dx() == 0 {
 			wg = img.Bounds()
 		}
 
-		model.DrawCopyOver(buffer, buffer.Bounds(), img, wg.Min)
-		serial := wc.surface.surface.RenderFrame(wc.wsc, []byte{0, 0, 0, 0})
+		layers := []layer{{surface: wl_surface, current: current, dst: buffer.Bounds(), sp: wg.Min.Sub(current.offset)}}
 
 		for _, subsurface := range wc.surface.surface.children {
-			wc.Subsurfaces(subsurface, buffer.Bounds().Min.Sub(wg.Min), buffer, width, height)
+			layers = wc.Subsurfaces(layers, subsurface, buffer.Bounds().Min.Sub(wg.Min))
 		}
 
 		for _, client := range wc.popups.Inner() {
@@ -115,8 +203,9 @@ func (wc *WaylandClient) Buffer(buffer *model.BGRA, width int, height int) {
 			xdg_surface := client.surface
 
 			wl_surface := xdg_surface.surface
+			pcurrent := wl_surface.Snapshot()
 
-			pimg := wl_surface.cached
+			pimg := pcurrent.image
 
 			if pimg != nil {
 				offset := xdg_surface.RelativeOffset()
@@ -124,9 +213,7 @@ func (wc *WaylandClient) Buffer(buffer *model.BGRA, width int, height int) {
 					offset.X+client.positioner.size.Dx(),
 					offset.Y+client.positioner.size.Dy()).Add(buffer.Bounds().Min)
 				//utils.Debug("client", fmt.Sprintf("rendering at %v %v\n", atZero, pimg.Bounds()))
-				model.DrawCopyOver(buffer, atZero, pimg, image.Pt(xdg_surface.windowGeometry.Min.X, xdg_surface.windowGeometry.Min.Y))
-				buffer.DrawRect(atZero.Min.X, atZero.Min.Y, atZero.Max.X, atZero.Max.Y, color.RGBA{B: 255})
-				wl_surface.RenderFrame(wc.wsc, serial)
+				layers = append(layers, layer{surface: wl_surface, current: pcurrent, dst: atZero, sp: xdg_surface.windowGeometry.Min.Sub(pcurrent.offset), border: color.RGBA{B: 255}})
 			} else {
 				utils.Debug(int(wc.wsc.id), "client", "could not render popup...")
 			}
@@ -140,12 +227,13 @@ func (wc *WaylandClient) Buffer(buffer *model.BGRA, width int, height int) {
 					//utils.Debug("client", fmt.Sprintf("drawing cursor %v", pointerObj.local))
 					ps, _ := wc.wsc.registry.Get(seat.mouse.surface)
 					if pointer_surface, ok := ps.(*Surface); ok {
-						mouseBuf := pointer_surface.cached
+						pcurrent := pointer_surface.Snapshot()
+						mouseBuf := pcurrent.image
 						if mouseBuf != nil {
-							pointerImgLoc := wc.pointerLocal.Sub(seat.mouse.hotspot)
+							// the attach offset moves the hotspot
+							pointerImgLoc := wc.pointerLocal.Sub(seat.mouse.hotspot).Add(pcurrent.offset)
 							windowRect := image.Rect(pointerImgLoc.X, pointerImgLoc.Y, pointerImgLoc.X+mouseBuf.Bounds().Dx(), pointerImgLoc.Y+mouseBuf.Bounds().Dy())
-							model.DrawCopyOver(buffer, windowRect.Add(buffer.Bounds().Min), mouseBuf, image.Pt(0, 0))
-							pointer_surface.RenderFrame(wc.wsc, serial)
+							layers = append(layers, layer{surface: pointer_surface, current: pcurrent, dst: windowRect.Add(buffer.Bounds().Min)})
 						}
 
 					}
@@ -153,12 +241,18 @@ func (wc *WaylandClient) Buffer(buffer *model.BGRA, width int, height int) {
 			}
 		}
 
+		wc.drawLayers(buffer, layers)
+
 	} else {
 		utils.Debug(int(wc.wsc.id), "client", "could not  subsurface...")
 	}
 }
 
 func (wc *WaylandClient) ProcessKeyboardEvent(ev model.KeyboardEvent) {
+	defer wc.wsc.Flush()
+	if wc.wsc.capturing() {
+		wc.wsc.captureKeyboard(wc.surface.id, ev)
+	}
 	seat := wc.wsc.registry.FindSeat()
 	if seat != nil {
 		seat.ProcessKeyboardEvent(ev)
@@ -166,6 +260,10 @@ func (wc *WaylandClient) ProcessKeyboardEvent(ev model.KeyboardEvent) {
 }
 
 func (wc *WaylandClient) ProcessPointerEvent(ev model.PointerEvent) bool {
+	defer wc.wsc.Flush()
+	if wc.wsc.capturing() {
+		wc.wsc.capturePointer(wc.surface.id, ev)
+	}
 
 	// send pointer enter event
 	seat := wc.wsc.registry.FindSeat()
@@ -181,6 +279,10 @@ func (wc *WaylandClient) ProcessPointerEvent(ev model.PointerEvent) bool {
 }
 
 func (wc *WaylandClient) ProcessFocus() {
+	defer wc.wsc.Flush()
+	if wc.wsc.capturing() {
+		wc.wsc.captureRecord(CaptureFocus, captureUints(wc.surface.id))
+	}
 	seat := wc.wsc.registry.FindSeat()
 	if seat != nil {
 		seat.Grab(wc.surface)
@@ -192,6 +294,9 @@ func (wc *WaylandClient) ProcessUnFocus() {
 }
 
 func (wc *WaylandClient) HandlePointerLeave() {
+	if wc.wsc.capturing() {
+		wc.wsc.captureRecord(CapturePointerLeave, captureUints(wc.surface.id))
+	}
 	if wc.hasPointer {
 		// send pointer leave evner
 		wc.hasPointer = false
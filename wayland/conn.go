@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"sync"
 	"sync/atomic"
 
-	"syscall"
-
 	"golang.org/x/sys/unix"
 
 	"nyctal/model"
@@ -25,89 +24,275 @@ type WaylandServerConn struct {
 	fds        *utils.Queue[int]
 	connFd     int
 	index      *atomic.Uint32
+	serials    *Serials
 	pingtarget Pingable
 	errors     int
+	creds      *unix.Ucred
+
+	// in buffers bytes received from the client that have not yet been parsed into messages
+	in *utils.RingBuffer
+	// oob is reused for every recvmsg to receive fds
+	oob []byte
+
+	// out buffers events (and the fds they carry) until the next Flush
+	outLock sync.Mutex
+	out     []byte
+	outFds  []int
+	closed  bool
+
+	limits   Limits
+	rate     rateLimiter
+	shmBytes atomic.Int64
+
+	// trace is checked before every message is sent or dispatched, see SetTrace
+	trace       atomic.Bool
+	traceLock   sync.Mutex
+	traceIfaces interfaceMap
+
+	// capture is set while the connection is being recorded, see SetCapture
+	capture atomic.Pointer[captureWriter]
+}
+
+const (
+	// maxFdsPerMsg is the maximum number of fds libwayland will send in a single message
+	maxFdsPerMsg = 28
+	// inBufferSize is large enough to hold the largest possible message (sizes are u16)
+	inBufferSize = 1 << 16
+)
+
+// Credentials holds the identity of the process on the other end of a connection
+type Credentials struct {
+	Pid int32
+	Uid uint32
+	Gid uint32
+}
+
+// Credentials returns the pid, uid and gid of the client process (as reported by SO_PEERCRED when it connected),
+// false is returned if they could not be determined
+func (c *WaylandServerConn) Credentials() (Credentials, bool) {
+	if c.creds == nil {
+		return Credentials{}, false
+	}
+	return Credentials{Pid: c.creds.Pid, Uid: c.creds.Uid, Gid: c.creds.Gid}, true
+}
+
+// Id returns the number the server assigned to the client when it connected
+func (c *WaylandServerConn) Id() model.GlobalIdx {
+	return c.id
 }
 
 func (c *WaylandServerConn) SendMessageWithFd(data []byte, fd int) {
-	utils.Debug(int(c.id), "send-wayland-message-with-fd", fmt.Sprintf("%d %x", c.id, data))
-	rights := syscall.UnixRights([]int{fd}...)
-	syscall.Sendmsg(c.connFd, data, rights, nil, 0)
+	c.SendMessageWithFds(data, []int{fd})
+}
+
+// SendMessageWithFds queues a message along with all of the file descriptors it carries (in argument order).
+// The fds are duplicated, the caller retains ownership of the originals.
+func (c *WaylandServerConn) SendMessageWithFds(data []byte, fds []int) {
+	if c.trace.Load() {
+		c.traceEvent(data)
+	}
+	if c.capturing() {
+		c.captureRecord(CaptureEvent, data)
+	}
+	c.queue(data, fds)
 }
 
+// SendMessage queues a message to be sent to the client on the next Flush
 func (c *WaylandServerConn) SendMessage(data []byte) {
-	utils.Debug(int(c.id), "send-wayland-message", fmt.Sprintf("%d %x", c.id, data))
-	syscall.Sendmsg(c.connFd, data, nil, nil, 0)
+	if c.trace.Load() {
+		c.traceEvent(data)
+	}
+	if c.capturing() {
+		c.captureRecord(CaptureEvent, data)
+	}
+	c.queue(data, nil)
 }
 
-func (c *WaylandServerConn) RecvMsg(connFd int, p []byte) (int, error) {
+func (c *WaylandServerConn) queue(data []byte, fds []int) {
+	c.outLock.Lock()
+	defer c.outLock.Unlock()
+	if c.closed {
+		return
+	}
 
-	b := make([]byte, unix.CmsgSpace(4))
+	// fds are sent alongside the first chunk of data of a flush, so make room for these ones
+	if len(c.outFds)+len(fds) > maxFdsPerMsg {
+		c.flushLocked()
+		if len(c.outFds)+len(fds) > maxFdsPerMsg {
+			c.disconnectLocked(fmt.Errorf("client is not reading, could not send fds"))
+			return
+		}
+	}
 
-	syscall.SetsockoptTimeval(connFd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &syscall.Timeval{Sec: 2})
-	n, oobn, flags, _, err := syscall.Recvmsg(connFd, p, b, 0)
+	for _, fd := range fds {
+		dup, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err != nil {
+			c.disconnectLocked(fmt.Errorf("could not duplicate fd %d: %v", fd, err))
+			return
+		}
+		c.outFds = append(c.outFds, dup)
+	}
+	c.out = append(c.out, data...)
 
-	// parse socket control message
-	if oobn > 0 {
-		cmsgs, err := unix.ParseSocketControlMessage(b)
-		if err != nil || cmsgs == nil {
-			return -1, fmt.Errorf("ERROR CMSG %v %v %v", cmsgs, flags, err)
+	// a client that has stopped reading cannot be sent an error, so it is just disconnected
+	if c.limits.MaxOutputBytes > 0 && len(c.out) > c.limits.MaxOutputBytes {
+		c.disconnectLocked(fmt.Errorf("output buffer exceeded %d bytes", c.limits.MaxOutputBytes))
+	}
+}
+
+// Flush writes as much of the queued output as the client socket will accept, anything remaining
+// is retried on the next Flush. An error is returned if the client has been disconnected.
+func (c *WaylandServerConn) Flush() error {
+	c.outLock.Lock()
+	defer c.outLock.Unlock()
+	return c.flushLocked()
+}
+
+func (c *WaylandServerConn) flushLocked() error {
+	if c.closed {
+		return fmt.Errorf("client#%d has been disconnected", c.id)
+	}
+	for len(c.out) > 0 {
+		var rights []byte
+		if len(c.outFds) > 0 {
+			rights = unix.UnixRights(c.outFds...)
+		}
+		n, err := unix.SendmsgN(c.connFd, c.out, rights, nil, unix.MSG_DONTWAIT|unix.MSG_NOSIGNAL)
+		if err == unix.EINTR {
+			continue
+		}
+		if err == unix.EAGAIN {
+			// the client is not reading, keep the rest for later
+			return nil
 		}
-		fds, err := unix.ParseUnixRights(&cmsgs[0])
 		if err != nil {
-			return -1, fmt.Errorf("ERROR PUR %v %v %v", cmsgs, flags, err)
+			c.disconnectLocked(fmt.Errorf("sendmsg: %v", err))
+			return err
 		}
-		for _, fd := range fds {
-			c.fds.Push(fd)
+		// fds are transferred along with the first byte of the write
+		for _, fd := range c.outFds {
+			unix.Close(fd)
 		}
-
+		c.outFds = c.outFds[:0]
+		c.out = c.out[n:]
 	}
-
-	return n, err
+	c.out = nil
+	return nil
 }
 
-func (c *WaylandServerConn) ReadPacket() (*WaylandMessage, error) {
+func (c *WaylandServerConn) disconnect(reason error) {
+	c.outLock.Lock()
+	defer c.outLock.Unlock()
+	if !c.closed {
+		c.disconnectLocked(reason)
+	}
+}
 
-	p := make([]byte, 8)
+// disconnectLocked drops any queued output and shuts down the socket, which causes the
+// connection's read loop to terminate.
+func (c *WaylandServerConn) disconnectLocked(reason error) {
+	utils.Debug(int(c.id), "wayland-server", fmt.Sprintf("disconnecting client#%d: %v", c.id, reason))
+	c.closed = true
+	c.out = nil
+	for _, fd := range c.outFds {
+		unix.Close(fd)
+	}
+	c.outFds = nil
+	unix.Shutdown(c.connFd, unix.SHUT_RDWR)
+}
 
-	n, err := c.RecvMsg(c.connFd, p)
+// fill reads as much as is available from the socket into the input buffer (blocking until there is
+// something to read), any fds sent alongside the data are pushed onto the fd queue in order
+func (c *WaylandServerConn) fill() error {
+	free := c.in.Free()
+	if free == nil {
+		return fmt.Errorf("input buffer is full")
+	}
 
+	n, oobn, flags, _, err := unix.RecvmsgBuffers(c.connFd, free, c.oob, unix.MSG_CMSG_CLOEXEC)
 	if err != nil {
-		return nil, fmt.Errorf("expected 8 got %d: %v", n, err)
+		return fmt.Errorf("recvmsg: %w", err)
 	}
 
-	if n == -1 {
-		return nil, fmt.Errorf("returned -1")
+	// parse socket control messages
+	if oobn > 0 {
+		cmsgs, err := unix.ParseSocketControlMessage(c.oob[:oobn])
+		if err != nil {
+			return fmt.Errorf("could not parse control message: %v", err)
+		}
+		for _, cmsg := range cmsgs {
+			if cmsg.Header.Level != unix.SOL_SOCKET || cmsg.Header.Type != unix.SCM_RIGHTS {
+				continue
+			}
+			fds, err := unix.ParseUnixRights(&cmsg)
+			if err != nil {
+				return fmt.Errorf("could not parse fds: %v", err)
+			}
+			for _, fd := range fds {
+				if c.capturing() {
+					c.captureFd(fd)
+				}
+				c.fds.Push(fd)
+			}
+		}
+		if c.limits.MaxPendingFds > 0 && len(c.fds.Inner()) > c.limits.MaxPendingFds {
+			return NewProtocolError(1, WlDisplayErrorNoMemory, "too many unused fds (limit %d)", c.limits.MaxPendingFds)
+		}
+	}
+	if flags&unix.MSG_CTRUNC != 0 {
+		return fmt.Errorf("control message truncated, client sent too many fds")
 	}
 
 	if n == 0 {
-		return nil, fmt.Errorf("returned 0")
+		return fmt.Errorf("connection closed by client")
 	}
+	c.in.Commit(n)
+	return nil
+}
 
-	if n != 8 {
-		return nil, fmt.Errorf("unexpectd number of bytes")
+// hasBufferedMessage returns true if a complete message has already been received
+func (c *WaylandServerConn) hasBufferedMessage() bool {
+	header := make([]byte, 8)
+	if c.in.Peek(header) != 8 {
+		return false
 	}
+	return c.in.Len() >= int(binary.LittleEndian.Uint16(header[6:8]))
+}
 
-	msg := &WaylandMessage{}
-	msg.Address = binary.LittleEndian.Uint32(p)
-	msg.Length = binary.LittleEndian.Uint16(p[6:8])
-	msg.Opcode = binary.LittleEndian.Uint16(p[4:6])
-	msg.Length -= 8
-	if msg.Length > 0 {
-		p = make([]byte, msg.Length)
-		n, err = c.RecvMsg(c.connFd, p)
-		if err != nil {
-			return nil, err
+// ReadPacket returns the next message sent by the client. Messages are demultiplexed from the
+// input buffer, the socket is only read when the buffer does not contain a complete message.
+func (c *WaylandServerConn) ReadPacket() (*WaylandMessage, error) {
+	for {
+		if msg, err := c.readBuffered(); msg != nil || err != nil {
+			return msg, err
 		}
-
-		if n == -1 {
-			return nil, fmt.Errorf("none")
+		if err := c.fill(); err != nil {
+			return nil, err
 		}
+	}
+}
 
-		if n != int(msg.Length) {
-			return nil, fmt.Errorf("expected %d got %d", n, msg.Length)
-		}
-		msg.Data = p
+// readBuffered returns the next complete message in the input buffer, or nil if there isn't one
+func (c *WaylandServerConn) readBuffered() (*WaylandMessage, error) {
+	header := make([]byte, 8)
+	if c.in.Peek(header) != 8 {
+		return nil, nil
+	}
+	size := binary.LittleEndian.Uint16(header[6:8])
+	if size < 8 || size%4 != 0 {
+		return nil, fmt.Errorf("invalid message size %d", size)
+	}
+	if c.in.Len() < int(size) {
+		return nil, nil
+	}
+	c.in.Read(header)
+	msg := &WaylandMessage{}
+	msg.Address = binary.LittleEndian.Uint32(header)
+	msg.Opcode = binary.LittleEndian.Uint16(header[4:6])
+	msg.Length = size - 8
+	if msg.Length > 0 {
+		msg.Data = make([]byte, msg.Length)
+		c.in.Read(msg.Data)
 	}
 	return msg, nil
 }
@@ -1,6 +1,10 @@
 package wayland
 
-import "fmt"
+import (
+	"fmt"
+
+	"nyctal/utils"
+)
 
 type DataDevice struct {
 	BaseObject
@@ -10,14 +14,25 @@ type DataDevice struct {
 	selection *DataSource
 }
 
+// Selection sends the current selection to the client, as a new data offer
 func (u *DataDevice) Selection(wsc *WaylandServerConn) {
-	wsc.SendMessage(NewPacketBuilder(u.id, 0x05).WithUint(0).Build())
+	if u.selection == nil || u.selection.destroyed {
+		SendWlDataDeviceSelection(wsc, u.id, 0)
+		return
+	}
+	offer, err := NewDataOffer(wsc, u, u.selection)
+	if err != nil {
+		utils.Error(int(wsc.id), "data_device", err.Error())
+		SendWlDataDeviceSelection(wsc, u.id, 0)
+		return
+	}
+	SendWlDataDeviceSelection(wsc, u.id, offer.id)
 }
 
 func (u *DataDevice) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 1:
+	case WlDataDeviceRequestSetSelection:
 		// 	This request asks the compositor to set the selection
 		//to the data from the source on behalf of the client.
 
@@ -26,20 +41,34 @@ func (u *DataDevice) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessag
 		//The given source may not be used in any further set_selection or
 		//start_drag requests. Attempting to reuse a previously-used source
 		//may send a used_source error.
-		source := NewUintField()
-		serial := NewUintField()
-		if err := ParsePacketStructure(packet.Data, source, serial); err != nil {
+		req, err := ParseWlDataDeviceSetSelectionRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		if obj, err := wsc.registry.Get((uint32(*source))); err == nil {
+		// only the client with keyboard focus or that was just clicked on may change the selection
+		valid := wsc.validSerial(req.Serial, SerialKeyboardEnter, SerialKey, SerialButton)
+		if !valid {
+			utils.Debug(int(wsc.id), fmt.Sprintf("wl_data_device#%d", u.id), fmt.Sprintf("set_selection refused, invalid serial %d", req.Serial))
+		}
+		if req.Source == 0 {
+			if valid {
+				u.selection = nil
+			}
+			return nil
+		}
+		if obj, err := wsc.registry.Get(req.Source); err == nil {
 			if datasource, ok := obj.(*DataSource); ok {
+				if !valid {
+					SendWlDataSourceCancelled(wsc, datasource.id)
+					return nil
+				}
 				u.selection = datasource
 				return nil
 			}
 		}
-		return fmt.Errorf("could not set selection")
+		return InvalidObject(packet, req.Source, WlDataSourceInterface)
 	default:
-		return fmt.Errorf("unknown opcode called on data device: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlDataDeviceInterface)
 	}
 
 }
@@ -15,35 +15,38 @@ type DataDeviceManager struct {
 func (u *DataDeviceManager) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-		newId := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId); err != nil {
+	case WlDataDeviceManagerRequestCreateDataSource:
+		req, err := ParseWlDataDeviceManagerCreateDataSourceRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		utils.Debug(int(wsc.id), "data_device_manager", fmt.Sprintf("create_data_source#%d", *newId))
-		wsc.registry.New(uint32(*newId), &DataSource{id: uint32(*newId), mimetypes: make(map[string]bool)})
+		utils.Debug(int(wsc.id), "data_device_manager", fmt.Sprintf("create_data_source#%d", req.Id))
+		if err := wsc.registry.New(req.Id, &DataSource{id: req.Id, wsc: wsc, mimetypes: make(map[string]bool)}, wsc.registry.Version(packet.Address)); err != nil {
+			return err
+		}
 		return nil
-	case 1:
+	case WlDataDeviceManagerRequestGetDataDevice:
 
-		newId := NewUintField()
-		seatId := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId, seatId); err != nil {
+		req, err := ParseWlDataDeviceManagerGetDataDeviceRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		utils.Debug(int(wsc.id), "data_device_manager", fmt.Sprintf("get_data_device#%d %d", *newId, *seatId))
+		utils.Debug(int(wsc.id), "data_device_manager", fmt.Sprintf("get_data_device#%d %d", req.Id, req.Seat))
 
-		if obj, err := wsc.registry.Get((uint32(*seatId))); err == nil {
+		if obj, err := wsc.registry.Get(req.Seat); err == nil {
 			if seat, ok := obj.(*Seat); ok {
-				wsc.registry.New(uint32(*newId), &DataDevice{id: uint32(*newId), seat: seat, server: u.server})
+				if err := wsc.registry.New(req.Id, &DataDevice{id: req.Id, seat: seat, server: u.server}, wsc.registry.Version(packet.Address)); err != nil {
+					return err
+				}
 				return nil
 			}
 		}
 
-		return fmt.Errorf("unable to get data device: invalid seat")
+		return InvalidObject(packet, req.Seat, WlSeatInterface)
 	default:
-		return fmt.Errorf("unknown opcode called on data device manager: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlDataDeviceManagerInterface)
 	}
 
 }
@@ -0,0 +1,63 @@
+package wayland
+
+import (
+	"fmt"
+
+	"nyctal/utils"
+
+	"golang.org/x/sys/unix"
+)
+
+// DataOffer is created by the server to offer the contents of a data source to a client, it is
+// the only object whose id comes from the server's range
+type DataOffer struct {
+	BaseObject
+	id     uint32
+	source *DataSource
+}
+
+// NewDataOffer introduces an offer for the source to the client through its data device
+func NewDataOffer(wsc *WaylandServerConn, device *DataDevice, source *DataSource) (*DataOffer, error) {
+	offer := &DataOffer{source: source}
+	id, err := wsc.registry.NewServerObject(offer, wsc.registry.Version(device.id))
+	if err != nil {
+		return nil, err
+	}
+	offer.id = id
+	SendWlDataDeviceDataOffer(wsc, device.id, id)
+	for mimetype := range source.mimetypes {
+		SendWlDataOfferOffer(wsc, id, mimetype)
+	}
+	return offer, nil
+}
+
+func (u *DataOffer) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
+
+	switch packet.Opcode {
+	case WlDataOfferRequestAccept:
+		_, err := ParseWlDataOfferAcceptRequest(wsc, packet)
+		return err
+	case WlDataOfferRequestReceive:
+		// 	Request that the data is transferred to the given fd, the source is asked to write it
+		req, err := ParseWlDataOfferReceiveRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		defer unix.Close(req.Fd)
+		utils.Debug(int(wsc.id), fmt.Sprintf("wl_data_offer#%d", u.id), fmt.Sprintf("receive %s", req.MimeType))
+		if !u.source.destroyed && u.source.mimetypes[req.MimeType] {
+			SendWlDataSourceSend(u.source.wsc, u.source.id, req.MimeType, req.Fd)
+		}
+		return nil
+	case WlDataOfferRequestDestroy:
+		wsc.registry.Destroy(u.id)
+		return nil
+	case WlDataOfferRequestFinish:
+		return NewProtocolError(u.id, WlDataOfferErrorInvalidFinish, "finish is only valid for drag and drop offers")
+	case WlDataOfferRequestSetActions:
+		return NewProtocolError(u.id, WlDataOfferErrorInvalidOffer, "set_actions is only valid for drag and drop offers")
+	default:
+		return UnknownOpcode(packet, WlDataOfferInterface)
+	}
+
+}
@@ -1,32 +1,36 @@
 package wayland
 
-import "fmt"
-
 type DataSource struct {
 	BaseObject
 	id        uint32
+	wsc       *WaylandServerConn
 	mimetypes map[string]bool
+	destroyed bool
+}
+
+func (u *DataSource) Destroy() {
+	u.destroyed = true
 }
 
 func (u *DataSource) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlDataSourceRequestOffer:
 		// 	This request adds a mime type to the set of mime types
 		// advertised to targets.  Can be called several times to offer
 		// multiple types.
-		mimetype := NewStringField()
-		if err := ParsePacketStructure(packet.Data, mimetype); err != nil {
+		req, err := ParseWlDataSourceOfferRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		u.mimetypes[string(*mimetype)] = true
+		u.mimetypes[req.MimeType] = true
 		return nil
-	case 1:
+	case WlDataSourceRequestDestroy:
 		// 	Destroy the data source.
 		wsc.registry.Destroy(u.id)
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on data source: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlDataSourceInterface)
 	}
 
 }
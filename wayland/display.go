@@ -1,9 +1,6 @@
 package wayland
 
-import (
-	"encoding/binary"
-	"fmt"
-)
+import ()
 
 type Display struct {
 	BaseObject
@@ -14,103 +11,37 @@ type Display struct {
 func (d *Display) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-		// sync
-		newID := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newID); err != nil {
+	case WlDisplayRequestSync:
+		req, err := ParseWlDisplaySyncRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		//	d.lastSync[metadata.client] = Callback{id: uint32(*newID)}
-
-		wsc.SendMessage(
-			NewPacketBuilder(uint32(*newID), 0x00).
-				WithUint(0).
-				Build())
-
-		// wsc.SendMessage(
-		// 	NewPacketBuilder(0x01, 0x01).
-		// 		WithUint(uint32(*newID)).
-		// 		Build())
+		callback := &Callback{id: req.Callback}
+		if err := wsc.registry.New(req.Callback, callback, 1); err != nil {
+			return err
+		}
+		callback.Done(wsc, 0)
 
 		d.lastSync += 1
 
 		return nil
-	case 1:
-		// get_registry
+	case WlDisplayRequestGetRegistry:
 		// 	This request creates a registry object that allows the client
 		// to list and bind the global objects available from the
 		//	compositor.
-		newId := binary.LittleEndian.Uint32(packet.Data)
-		wsc.registry.New(newId, &UnboundObject{server: d.server})
-
-		// we only support shared memory...
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x01).
-				WithString("wl_compositor").
-				WithUint(0x05).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x02).
-				WithString("wl_subcompositor").
-				WithUint(0x01).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x03).
-				WithString("wl_seat").
-				WithUint(0x07).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x04).
-				WithString("wl_shm").
-				WithUint(0x02).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x05).
-				WithString("xdg_wm_base").
-				WithUint(0x02).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x06).
-				WithString("wl_data_device_manager").
-				WithUint(0x03).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x07).
-				WithString("wl_output").
-				WithUint(0x01).
-				Build())
-
-		wsc.SendMessage(
-			NewPacketBuilder(newId, 0x00).
-				WithUint(0x08).
-				WithString("wp_viewporter").
-				WithUint(0x01).
-				Build())
-
-		// wsc.SendMessage(
-		// 	NewPacketBuilder(newId, 0x00).
-		// 		WithUint(0x08).
-		// 		WithString("zwp_linux_dmabuf_v1").
-		// 		WithUint(0x04).
-		// 		Build())
+		req, err := ParseWlDisplayGetRegistryRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		registry := &UnboundObject{server: d.server, wsc: wsc, id: req.Registry}
+		if err := wsc.registry.New(req.Registry, registry, 1); err != nil {
+			return err
+		}
+		d.server.globals.AddRegistry(registry)
 
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on display: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlDisplayInterface)
 	}
 
 }
@@ -0,0 +1,49 @@
+package wayland
+
+import (
+	"errors"
+	"fmt"
+
+	"nyctal/utils"
+)
+
+// ProtocolError is a fatal error caused by a client violating the protocol. Returning
+// one from HandleMessage results in a wl_display.error event being sent to the client
+// before it is disconnected.
+// Code is taken from the error enum of the interface the error is raised on
+// e.g. WlShmErrorInvalidFormat, XdgWmBaseErrorRole
+type ProtocolError struct {
+	ObjectId uint32
+	Code     uint32
+	Message  string
+}
+
+func NewProtocolError[E ~uint32](objectId uint32, code E, format string, args ...any) *ProtocolError {
+	return &ProtocolError{ObjectId: objectId, Code: uint32(code), Message: fmt.Sprintf(format, args...)}
+}
+
+func (pe *ProtocolError) Error() string {
+	return fmt.Sprintf("protocol error on object#%d (code %d): %s", pe.ObjectId, pe.Code, pe.Message)
+}
+
+// UnknownOpcode is returned by objects that receive a request they do not implement
+func UnknownOpcode(packet *WaylandMessage, iface string) *ProtocolError {
+	return NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid method %d on %s#%d", packet.Opcode, iface, packet.Address)
+}
+
+// InvalidObject is returned when a request references an object id that does not exist (or is of the wrong type)
+func InvalidObject(packet *WaylandMessage, id uint32, expected string) *ProtocolError {
+	return NewProtocolError(packet.Address, WlDisplayErrorInvalidObject, "invalid object %d, expected %s", id, expected)
+}
+
+// PostError sends err to the client as a wl_display.error event. Errors that are not
+// protocol errors are reported as implementation errors against the display.
+func (c *WaylandServerConn) PostError(err error) {
+	var pe *ProtocolError
+	if !errors.As(err, &pe) {
+		pe = NewProtocolError(1, WlDisplayErrorImplementation, "%v", err)
+	}
+	utils.Debug(int(c.id), "wayland-server", fmt.Sprintf("posting error to client#%d: %v", c.id, pe))
+	SendWlDisplayError(c, 1, pe.ObjectId, pe.Code, pe.Message)
+	c.Flush()
+}
@@ -0,0 +1,170 @@
+package wayland
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"nyctal/model"
+	"nyctal/utils"
+	"nyctal/workspace"
+
+	"golang.org/x/sys/unix"
+)
+
+// The seed corpus is in testdata/fuzz, run e.g. go test ./wayland -fuzz FuzzDispatch to search for more
+
+// fieldFor maps a byte of the fuzzed layout onto a field, as the generated parsers would use
+func fieldFor(b byte) Field {
+	switch b % 11 {
+	case 0:
+		return NewUintField()
+	case 1:
+		return NewIntField()
+	case 2:
+		return NewFixedField()
+	case 3:
+		return NewStringField()
+	case 4:
+		return NewNullableStringField()
+	case 5:
+		return NewObjectField()
+	case 6:
+		return NewNullableObjectField()
+	case 7:
+		return NewNewIdField()
+	case 8:
+		return NewArrayField()
+	case 9:
+		return NewFdField()
+	default:
+		return NewUint64Field()
+	}
+}
+
+// FuzzParsePacketStructure checks that arbitrary message bodies never crash the decoder, and that
+// any body it accepts is re-encoded exactly (other than fds and fixed values, as a float32 cannot hold
+// every 24.8 fixed point value)
+func FuzzParsePacketStructure(f *testing.F) {
+	f.Add(NewPacketBuilder(1, 0).WithUint(3).WithString("wl_compositor").WithUint(5).WithUint(7).Build()[8:], []byte{0, 3, 0, 0})
+	f.Add(NewPacketBuilder(1, 0).WithNewId("wl_seat", 7, 12).Build()[8:], []byte{0, 7})
+	f.Add(NewPacketBuilder(1, 0).WithArray([]byte{1, 2, 3}).WithNullableString(nil).Build()[8:], []byte{8, 4})
+
+	f.Fuzz(func(t *testing.T, body []byte, layout []byte) {
+		fields := make([]Field, len(layout))
+		lossy := false
+		for i, b := range layout {
+			fields[i] = fieldFor(b)
+			switch fields[i].(type) {
+			case *FdField, *FixedField:
+				lossy = true
+			}
+		}
+		fds := utils.NewQueue[int]()
+		for range layout {
+			fds.Push(-1)
+		}
+		if err := ParsePacketStructureWithFds(body, fds, fields...); err != nil || lossy {
+			return
+		}
+		var encoded []byte
+		for _, field := range fields {
+			encoded = field.AppendToBuf(encoded)
+		}
+		if !bytes.Equal(encoded, body) {
+			t.Fatalf("accepted body % x was re-encoded as % x", body, encoded)
+		}
+	})
+}
+
+// fuzzServer creates a server whose output is discarded, utils.Debug logs every request which
+// would otherwise dominate the time spent fuzzing
+func fuzzServer(f *testing.F) *WaylandServer {
+	stdout := os.Stdout
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		f.Fatal(err)
+	}
+	os.Stdout = devnull
+	f.Cleanup(func() {
+		os.Stdout = stdout
+		devnull.Close()
+	})
+
+	ws, err := NewServer(filepath.Join(f.TempDir(), "wayland-fuzz"), workspace.NewDragOverlay())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// isFault reports whether a recovered panic was a memory fault (e.g. SIGBUS on a shm pool larger
+// than its file), which SetPanicOnFault turns into a panic that handle recovers from
+func isFault(r any) bool {
+	_, ok := r.(interface{ Addr() uintptr })
+	return ok
+}
+
+// FuzzDispatch feeds a stream of requests from a single client through the registry, with a frame
+// rendered after every surface commit. Each of the first fds requests that consume an fd are given a memfd of fdSize bytes.
+func FuzzDispatch(f *testing.F) {
+	ws := fuzzServer(f)
+
+	f.Fuzz(func(t *testing.T, stream []byte, fds uint8, fdSize uint16) {
+		debug.SetPanicOnFault(true)
+		defer debug.SetPanicOnFault(false)
+
+		pair, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unix.Close(pair[1])
+		// serials are compositor wide, start them afresh so that the serials sent (and those in the
+		// corpus, e.g. for ack_configure) only depend on the input
+		ws.serials = Serials{}
+		wsc := ws.newConn(nil, pair[0])
+		defer ws.removeConn(wsc)
+
+		for i := 0; i < int(fds%maxFdsPerMsg); i++ {
+			fd, err := unix.MemfdCreate("fuzz", unix.MFD_CLOEXEC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			unix.Ftruncate(fd, int64(fdSize))
+			wsc.fds.Push(fd)
+		}
+		for _, free := range wsc.in.Free() {
+			n := copy(free, stream)
+			wsc.in.Commit(n)
+			stream = stream[n:]
+		}
+
+		defer func() {
+			if r := recover(); r != nil && !isFault(r) {
+				panic(r)
+			}
+		}()
+		render := func() {
+			ws.workspace.Buffer(model.EmptyBGRA(image.Rect(0, 0, 64, 64)), 64, 64)
+		}
+		for {
+			packet, err := wsc.readBuffered()
+			if err != nil || packet == nil {
+				break
+			}
+			obj, _ := wsc.registry.Get(packet.Address)
+			if err := ws.dispatch(wsc, packet); err != nil {
+				break
+			}
+			// surface contents are read when rendering, so render every committed state
+			if _, ok := obj.(*Surface); ok && packet.Opcode == WlSurfaceRequestCommit {
+				render()
+			}
+		}
+		render()
+	})
+}
@@ -0,0 +1,236 @@
+package wayland
+
+import (
+	"fmt"
+	"image"
+	"sync"
+
+	"nyctal/model"
+	"nyctal/utils"
+)
+
+// BindFunc creates the resource id for a client that has bound a global, version is the
+// version requested by the client (which is never greater than the version of the global)
+type BindFunc func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error
+
+// Global is an object advertised to every client through wl_registry
+type Global struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+	Bind      BindFunc
+	removed   bool
+}
+
+// GlobalPolicy decides whether a global is advertised to (and can be bound by) a client, it is called
+// with the global table locked and so must not add or remove globals
+type GlobalPolicy func(wsc *WaylandServerConn, global *Global) bool
+
+// GlobalTable holds the globals advertised by the server, and every wl_registry they have
+// been advertised to
+type GlobalTable struct {
+	lock       sync.Mutex
+	nextName   uint32
+	globals    map[uint32]*Global
+	order      []uint32
+	registries map[*UnboundObject]bool
+	// removing holds the registries that were sent global_remove for each removed global, which may
+	// still bind it until they have seen the event. Once they are all gone the global is forgotten.
+	removing map[uint32]map[*UnboundObject]bool
+	policy   GlobalPolicy
+}
+
+// SetPolicy restricts the globals visible to each client, globals that have already been advertised are not retracted
+func (gt *GlobalTable) SetPolicy(policy GlobalPolicy) {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	gt.policy = policy
+}
+
+func (gt *GlobalTable) visibleLocked(wsc *WaylandServerConn, global *Global) bool {
+	return gt.policy == nil || gt.policy(wsc, global)
+}
+
+// Visible returns true if the client is allowed to see and bind the global
+func (gt *GlobalTable) Visible(wsc *WaylandServerConn, global *Global) bool {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	return gt.visibleLocked(wsc, global)
+}
+
+func NewGlobalTable() *GlobalTable {
+	return &GlobalTable{globals: make(map[uint32]*Global), registries: make(map[*UnboundObject]bool), removing: make(map[uint32]map[*UnboundObject]bool)}
+}
+
+// Add registers a new global and announces it to all existing registries, the name of the global is returned
+func (gt *GlobalTable) Add(iface string, version uint32, bind BindFunc) uint32 {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	gt.nextName += 1
+	global := &Global{Name: gt.nextName, Interface: iface, Version: version, Bind: bind}
+	gt.globals[global.Name] = global
+	gt.order = append(gt.order, global.Name)
+	for registry := range gt.registries {
+		if gt.visibleLocked(registry.wsc, global) {
+			SendWlRegistryGlobal(registry.wsc, registry.id, global.Name, global.Interface, global.Version)
+			registry.wsc.Flush()
+		}
+	}
+	return global.Name
+}
+
+// Remove unregisters a global and sends global_remove to all existing registries. Clients may still
+// bind a removed global (if they have not yet seen the global_remove) but the resulting object is inert,
+// so it is kept until all of those registries have been destroyed.
+func (gt *GlobalTable) Remove(name uint32) error {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	global, ok := gt.globals[name]
+	if !ok || global.removed {
+		return fmt.Errorf("unknown global %d", name)
+	}
+	global.removed = true
+	for i, n := range gt.order {
+		if n == name {
+			gt.order = append(gt.order[:i], gt.order[i+1:]...)
+			break
+		}
+	}
+	removing := make(map[*UnboundObject]bool)
+	for registry := range gt.registries {
+		if gt.visibleLocked(registry.wsc, global) {
+			SendWlRegistryGlobalRemove(registry.wsc, registry.id, name)
+			registry.wsc.Flush()
+			removing[registry] = true
+		}
+	}
+	if len(removing) == 0 {
+		delete(gt.globals, name)
+	} else {
+		gt.removing[name] = removing
+	}
+	return nil
+}
+
+// Get returns a copy of the global with the given name if registry can bind it, this includes globals
+// that have been removed if the registry was sent the global_remove
+func (gt *GlobalTable) Get(registry *UnboundObject, name uint32) (Global, bool) {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	global, ok := gt.globals[name]
+	if !ok || !gt.visibleLocked(registry.wsc, global) {
+		return Global{}, false
+	}
+	if global.removed && !gt.removing[name][registry] {
+		return Global{}, false
+	}
+	return *global, true
+}
+
+// AddRegistry announces all current globals to a new registry, and keeps it updated as globals change
+func (gt *GlobalTable) AddRegistry(registry *UnboundObject) {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	for _, name := range gt.order {
+		global := gt.globals[name]
+		if gt.visibleLocked(registry.wsc, global) {
+			SendWlRegistryGlobal(registry.wsc, registry.id, global.Name, global.Interface, global.Version)
+		}
+	}
+	gt.registries[registry] = true
+}
+
+func (gt *GlobalTable) RemoveRegistry(registry *UnboundObject) {
+	gt.lock.Lock()
+	defer gt.lock.Unlock()
+	delete(gt.registries, registry)
+	for name, removing := range gt.removing {
+		delete(removing, registry)
+		if len(removing) == 0 {
+			delete(gt.removing, name)
+			delete(gt.globals, name)
+		}
+	}
+}
+
+// registerDefaultGlobals adds the globals supported by nyctal to the server (we only support shared memory...)
+func (ws *WaylandServer) registerDefaultGlobals() {
+	ws.AddGlobal(WlCompositorInterface, 5, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		if err := wsc.registry.New(id, &Compositor{}, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	ws.AddGlobal(WlSubcompositorInterface, 1, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		if err := wsc.registry.New(id, &SubCompositor{}, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	ws.AddGlobal(WlSeatInterface, 7, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		if seat := wsc.registry.FindSeat(); seat != nil {
+			if err := wsc.registry.New(id, seat, version); err != nil {
+				return err
+			}
+			seat.id = id
+		} else {
+			_, err := NewSeat(wsc, id, version)
+			return err
+		}
+		return nil
+	})
+	ws.AddGlobal(WlShmInterface, 2, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		for _, format := range model.Formats() {
+			SendWlShmFormat(wsc, id, WlShmFormat(format))
+		}
+		if err := wsc.registry.New(id, &SHM{id: id}, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	ws.AddGlobal(XdgWmBaseInterface, 2, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		wmbase := &XDG_Base{server: ws, wsc: wsc, id: id}
+		wsc.pingtarget = wmbase
+		if err := wsc.registry.New(id, wmbase, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	ws.AddGlobal(WlDataDeviceManagerInterface, 3, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		if err := wsc.registry.New(id, &DataDeviceManager{id: id}, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	// resized (or replaced) by the backend once it knows the size of the display, see ResizeOutput
+	ws.AddOutput(image.Rect(0, 0, 1024, 1024))
+	ws.AddGlobal("wp_viewporter", 1, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		if err := wsc.registry.New(id, &WPViewporter{id: id}, version); err != nil {
+			return err
+		}
+		return nil
+	})
+	// ws.AddGlobal("zwp_linux_dmabuf_v1", 4, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+	// 	wsc.registry.New(id, NewLinuxDMABuf(ws), version)
+	// 	return nil
+	// })
+}
+
+// AddGlobal advertises a new global to all clients, returning its name
+func (ws *WaylandServer) AddGlobal(iface string, version uint32, bind BindFunc) uint32 {
+	name := ws.globals.Add(iface, version, bind)
+	utils.Debug(0, "globals", fmt.Sprintf("added %s v%d as global#%d", iface, version, name))
+	return name
+}
+
+// SetGlobalPolicy decides which globals each client can see and bind e.g. to hide privileged interfaces
+// such as screen capture from ordinary applications (see WaylandServerConn.Credentials)
+func (ws *WaylandServer) SetGlobalPolicy(policy GlobalPolicy) {
+	ws.globals.SetPolicy(policy)
+}
+
+// RemoveGlobal withdraws a global from all clients (e.g. when an output is unplugged)
+func (ws *WaylandServer) RemoveGlobal(name uint32) error {
+	utils.Debug(0, "globals", fmt.Sprintf("removing global#%d", name))
+	return ws.globals.Remove(name)
+}
@@ -1,6 +1,7 @@
 package wayland
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"nyctal/model"
@@ -15,12 +16,14 @@ type Keyboard struct {
 	wsc           *WaylandServerConn
 }
 
-func NewKeyboard(id uint32, wsc *WaylandServerConn) *Keyboard {
+func NewKeyboard(id uint32, version uint32, wsc *WaylandServerConn) (*Keyboard, error) {
 	keyboard := &Keyboard{id: id, wsc: wsc, kb: model.NewKeyboardModel()}
-	wsc.registry.New(id, keyboard)
-	wsc.SendMessage(NewPacketBuilder(id, 0x05).WithUint(40).WithUint(400).Build())
+	if err := wsc.registry.New(id, keyboard, version); err != nil {
+		return nil, err
+	}
+	SendWlKeyboardRepeatInfo(wsc, id, 40, 400)
 	keyboard.SendKeyMap()
-	return keyboard
+	return keyboard, nil
 }
 
 // The only keymap specified in most wayland specs is the xkbcommon
@@ -31,13 +34,9 @@ func NewKeyboard(id uint32, wsc *WaylandServerConn) *Keyboard {
 // Note: Because of this nyctal running in an x11 window is dependent on a custom fork
 // of minifb which surfaces scancodes instead of xkbcommon codes...
 func (u *Keyboard) SendKeyMap() {
-	pb := NewPacketBuilder(u.id, 0x00).
-		WithUint(0).
-		WithUint(0)
-
 	utils.Debug(int(u.wsc.id), fmt.Sprintf("wl_keyboard#%d", u.id), fmt.Sprintf("keymap %d %d", 0, 0))
 
-	u.wsc.SendMessageWithFd(pb.Build(), 0)
+	SendWlKeyboardKeymap(u.wsc, u.id, WlKeyboardKeymapFormatNoKeymap, 0, 0)
 }
 
 func (u *Keyboard) Enter(serial uint32, surface *Surface) {
@@ -46,18 +45,13 @@ func (u *Keyboard) Enter(serial uint32, surface *Surface) {
 
 	downKeys := u.kb.DownKeys()
 
-	pb := NewPacketBuilder(u.id, 0x01).
-		WithUint(serial).
-		WithUint(u.activeSurface.id).
-		WithUint(uint32(len(downKeys)))
-
+	keys := []byte{}
 	for key := range downKeys {
-		pb.WithUint(uint32(key))
-
+		keys = binary.LittleEndian.AppendUint32(keys, uint32(key))
 	}
-	utils.Debug(int(u.wsc.id), fmt.Sprintf("wl_keyboard#%d", u.id), fmt.Sprintf("enter %d %d %x", serial, u.activeSurface.id, pb.Build()))
+	utils.Debug(int(u.wsc.id), fmt.Sprintf("wl_keyboard#%d", u.id), fmt.Sprintf("enter %d %d %x", serial, u.activeSurface.id, keys))
 
-	u.wsc.SendMessage(pb.Build())
+	SendWlKeyboardEnter(u.wsc, u.id, serial, u.activeSurface.id, keys)
 	u.sendModifiers(serial)
 
 	if dd := u.wsc.registry.FindDataDevice(); dd != nil {
@@ -69,11 +63,8 @@ func (u *Keyboard) Enter(serial uint32, surface *Surface) {
 func (u *Keyboard) Leave(serial uint32) {
 
 	if u.activeSurface != nil {
-		pb := NewPacketBuilder(u.id, 0x02).
-			WithUint(serial).
-			WithUint(u.activeSurface.id)
 		utils.Debug(int(u.wsc.id), fmt.Sprintf("wl_keyboard#%d", u.id), fmt.Sprintf("leave: %v", serial))
-		u.wsc.SendMessage(pb.Build())
+		SendWlKeyboardLeave(u.wsc, u.id, serial, u.activeSurface.id)
 	}
 }
 
@@ -83,12 +74,7 @@ func (u *Keyboard) ProcessKeyboardEvent(ev model.KeyboardEvent, serial uint32) {
 	if u.activeSurface != nil {
 
 		utils.Debug(int(u.wsc.id), "keyboard", "processing keyboard event")
-		pb := NewPacketBuilder(u.id, 0x03).
-			WithUint(serial).
-			WithUint(ev.Time).
-			WithUint(ev.Key).
-			WithUint(ev.State)
-		u.wsc.SendMessage(pb.Build())
+		SendWlKeyboardKey(u.wsc, u.id, serial, ev.Time, ev.Key, WlKeyboardKeyState(ev.State))
 		u.sendModifiers(serial)
 	}
 }
@@ -105,24 +91,18 @@ func (u *Keyboard) sendModifiers(serial uint32) {
 		depressed |= 0x4
 	}
 
-	pb := NewPacketBuilder(u.id, 0x04).
-		WithUint(serial).
-		WithUint(uint32(depressed)).
-		WithUint(0).
-		WithUint(0).
-		WithUint(0)
-	u.wsc.SendMessage(pb.Build())
+	SendWlKeyboardModifiers(u.wsc, u.id, serial, uint32(depressed), 0, 0, 0)
 }
 
 func (u *Keyboard) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlKeyboardRequestRelease:
 		// release
 		wsc.registry.Destroy(u.id)
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on keyboard: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlKeyboardInterface)
 	}
 
 }
@@ -0,0 +1,90 @@
+package wayland
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketpair creates a connected pair of sockets, returning the compositor's end as an fd and the client's end as a file
+func socketpair() (int, *os.File, error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return -1, nil, fmt.Errorf("could not create socketpair: %v", err)
+	}
+	return fds[0], os.NewFile(uintptr(fds[1]), "wayland-client"), nil
+}
+
+// clientSocket creates a connected socketpair, passing one end to cmd as WAYLAND_SOCKET and
+// starting it. The other end is returned for the compositor.
+func clientSocket(cmd *exec.Cmd) (int, error) {
+	connFd, clientEnd, err := socketpair()
+	if err != nil {
+		return -1, err
+	}
+	defer clientEnd.Close()
+
+	// ExtraFiles[i] becomes fd 3+i in the child
+	cmd.ExtraFiles = append(cmd.ExtraFiles, clientEnd)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("WAYLAND_SOCKET=%d", 2+len(cmd.ExtraFiles)))
+	if err := cmd.Start(); err != nil {
+		unix.Close(connFd)
+		return -1, err
+	}
+	return connFd, nil
+}
+
+// LaunchClient starts cmd as a client connected to the compositor through WAYLAND_SOCKET rather
+// than the display socket. The returned connection belongs to the child process, which must still
+// be waited for by the caller. Clients are handled as if they had connected to Serve.
+func (ws *WaylandServer) LaunchClient(cmd *exec.Cmd) (*WaylandServerConn, error) {
+	connFd, err := clientSocket(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// see Serve
+	syscall.SetsockoptTimeval(connFd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &syscall.Timeval{Sec: 2})
+	wsc := ws.newConn(nil, connFd)
+	ws.serveConn(wsc)
+	return wsc, nil
+}
+
+// ConnectClient connects an in-process client (e.g. wayland/testclient), returning the client's end of the
+// connection. Clients are handled as if they had connected to Serve.
+func (ws *WaylandServer) ConnectClient() (*os.File, *WaylandServerConn, error) {
+	connFd, clientEnd, err := socketpair()
+	if err != nil {
+		return nil, nil, err
+	}
+	// see Serve
+	syscall.SetsockoptTimeval(connFd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &syscall.Timeval{Sec: 2})
+	wsc := ws.newConn(nil, connFd)
+	ws.serveConn(wsc)
+	return clientEnd, wsc, nil
+}
+
+// LaunchClient starts cmd as a client connected through WAYLAND_SOCKET, see WaylandServer.LaunchClient.
+// It must be called before Run or from the loop (e.g. in a source handler).
+func (el *EventLoop) LaunchClient(cmd *exec.Cmd) (*WaylandServerConn, error) {
+	connFd, err := clientSocket(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.SetNonblock(connFd, true); err != nil {
+		unix.Close(connFd)
+		return nil, fmt.Errorf("could not make client socket nonblocking: %v", err)
+	}
+	wsc := el.ws.newConn(nil, connFd)
+	if err := el.add(connFd); err != nil {
+		el.ws.removeConn(wsc)
+		return nil, err
+	}
+	el.clients[connFd] = wsc
+	return wsc, nil
+}
@@ -0,0 +1,77 @@
+package wayland
+
+import (
+	"time"
+)
+
+// Limits bounds the resources a single client can consume, a client that exceeds any of them
+// is sent a protocol error and disconnected. A limit of zero means unlimited.
+type Limits struct {
+	// MaxObjects is the number of live protocol objects a client can have
+	MaxObjects int
+	// MaxSHMBytes is the total size of all shm pools mapped for a client and the surface contents copied from them
+	MaxSHMBytes int64
+	// MaxPendingFds is the number of fds a client can send that have not yet been consumed by a request
+	MaxPendingFds int
+	// MaxOutputBytes is the amount of unsent events buffered for a client that has stopped reading
+	MaxOutputBytes int
+	// MaxRequestsPerSecond is the number of requests a client can make each second
+	MaxRequestsPerSecond int
+}
+
+// DefaultLimits are generous enough for any well-behaved application
+func DefaultLimits() Limits {
+	return Limits{
+		MaxObjects:           1 << 16,
+		MaxSHMBytes:          2048 * 2048 * 16 * 4,
+		MaxPendingFds:        maxFdsPerMsg * 4,
+		MaxOutputBytes:       4 << 20,
+		MaxRequestsPerSecond: 100000,
+	}
+}
+
+// SetLimits changes the limits applied to clients that connect from now on
+func (ws *WaylandServer) SetLimits(limits Limits) {
+	ws.limits = limits
+}
+
+// rateLimiter counts requests in one second windows
+type rateLimiter struct {
+	window   time.Time
+	requests int
+}
+
+// checkLimits is called after every request is dispatched
+func (c *WaylandServerConn) checkLimits() error {
+	if c.limits.MaxObjects > 0 && c.registry.Len() > c.limits.MaxObjects {
+		return NewProtocolError(1, WlDisplayErrorNoMemory, "too many objects (limit %d)", c.limits.MaxObjects)
+	}
+	if c.limits.MaxPendingFds > 0 && len(c.fds.Inner()) > c.limits.MaxPendingFds {
+		return NewProtocolError(1, WlDisplayErrorNoMemory, "too many unused fds (limit %d)", c.limits.MaxPendingFds)
+	}
+	if c.limits.MaxRequestsPerSecond > 0 {
+		now := time.Now()
+		if now.Sub(c.rate.window) >= time.Second {
+			c.rate.window = now
+			c.rate.requests = 0
+		}
+		c.rate.requests += 1
+		if c.rate.requests > c.limits.MaxRequestsPerSecond {
+			return NewProtocolError(1, WlDisplayErrorImplementation, "too many requests (limit %d per second)", c.limits.MaxRequestsPerSecond)
+		}
+	}
+	return nil
+}
+
+// reserveSHM accounts for size bytes of newly mapped shm, failing if the client would exceed its limit
+func (c *WaylandServerConn) reserveSHM(size int64) error {
+	if total := c.shmBytes.Add(size); c.limits.MaxSHMBytes > 0 && total > c.limits.MaxSHMBytes {
+		c.shmBytes.Add(-size)
+		return NewProtocolError(1, WlDisplayErrorNoMemory, "too much shared memory mapped (limit %d bytes)", c.limits.MaxSHMBytes)
+	}
+	return nil
+}
+
+func (c *WaylandServerConn) releaseSHM(size int64) {
+	c.shmBytes.Add(-size)
+}
@@ -1,8 +1,6 @@
 package wayland
 
-import (
-	"fmt"
-)
+import ()
 
 // NOTE: Prototype Code...
 type LinuxDMABuf struct {
@@ -25,14 +23,18 @@ func (u *LinuxDMABuf) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessa
 		if err := ParsePacketStructure(packet.Data, newId); err != nil {
 			return err
 		}
-		wsc.registry.New(uint32(*newId), NewLinuxDMABufParams(uint32(*newId), wsc))
+		if err := wsc.registry.New(uint32(*newId), NewLinuxDMABufParams(uint32(*newId), wsc), wsc.registry.Version(packet.Address)); err != nil {
+			return err
+		}
 		return nil
 	case 2:
 		newId := NewUintField()
 		if err := ParsePacketStructure(packet.Data, newId); err != nil {
 			return err
 		}
-		wsc.registry.New(uint32(*newId), NewLinuxDMABufFeedback(uint32(*newId), 0, wsc))
+		if err := wsc.registry.New(uint32(*newId), NewLinuxDMABufFeedback(uint32(*newId), 0, wsc), wsc.registry.Version(packet.Address)); err != nil {
+			return err
+		}
 		return nil
 	case 3:
 		newId := NewUintField()
@@ -40,10 +42,12 @@ func (u *LinuxDMABuf) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessa
 		if err := ParsePacketStructure(packet.Data, newId, surfaceId); err != nil {
 			return err
 		}
-		wsc.registry.New(uint32(*newId), NewLinuxDMABufFeedback(uint32(*newId), uint32(*surfaceId), wsc))
+		if err := wsc.registry.New(uint32(*newId), NewLinuxDMABufFeedback(uint32(*newId), uint32(*surfaceId), wsc), wsc.registry.Version(packet.Address)); err != nil {
+			return err
+		}
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on linux dmabuf object: %v", packet.Opcode)
+		return UnknownOpcode(packet, "zwp_linux_dmabuf_v1")
 	}
 }
 
@@ -63,7 +67,7 @@ func (u *LinuxDMABufParams) HandleMessage(wsc *WaylandServerConn, packet *Waylan
 		// destroy
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on unbound object: %v", packet.Opcode)
+		return UnknownOpcode(packet, "zwp_linux_buffer_params_v1")
 	}
 }
 
@@ -97,6 +101,6 @@ func (u *LinuxDMABufFeedback) HandleMessage(wsc *WaylandServerConn, packet *Wayl
 		return nil
 
 	default:
-		return fmt.Errorf("unknown opcode called on linux dmabuf feedback object: %v", packet.Opcode)
+		return UnknownOpcode(packet, "zwp_linux_dmabuf_feedback_v1")
 	}
 }
@@ -0,0 +1,232 @@
+package wayland
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"nyctal/utils"
+
+	"golang.org/x/sys/unix"
+)
+
+// EventLoop is an alternative to Listen that multiplexes the listening socket, every client, and
+// any other sources (e.g. input devices, frame timers) with epoll on a single goroutine. As requests,
+// input and rendering are never handled concurrently none of the protocol state needs to be locked.
+//
+// Unlike Listen, clients are not pinged when idle.
+type EventLoop struct {
+	ws       *WaylandServer
+	epfd     int
+	listenFd int
+	clients  map[int]*WaylandServerConn
+	sources  map[int]func() error
+	// wakeFd is an eventfd written by Close and Shutdown to wake Run, which then returns (disconnecting
+	// every client first if shutdown is set). done is closed once Run has returned.
+	wakeFd   int
+	shutdown atomic.Bool
+	done     chan struct{}
+}
+
+func (ws *WaylandServer) NewEventLoop() (*EventLoop, error) {
+	listener, ok := ws.l.(syscall.Conn)
+	if !ok {
+		return nil, fmt.Errorf("listener does not expose its fd")
+	}
+	listenFd, err := getRawFd(listener)
+	if err != nil {
+		return nil, err
+	}
+
+	epfd, err := unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("could not create epoll: %v", err)
+	}
+	wakeFd, err := unix.Eventfd(0, unix.EFD_NONBLOCK|unix.EFD_CLOEXEC)
+	if err != nil {
+		unix.Close(epfd)
+		return nil, fmt.Errorf("could not create eventfd: %v", err)
+	}
+	el := &EventLoop{ws: ws, epfd: epfd, listenFd: listenFd, wakeFd: wakeFd, done: make(chan struct{}),
+		clients: make(map[int]*WaylandServerConn), sources: make(map[int]func() error)}
+	if err := el.add(listenFd); err != nil {
+		unix.Close(epfd)
+		unix.Close(wakeFd)
+		return nil, err
+	}
+	if err := el.add(wakeFd); err != nil {
+		unix.Close(epfd)
+		unix.Close(wakeFd)
+		return nil, err
+	}
+	ws.loop.Store(el)
+	return el, nil
+}
+
+func (el *EventLoop) add(fd int) error {
+	event := unix.EpollEvent{Events: unix.EPOLLIN, Fd: int32(fd)}
+	if err := unix.EpollCtl(el.epfd, unix.EPOLL_CTL_ADD, fd, &event); err != nil {
+		return fmt.Errorf("could not add fd %d to epoll: %v", fd, err)
+	}
+	return nil
+}
+
+// AddSource calls handler from the loop whenever fd is readable, the source is removed if handler returns an error
+func (el *EventLoop) AddSource(fd int, handler func() error) error {
+	if err := el.add(fd); err != nil {
+		return err
+	}
+	el.sources[fd] = handler
+	return nil
+}
+
+// AddTimer calls handler from the loop every interval e.g. to render frames
+func (el *EventLoop) AddTimer(interval time.Duration, handler func()) error {
+	tfd, err := unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("could not create timer: %v", err)
+	}
+	spec := unix.NsecToTimespec(interval.Nanoseconds())
+	if err := unix.TimerfdSettime(tfd, 0, &unix.ItimerSpec{Interval: spec, Value: spec}, nil); err != nil {
+		unix.Close(tfd)
+		return fmt.Errorf("could not start timer: %v", err)
+	}
+	expirations := make([]byte, 8)
+	return el.AddSource(tfd, func() error {
+		if _, err := unix.Read(tfd, expirations); err != nil && err != unix.EAGAIN {
+			return err
+		}
+		if binary.LittleEndian.Uint64(expirations) > 0 {
+			handler()
+		}
+		return nil
+	})
+}
+
+// wake interrupts Run from another goroutine
+func (el *EventLoop) wake() {
+	unix.Write(el.wakeFd, binary.LittleEndian.AppendUint64(nil, 1))
+}
+
+// Run dispatches events until the server is closed (see Close and Shutdown), returning nil once it has been
+func (el *EventLoop) Run() error {
+	defer close(el.done)
+	// see handle
+	debug.SetPanicOnFault(true)
+
+	events := make([]unix.EpollEvent, 64)
+	for {
+		n, err := unix.EpollWait(el.epfd, events, -1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("epoll_wait: %v", err)
+		}
+		for _, event := range events[:n] {
+			fd := int(event.Fd)
+			if fd == el.wakeFd {
+				unix.Read(el.wakeFd, make([]byte, 8))
+				if el.shutdown.Load() {
+					// the clients are only ever touched from the loop, so Shutdown leaves disconnecting them to us
+					for _, wsc := range el.clients {
+						wsc.Flush()
+						el.removeClient(wsc)
+					}
+				}
+				if el.ws.closing.Load() {
+					return nil
+				}
+			} else if fd == el.listenFd {
+				if err := el.accept(); err != nil {
+					return err
+				}
+			} else if wsc, ok := el.clients[fd]; ok {
+				el.handleClient(wsc)
+			} else if handler, ok := el.sources[fd]; ok {
+				if err := handler(); err != nil {
+					utils.Debug(0, "event-loop", fmt.Sprintf("removing source %d: %v", fd, err))
+					unix.EpollCtl(el.epfd, unix.EPOLL_CTL_DEL, fd, nil)
+					delete(el.sources, fd)
+				}
+			}
+		}
+	}
+}
+
+func (el *EventLoop) accept() error {
+	connFd, _, err := unix.Accept4(el.listenFd, unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC)
+	if err == unix.EAGAIN || err == unix.EINTR || err == unix.ECONNABORTED {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("accept: %v", err)
+	}
+	wsc := el.ws.newConn(nil, connFd)
+	if err := el.add(connFd); err != nil {
+		el.ws.removeConn(wsc)
+		return nil
+	}
+	el.clients[connFd] = wsc
+	return nil
+}
+
+// handleClient reads everything available from a client and dispatches every complete request
+func (el *EventLoop) handleClient(wsc *WaylandServerConn) {
+	defer func() {
+		if r := recover(); r != nil {
+			debug.PrintStack()
+			utils.Debug(int(wsc.id), "wayland-server", fmt.Sprintf("recovered panic in event loop %v", r))
+			el.removeClient(wsc)
+		}
+	}()
+
+	if err := wsc.fill(); err != nil && !errors.Is(err, unix.EAGAIN) {
+		utils.Debug(int(wsc.id), "wayland-server", err.Error())
+		if _, ok := err.(*ProtocolError); ok {
+			wsc.PostError(err)
+		}
+		el.removeClient(wsc)
+		return
+	}
+
+	for {
+		packet, err := wsc.readBuffered()
+		if err != nil {
+			utils.Debug(int(wsc.id), "wayland-server", err.Error())
+			el.removeClient(wsc)
+			return
+		}
+		if packet == nil {
+			break
+		}
+		if err := el.ws.dispatch(wsc, packet); err != nil {
+			el.removeClient(wsc)
+			return
+		}
+	}
+
+	if err := wsc.Flush(); err != nil {
+		el.removeClient(wsc)
+	}
+}
+
+func (el *EventLoop) removeClient(wsc *WaylandServerConn) {
+	unix.EpollCtl(el.epfd, unix.EPOLL_CTL_DEL, wsc.connFd, nil)
+	delete(el.clients, wsc.connFd)
+	el.ws.removeConn(wsc)
+}
+
+// Flush sends any buffered events to every client, it should be called after events are generated
+// outside of request dispatch (e.g. after rendering a frame)
+func (el *EventLoop) Flush() {
+	for _, wsc := range el.clients {
+		if err := wsc.Flush(); err != nil {
+			el.removeClient(wsc)
+		}
+	}
+}
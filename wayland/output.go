@@ -2,45 +2,146 @@ package wayland
 
 import (
 	"fmt"
+	"image"
+	"slices"
+	"sync"
+
+	"nyctal/utils"
 )
 
+// outputHead is a display showing part of the workspace, which clients see as a wl_output global
+type outputHead struct {
+	name    uint32
+	lock    sync.Mutex
+	bounds  image.Rectangle // the area of the workspace shown
+	removed bool
+}
+
+// Overlaps reports whether any of area (in workspace coordinates) is shown on the output
+func (h *outputHead) Overlaps(area image.Rectangle) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return !h.removed && h.bounds.Overlaps(area)
+}
+
+func (h *outputHead) Bounds() image.Rectangle {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.bounds
+}
+
+// AddOutput advertises an output showing bounds of the workspace, returning the name of its global
+func (ws *WaylandServer) AddOutput(bounds image.Rectangle) uint32 {
+	head := &outputHead{bounds: bounds}
+	ws.outputsLock.Lock()
+	defer ws.outputsLock.Unlock()
+	head.name = ws.AddGlobal(WlOutputInterface, 3, func(ws *WaylandServer, wsc *WaylandServerConn, id uint32, version uint32) error {
+		_, err := NewOutput(id, version, wsc, head)
+		return err
+	})
+	ws.outputs[head.name] = head
+	return head.name
+}
+
+// Outputs returns the names of the globals of all outputs
+func (ws *WaylandServer) Outputs() []uint32 {
+	ws.outputsLock.Lock()
+	defer ws.outputsLock.Unlock()
+	names := make([]uint32, 0, len(ws.outputs))
+	for name := range ws.outputs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ResizeOutput changes the area of the workspace an output shows, clients that have bound it are sent
+// the new geometry and mode
+func (ws *WaylandServer) ResizeOutput(name uint32, bounds image.Rectangle) error {
+	ws.outputsLock.Lock()
+	head, ok := ws.outputs[name]
+	ws.outputsLock.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown output %d", name)
+	}
+	head.lock.Lock()
+	head.bounds = bounds
+	head.lock.Unlock()
+	utils.Debug(0, "output", fmt.Sprintf("output#%d resized to %v", name, bounds))
+
+	ws.connsLock.Lock()
+	defer ws.connsLock.Unlock()
+	for wsc := range ws.conns {
+		for _, output := range wsc.registry.Outputs() {
+			if output.head == head {
+				output.sendMode(wsc)
+			}
+		}
+		wsc.Flush()
+	}
+	return nil
+}
+
+// RemoveOutput withdraws an output (e.g. when a display is unplugged), surfaces that were on it are sent
+// leave straight away
+func (ws *WaylandServer) RemoveOutput(name uint32) error {
+	ws.outputsLock.Lock()
+	head, ok := ws.outputs[name]
+	delete(ws.outputs, name)
+	ws.outputsLock.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown output %d", name)
+	}
+	head.lock.Lock()
+	head.removed = true
+	head.lock.Unlock()
+
+	ws.connsLock.Lock()
+	for wsc := range ws.conns {
+		for _, surface := range wsc.registry.Surfaces() {
+			surface.leaveOutputs(wsc, func(output *Output) bool { return output.head == head })
+		}
+		wsc.Flush()
+	}
+	ws.connsLock.Unlock()
+	return ws.RemoveGlobal(name)
+}
+
 type Output struct {
 	BaseObject
-	id uint32
+	id      uint32
+	version uint32
+	head    *outputHead
 }
 
-func NewOutput(id uint32, wsc *WaylandServerConn) *Output {
-	output := &Output{id: id}
-	wsc.registry.New(id, output)
-
-	wsc.SendMessage(NewPacketBuilder(id, 0x00).
-		WithUint(0).
-		WithUint(0).
-		WithUint(1024).
-		WithUint(1024).
-		WithUint(0).
-		WithString("nyctal").
-		WithString("none").
-		WithUint(0).
-		Build())
+func NewOutput(id uint32, version uint32, wsc *WaylandServerConn, head *outputHead) (*Output, error) {
+	output := &Output{id: id, version: version, head: head}
+	if err := wsc.registry.New(id, output, version); err != nil {
+		return nil, err
+	}
+	output.sendMode(wsc)
+	return output, nil
+}
 
-	wsc.SendMessage(NewPacketBuilder(id, 0x01).
-		WithUint(3).WithUint(1024).WithUint(1024).WithUint(60000).Build())
-	wsc.SendMessage(NewPacketBuilder(id, 0x03).WithUint(1).Build())
-	wsc.SendMessage(NewPacketBuilder(id, 0x02).Build())
-	return output
+// sendMode describes the output to the client, scale and done were only added in version 2
+func (u *Output) sendMode(wsc *WaylandServerConn) {
+	bounds := u.head.Bounds()
+	SendWlOutputGeometry(wsc, u.id, int32(bounds.Min.X), int32(bounds.Min.Y), int32(bounds.Dx()), int32(bounds.Dy()), WlOutputSubpixelUnknown, "nyctal", "none", WlOutputTransformNormal)
+	SendWlOutputMode(wsc, u.id, WlOutputModeCurrent|WlOutputModePreferred, int32(bounds.Dx()), int32(bounds.Dy()), 60000)
+	if u.version >= 2 {
+		SendWlOutputScale(wsc, u.id, 1)
+		SendWlOutputDone(wsc, u.id)
+	}
 }
 
 func (u *Output) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-		// destroy
-		return nil
-	case 1:
+	case WlOutputRequestRelease:
+		wsc.registry.Destroy(u.id)
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on region: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlOutputInterface)
 	}
 
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+
+	"nyctal/utils"
 )
 
 type WaylandMessage struct {
@@ -71,6 +73,11 @@ func (uf IntField) AppendToBuf(buf []byte) []byte {
 
 type FixedField float32
 
+func NewFixedField() *FixedField {
+	ff := FixedField(0)
+	return &ff
+}
+
 func (ff FixedField) AppendToBuf(buf []byte) []byte {
 
 	u_d := float64(ff) + (3 << (51 - 8))
@@ -93,63 +100,249 @@ func (sf StringField) AppendToBuf(buf []byte) []byte {
 	buf = append(buf, string(sf)...)
 	buf = append(buf, []byte{0}...)
 
-	mod := (len(sf) + 1) % 4
-	padding := 0
-	if mod != 0 {
-		off := 4 - mod
-		padding += off
-	}
-	for i := 0; i < padding; i++ {
+	for i := 0; i < padding(len(sf)+1); i++ {
 		buf = append(buf, []byte{0}...)
 	}
 	return buf
 }
 
+type NullableStringField struct {
+	Value string
+	Valid bool
+}
+
+func NewNullableStringField() *NullableStringField {
+	return &NullableStringField{}
+}
+
+// Ptr returns the value of the string argument, or nil if a null string was sent
+func (nsf NullableStringField) Ptr() *string {
+	if !nsf.Valid {
+		return nil
+	}
+	return &nsf.Value
+}
+
+func (nsf NullableStringField) AppendToBuf(buf []byte) []byte {
+	if !nsf.Valid {
+		return binary.LittleEndian.AppendUint32(buf, 0)
+	}
+	return StringField(nsf.Value).AppendToBuf(buf)
+}
+
+// ObjectField is an object (or new_id) argument that must not be null
+type ObjectField uint32
+
+func NewObjectField() *ObjectField {
+	of := ObjectField(0)
+	return &of
+}
+
+func (of ObjectField) AppendToBuf(buf []byte) []byte {
+	return binary.LittleEndian.AppendUint32(buf, uint32(of))
+}
+
+// NullableObjectField is an object argument where 0 represents null
+type NullableObjectField uint32
+
+func NewNullableObjectField() *NullableObjectField {
+	of := NullableObjectField(0)
+	return &of
+}
+
+func (of NullableObjectField) AppendToBuf(buf []byte) []byte {
+	return binary.LittleEndian.AppendUint32(buf, uint32(of))
+}
+
+// NewIdField is a new_id argument without a fixed interface, which is sent
+// as the interface name and version followed by the new object id (e.g. wl_registry.bind)
+type NewIdField struct {
+	Interface string
+	Version   uint32
+	Id        uint32
+}
+
+func NewNewIdField() *NewIdField {
+	return &NewIdField{}
+}
+
+func (nf NewIdField) AppendToBuf(buf []byte) []byte {
+	buf = StringField(nf.Interface).AppendToBuf(buf)
+	buf = binary.LittleEndian.AppendUint32(buf, nf.Version)
+	return binary.LittleEndian.AppendUint32(buf, nf.Id)
+}
+
+// ArrayField is a length prefixed blob of bytes, padded to a 32-bit boundary
+type ArrayField []byte
+
+func NewArrayField() *ArrayField {
+	af := ArrayField(nil)
+	return &af
+}
+
+func (af ArrayField) AppendToBuf(buf []byte) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(af)))
+	buf = append(buf, af...)
+	for i := 0; i < padding(len(af)); i++ {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// FdField is a file descriptor argument. File descriptors are not part of the message
+// body, they are passed out-of-band (as SCM_RIGHTS) and are taken from the connection
+// in argument order.
+type FdField int
+
+func NewFdField() *FdField {
+	ff := FdField(-1)
+	return &ff
+}
+
+func (ff FdField) AppendToBuf(buf []byte) []byte {
+	return buf
+}
+
+// padding returns the number of bytes needed to align length to a 32-bit boundary
+func padding(length int) int {
+	if mod := length % 4; mod != 0 {
+		return 4 - mod
+	}
+	return 0
+}
+
+// readBlob reads a length prefixed (and padded) blob from the start of buf, returning
+// the contents and the remaining buffer.
+func readBlob(buf []byte) ([]byte, []byte, error) {
+	if len(buf) < 4 {
+		return nil, nil, fmt.Errorf("expected 4 byte length, only %d bytes remaining", len(buf))
+	}
+	length := binary.LittleEndian.Uint32(buf[0:4])
+	buf = buf[4:]
+	if uint64(length) > uint64(len(buf)) {
+		return nil, nil, fmt.Errorf("length %d exceeds the %d bytes remaining", length, len(buf))
+	}
+	padded := int(length) + padding(int(length))
+	if padded > len(buf) {
+		return nil, nil, fmt.Errorf("padded length %d exceeds the %d bytes remaining", padded, len(buf))
+	}
+	return buf[:length], buf[padded:], nil
+}
+
+// readString reads a string, the returned bool is false if the string was null
+func readString(buf []byte) (string, bool, []byte, error) {
+	blob, rest, err := readBlob(buf)
+	if err != nil {
+		return "", false, nil, err
+	}
+	if len(blob) == 0 {
+		return "", false, rest, nil
+	}
+	if blob[len(blob)-1] != 0 {
+		return "", false, nil, fmt.Errorf("string of length %d is not NUL terminated", len(blob))
+	}
+	if bytes.IndexByte(blob, 0) != len(blob)-1 {
+		return "", false, nil, fmt.Errorf("string of length %d contains an embedded NUL", len(blob))
+	}
+	return string(blob[:len(blob)-1]), true, rest, nil
+}
+
+func readUint32(buf []byte) (uint32, []byte, error) {
+	if len(buf) < 4 {
+		return 0, nil, fmt.Errorf("expected 4 bytes, only %d bytes remaining", len(buf))
+	}
+	return binary.LittleEndian.Uint32(buf[0:4]), buf[4:], nil
+}
+
+// ParsePacketStructure decodes buf into fields, it cannot be used for messages
+// that carry file descriptors (see ParsePacketStructureWithFds)
 func ParsePacketStructure(buf []byte, fields ...Field) error {
-	for _, field := range fields {
+	return ParsePacketStructureWithFds(buf, nil, fields...)
+}
+
+// ParsePacketStructureWithFds decodes buf into fields, taking any FdFields from fds in argument order.
+// The whole of buf must be consumed by the fields. Fds are only taken from the queue once the rest of the
+// message has been successfully decoded.
+func ParsePacketStructureWithFds(buf []byte, fds *utils.Queue[int], fields ...Field) error {
+	var fdFields []*FdField
+	for i, field := range fields {
+		var err error
 		switch f := field.(type) {
 		case *UintField:
-			if len(buf) >= 4 {
-				uintfield := UintField(binary.LittleEndian.Uint32(buf[0:4]))
-				*f = uintfield
-				buf = buf[4:]
-			} else {
-				return fmt.Errorf("could not parse packet structure")
-			}
+			var v uint32
+			v, buf, err = readUint32(buf)
+			*f = UintField(v)
 		case *IntField:
-			if len(buf) >= 4 {
-				uintfield := IntField(binary.LittleEndian.Uint32(buf[0:4]))
-				*f = uintfield
-				buf = buf[4:]
-			} else {
-				return fmt.Errorf("could not parse packet structure")
+			var v uint32
+			v, buf, err = readUint32(buf)
+			*f = IntField(int32(v))
+		case *FixedField:
+			var v uint32
+			v, buf, err = readUint32(buf)
+			*f = FixedField(float32(int32(v)) / 256.0)
+		case *ObjectField:
+			var v uint32
+			v, buf, err = readUint32(buf)
+			if err == nil && v == 0 {
+				err = fmt.Errorf("null object id for non-nullable argument")
 			}
+			*f = ObjectField(v)
+		case *NullableObjectField:
+			var v uint32
+			v, buf, err = readUint32(buf)
+			*f = NullableObjectField(v)
 		case *StringField:
-			if len(buf) >= 4 {
-				strlen := binary.LittleEndian.Uint32(buf[0:4])
-				idp := strlen
-				mod := idp % 4
-				ide := idp
-				if mod != 0 {
-					off := 4 - mod
-					ide += off
-				}
-				if len(buf) >= int(4+ide) {
-					nameBytes := bytes.Trim(buf[4:4+strlen], "\x00")
-					strfield := StringField(string(nameBytes))
-					*f = strfield
-				}
-				ptr := 4 + ide
-				if len(buf) >= int(ptr) {
-					buf = buf[ptr:]
-				} else {
-					return fmt.Errorf("could not parse packet structure")
-				}
-			} else {
-				return fmt.Errorf("could not parse packet structure")
+			var v string
+			var valid bool
+			v, valid, buf, err = readString(buf)
+			if err == nil && !valid {
+				err = fmt.Errorf("null string for non-nullable argument")
+			}
+			*f = StringField(v)
+		case *NullableStringField:
+			f.Value, f.Valid, buf, err = readString(buf)
+		case *NewIdField:
+			var valid bool
+			f.Interface, valid, buf, err = readString(buf)
+			if err == nil && !valid {
+				err = fmt.Errorf("null interface name for new_id argument")
+			}
+			if err == nil {
+				f.Version, buf, err = readUint32(buf)
 			}
+			if err == nil {
+				f.Id, buf, err = readUint32(buf)
+			}
+			if err == nil && f.Id == 0 {
+				err = fmt.Errorf("null object id for new_id argument")
+			}
+		case *ArrayField:
+			var v []byte
+			v, buf, err = readBlob(buf)
+			if err == nil {
+				*f = ArrayField(bytes.Clone(v))
+			}
+		case *FdField:
+			fdFields = append(fdFields, f)
 		default:
-			return fmt.Errorf("unimplemented field type: %v", field)
+			err = fmt.Errorf("unimplemented field type %T", field)
+		}
+		if err != nil {
+			return fmt.Errorf("could not parse argument %d (%T): %v", i, field, err)
+		}
+	}
+
+	if len(buf) != 0 {
+		return fmt.Errorf("could not parse packet structure: %d unexpected trailing bytes", len(buf))
+	}
+
+	if len(fdFields) > 0 {
+		if fds == nil || len(fds.Inner()) < len(fdFields) {
+			return fmt.Errorf("could not parse packet structure: expected %d fds but the connection has not received them", len(fdFields))
+		}
+		for _, f := range fdFields {
+			fd, _ := fds.Pop()
+			*f = FdField(fd)
 		}
 	}
 	return nil
@@ -160,6 +353,7 @@ type PacketBulder struct {
 	opcode uint16
 	id     uint32
 	fields []Field
+	fds    []int
 }
 
 func NewPacketBuilder(id uint32, opcode uint16) *PacketBulder {
@@ -171,6 +365,39 @@ func (pb *PacketBulder) WithString(str string) *PacketBulder {
 	return pb
 }
 
+// WithNullableString appends a string argument that may be null (nil)
+func (pb *PacketBulder) WithNullableString(str *string) *PacketBulder {
+	if str == nil {
+		pb.fields = append(pb.fields, NullableStringField{})
+	} else {
+		pb.fields = append(pb.fields, NullableStringField{Value: *str, Valid: true})
+	}
+	return pb
+}
+
+func (pb *PacketBulder) WithInt(i int32) *PacketBulder {
+	pb.fields = append(pb.fields, IntField(i))
+	return pb
+}
+
+// WithNewId appends a new_id argument that does not have a fixed interface
+func (pb *PacketBulder) WithNewId(iface string, version uint32, id uint32) *PacketBulder {
+	pb.fields = append(pb.fields, NewIdField{Interface: iface, Version: version, Id: id})
+	return pb
+}
+
+// WithFd attaches a file descriptor to the packet, fds are not part of the packet body
+// and must be sent alongside it (see Fds)
+func (pb *PacketBulder) WithFd(fd int) *PacketBulder {
+	pb.fds = append(pb.fds, fd)
+	return pb
+}
+
+// Fds returns the file descriptors attached to the packet, in argument order
+func (pb *PacketBulder) Fds() []int {
+	return pb.fds
+}
+
 func (pb *PacketBulder) WithUint(u uint32) *PacketBulder {
 	pb.fields = append(pb.fields, UintField(u))
 	return pb
@@ -186,6 +413,12 @@ func (pb *PacketBulder) WithBytes(b []byte) *PacketBulder {
 	return pb
 }
 
+// WithArray appends a wayland array argument, the contents are padded to a 32-bit boundary
+func (pb *PacketBulder) WithArray(arr []byte) *PacketBulder {
+	pb.fields = append(pb.fields, ArrayField(arr))
+	return pb
+}
+
 func (pb *PacketBulder) WithU16Array(arr []uint16) *PacketBulder {
 	pb.fields = append(pb.fields, UintField(uint32(len(arr)*2)))
 	for _, v := range arr {
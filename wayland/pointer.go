@@ -18,29 +18,31 @@ type Pointer struct {
 func (u *Pointer) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlPointerRequestSetCursor:
 		// set_cursor
-		serial := NewUintField()
-		surface := NewUintField()
-		x := NewIntField()
-		y := NewIntField()
 		//The parameters hotspot_x and hotspot_y define the position of
 		//the pointer surface relative to the pointer location. Its
 		//top-left corner is always at (x, y) - (hotspot_x, hotspot_y),
 		//where (x, y) are the coordinates of the pointer location, in
 		//surface-local coordinates.
-		if err := ParsePacketStructure(packet.Data, serial, surface, x, y); err != nil {
+		req, err := ParseWlPointerSetCursorRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("pointer#%d", u.id), fmt.Sprintf("set_cursor %d %d %d %d", *serial, *surface, *x, *y))
-		u.surface = uint32(*surface)
-		u.hotspot = image.Pt(int(*x), int(*y))
+		utils.Debug(int(wsc.id), fmt.Sprintf("pointer#%d", u.id), fmt.Sprintf("set_cursor %d %d %d %d", req.Serial, req.Surface, req.HotspotX, req.HotspotY))
+		// the cursor can only be set by the client the pointer entered, in response to the enter
+		if !wsc.validSerial(req.Serial, SerialPointerEnter) {
+			utils.Debug(int(wsc.id), fmt.Sprintf("pointer#%d", u.id), fmt.Sprintf("set_cursor ignored, invalid serial %d", req.Serial))
+			return nil
+		}
+		u.surface = req.Surface
+		u.hotspot = image.Pt(int(req.HotspotX), int(req.HotspotY))
 		return nil
 
-	case 1:
+	case WlPointerRequestRelease:
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on pointer: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlPointerInterface)
 	}
 
 }
@@ -0,0 +1,2553 @@
+// Code generated by wayland/scanner from wayland.xml. DO NOT EDIT.
+
+package wayland
+
+// wl_display: core global object
+const (
+	WlDisplayInterface = "wl_display"
+	WlDisplayVersion   = 1
+)
+
+// wl_display request opcodes
+const (
+	WlDisplayRequestSync        uint16 = 0 // asynchronous roundtrip
+	WlDisplayRequestGetRegistry uint16 = 1 // get global registry object
+)
+
+// wl_display event opcodes
+const (
+	WlDisplayEventError    uint16 = 0 // fatal error event
+	WlDisplayEventDeleteId uint16 = 1 // acknowledge object ID deletion
+)
+
+// WlDisplayError: global error values
+type WlDisplayError uint32
+
+const (
+	WlDisplayErrorInvalidObject  WlDisplayError = 0 // server couldn't find object
+	WlDisplayErrorInvalidMethod  WlDisplayError = 1 // method doesn't exist on the specified interface or malformed request
+	WlDisplayErrorNoMemory       WlDisplayError = 2 // server is out of memory
+	WlDisplayErrorImplementation WlDisplayError = 3 // implementation error in compositor
+)
+
+// WlDisplaySyncRequest holds the arguments of a wl_display.sync request
+type WlDisplaySyncRequest struct {
+	Callback uint32
+}
+
+// ParseWlDisplaySyncRequest decodes a wl_display.sync request
+func ParseWlDisplaySyncRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDisplaySyncRequest, error) {
+	callback := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, callback); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_display.sync#%d: %v", packet.Address, err)
+	}
+	return &WlDisplaySyncRequest{
+		Callback: uint32(*callback),
+	}, nil
+}
+
+// WlDisplayGetRegistryRequest holds the arguments of a wl_display.get_registry request
+type WlDisplayGetRegistryRequest struct {
+	Registry uint32
+}
+
+// ParseWlDisplayGetRegistryRequest decodes a wl_display.get_registry request
+func ParseWlDisplayGetRegistryRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDisplayGetRegistryRequest, error) {
+	registry := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, registry); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_display.get_registry#%d: %v", packet.Address, err)
+	}
+	return &WlDisplayGetRegistryRequest{
+		Registry: uint32(*registry),
+	}, nil
+}
+
+// SendWlDisplayError sends a wl_display.error event: fatal error event
+func SendWlDisplayError(wsc *WaylandServerConn, id uint32, objectId uint32, code uint32, message string) {
+	pb := NewPacketBuilder(id, WlDisplayEventError)
+	pb.WithUint(objectId)
+	pb.WithUint(code)
+	pb.WithString(message)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDisplayDeleteId sends a wl_display.delete_id event: acknowledge object ID deletion
+func SendWlDisplayDeleteId(wsc *WaylandServerConn, id uint32, idArg uint32) {
+	pb := NewPacketBuilder(id, WlDisplayEventDeleteId)
+	pb.WithUint(idArg)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_registry: global registry object
+const (
+	WlRegistryInterface = "wl_registry"
+	WlRegistryVersion   = 1
+)
+
+// wl_registry request opcodes
+const (
+	WlRegistryRequestBind uint16 = 0 // bind an object to the display
+)
+
+// wl_registry event opcodes
+const (
+	WlRegistryEventGlobal       uint16 = 0 // announce global object
+	WlRegistryEventGlobalRemove uint16 = 1 // announce removal of global object
+)
+
+// WlRegistryBindRequest holds the arguments of a wl_registry.bind request
+type WlRegistryBindRequest struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+	Id        uint32
+}
+
+// ParseWlRegistryBindRequest decodes a wl_registry.bind request
+func ParseWlRegistryBindRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlRegistryBindRequest, error) {
+	name := NewUintField()
+	idArg := NewNewIdField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, name, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_registry.bind#%d: %v", packet.Address, err)
+	}
+	return &WlRegistryBindRequest{
+		Name:      uint32(*name),
+		Interface: idArg.Interface,
+		Version:   idArg.Version,
+		Id:        idArg.Id,
+	}, nil
+}
+
+// SendWlRegistryGlobal sends a wl_registry.global event: announce global object
+func SendWlRegistryGlobal(wsc *WaylandServerConn, id uint32, name uint32, interfaceArg string, version uint32) {
+	pb := NewPacketBuilder(id, WlRegistryEventGlobal)
+	pb.WithUint(name)
+	pb.WithString(interfaceArg)
+	pb.WithUint(version)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlRegistryGlobalRemove sends a wl_registry.global_remove event: announce removal of global object
+func SendWlRegistryGlobalRemove(wsc *WaylandServerConn, id uint32, name uint32) {
+	pb := NewPacketBuilder(id, WlRegistryEventGlobalRemove)
+	pb.WithUint(name)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_callback: callback object
+const (
+	WlCallbackInterface = "wl_callback"
+	WlCallbackVersion   = 1
+)
+
+// wl_callback event opcodes
+const (
+	WlCallbackEventDone uint16 = 0 // done event
+)
+
+// SendWlCallbackDone sends a wl_callback.done event: done event
+func SendWlCallbackDone(wsc *WaylandServerConn, id uint32, callbackData uint32) {
+	pb := NewPacketBuilder(id, WlCallbackEventDone)
+	pb.WithUint(callbackData)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_compositor: the compositor singleton
+const (
+	WlCompositorInterface = "wl_compositor"
+	WlCompositorVersion   = 6
+)
+
+// wl_compositor request opcodes
+const (
+	WlCompositorRequestCreateSurface uint16 = 0 // create new surface
+	WlCompositorRequestCreateRegion  uint16 = 1 // create new region
+)
+
+// WlCompositorCreateSurfaceRequest holds the arguments of a wl_compositor.create_surface request
+type WlCompositorCreateSurfaceRequest struct {
+	Id uint32
+}
+
+// ParseWlCompositorCreateSurfaceRequest decodes a wl_compositor.create_surface request
+func ParseWlCompositorCreateSurfaceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlCompositorCreateSurfaceRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_compositor.create_surface#%d: %v", packet.Address, err)
+	}
+	return &WlCompositorCreateSurfaceRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// WlCompositorCreateRegionRequest holds the arguments of a wl_compositor.create_region request
+type WlCompositorCreateRegionRequest struct {
+	Id uint32
+}
+
+// ParseWlCompositorCreateRegionRequest decodes a wl_compositor.create_region request
+func ParseWlCompositorCreateRegionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlCompositorCreateRegionRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_compositor.create_region#%d: %v", packet.Address, err)
+	}
+	return &WlCompositorCreateRegionRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// wl_shm_pool: a shared memory pool
+const (
+	WlShmPoolInterface = "wl_shm_pool"
+	WlShmPoolVersion   = 2
+)
+
+// wl_shm_pool request opcodes
+const (
+	WlShmPoolRequestCreateBuffer uint16 = 0 // create a buffer from the pool
+	WlShmPoolRequestDestroy      uint16 = 1 // destroy the pool
+	WlShmPoolRequestResize       uint16 = 2 // change the size of the pool mapping
+)
+
+// WlShmPoolCreateBufferRequest holds the arguments of a wl_shm_pool.create_buffer request
+type WlShmPoolCreateBufferRequest struct {
+	Id     uint32
+	Offset int32
+	Width  int32
+	Height int32
+	Stride int32
+	Format WlShmFormat
+}
+
+// ParseWlShmPoolCreateBufferRequest decodes a wl_shm_pool.create_buffer request
+func ParseWlShmPoolCreateBufferRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShmPoolCreateBufferRequest, error) {
+	idArg := NewObjectField()
+	offset := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	stride := NewIntField()
+	format := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, offset, width, height, stride, format); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shm_pool.create_buffer#%d: %v", packet.Address, err)
+	}
+	return &WlShmPoolCreateBufferRequest{
+		Id:     uint32(*idArg),
+		Offset: int32(*offset),
+		Width:  int32(*width),
+		Height: int32(*height),
+		Stride: int32(*stride),
+		Format: WlShmFormat(*format),
+	}, nil
+}
+
+// WlShmPoolResizeRequest holds the arguments of a wl_shm_pool.resize request
+type WlShmPoolResizeRequest struct {
+	Size int32
+}
+
+// ParseWlShmPoolResizeRequest decodes a wl_shm_pool.resize request
+func ParseWlShmPoolResizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShmPoolResizeRequest, error) {
+	size := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, size); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shm_pool.resize#%d: %v", packet.Address, err)
+	}
+	return &WlShmPoolResizeRequest{
+		Size: int32(*size),
+	}, nil
+}
+
+// wl_shm: shared memory support
+const (
+	WlShmInterface = "wl_shm"
+	WlShmVersion   = 2
+)
+
+// wl_shm request opcodes
+const (
+	WlShmRequestCreatePool uint16 = 0 // create a shm pool
+	WlShmRequestRelease    uint16 = 1 // release the shm object
+)
+
+// wl_shm request since versions
+const (
+	WlShmRequestReleaseSince = 2
+)
+
+// wl_shm event opcodes
+const (
+	WlShmEventFormat uint16 = 0 // pixel format description
+)
+
+// WlShmError: wl_shm error values
+type WlShmError uint32
+
+const (
+	WlShmErrorInvalidFormat WlShmError = 0 // buffer format is not known
+	WlShmErrorInvalidStride WlShmError = 1 // invalid size or stride during pool or buffer creation
+	WlShmErrorInvalidFd     WlShmError = 2 // mmapping the file descriptor failed
+)
+
+// WlShmFormat: pixel formats
+type WlShmFormat uint32
+
+const (
+	WlShmFormatArgb8888             WlShmFormat = 0          // 32-bit ARGB format, [31:0] A:R:G:B 8:8:8:8 little endian
+	WlShmFormatXrgb8888             WlShmFormat = 1          // 32-bit RGB format, [31:0] x:R:G:B 8:8:8:8 little endian
+	WlShmFormatC8                   WlShmFormat = 0x20203843 // 8-bit color index format, [7:0] C
+	WlShmFormatRgb332               WlShmFormat = 0x38424752 // 8-bit RGB format, [7:0] R:G:B 3:3:2
+	WlShmFormatBgr233               WlShmFormat = 0x38524742 // 8-bit BGR format, [7:0] B:G:R 2:3:3
+	WlShmFormatXrgb4444             WlShmFormat = 0x32315258 // 16-bit xRGB format, [15:0] x:R:G:B 4:4:4:4 little endian
+	WlShmFormatXbgr4444             WlShmFormat = 0x32314258 // 16-bit xBGR format, [15:0] x:B:G:R 4:4:4:4 little endian
+	WlShmFormatRgbx4444             WlShmFormat = 0x32315852 // 16-bit RGBx format, [15:0] R:G:B:x 4:4:4:4 little endian
+	WlShmFormatBgrx4444             WlShmFormat = 0x32315842 // 16-bit BGRx format, [15:0] B:G:R:x 4:4:4:4 little endian
+	WlShmFormatArgb4444             WlShmFormat = 0x32315241 // 16-bit ARGB format, [15:0] A:R:G:B 4:4:4:4 little endian
+	WlShmFormatAbgr4444             WlShmFormat = 0x32314241 // 16-bit ABGR format, [15:0] A:B:G:R 4:4:4:4 little endian
+	WlShmFormatRgba4444             WlShmFormat = 0x32314152 // 16-bit RBGA format, [15:0] R:G:B:A 4:4:4:4 little endian
+	WlShmFormatBgra4444             WlShmFormat = 0x32314142 // 16-bit BGRA format, [15:0] B:G:R:A 4:4:4:4 little endian
+	WlShmFormatXrgb1555             WlShmFormat = 0x35315258 // 16-bit xRGB format, [15:0] x:R:G:B 1:5:5:5 little endian
+	WlShmFormatXbgr1555             WlShmFormat = 0x35314258 // 16-bit xBGR 1555 format, [15:0] x:B:G:R 1:5:5:5 little endian
+	WlShmFormatRgbx5551             WlShmFormat = 0x35315852 // 16-bit RGBx 5551 format, [15:0] R:G:B:x 5:5:5:1 little endian
+	WlShmFormatBgrx5551             WlShmFormat = 0x35315842 // 16-bit BGRx 5551 format, [15:0] B:G:R:x 5:5:5:1 little endian
+	WlShmFormatArgb1555             WlShmFormat = 0x35315241 // 16-bit ARGB 1555 format, [15:0] A:R:G:B 1:5:5:5 little endian
+	WlShmFormatAbgr1555             WlShmFormat = 0x35314241 // 16-bit ABGR 1555 format, [15:0] A:B:G:R 1:5:5:5 little endian
+	WlShmFormatRgba5551             WlShmFormat = 0x35314152 // 16-bit RGBA 5551 format, [15:0] R:G:B:A 5:5:5:1 little endian
+	WlShmFormatBgra5551             WlShmFormat = 0x35314142 // 16-bit BGRA 5551 format, [15:0] B:G:R:A 5:5:5:1 little endian
+	WlShmFormatRgb565               WlShmFormat = 0x36314752 // 16-bit RGB 565 format, [15:0] R:G:B 5:6:5 little endian
+	WlShmFormatBgr565               WlShmFormat = 0x36314742 // 16-bit BGR 565 format, [15:0] B:G:R 5:6:5 little endian
+	WlShmFormatRgb888               WlShmFormat = 0x34324752 // 24-bit RGB format, [23:0] R:G:B little endian
+	WlShmFormatBgr888               WlShmFormat = 0x34324742 // 24-bit BGR format, [23:0] B:G:R little endian
+	WlShmFormatXbgr8888             WlShmFormat = 0x34324258 // 32-bit xBGR format, [31:0] x:B:G:R 8:8:8:8 little endian
+	WlShmFormatRgbx8888             WlShmFormat = 0x34325852 // 32-bit RGBx format, [31:0] R:G:B:x 8:8:8:8 little endian
+	WlShmFormatBgrx8888             WlShmFormat = 0x34325842 // 32-bit BGRx format, [31:0] B:G:R:x 8:8:8:8 little endian
+	WlShmFormatAbgr8888             WlShmFormat = 0x34324241 // 32-bit ABGR format, [31:0] A:B:G:R 8:8:8:8 little endian
+	WlShmFormatRgba8888             WlShmFormat = 0x34324152 // 32-bit RGBA format, [31:0] R:G:B:A 8:8:8:8 little endian
+	WlShmFormatBgra8888             WlShmFormat = 0x34324142 // 32-bit BGRA format, [31:0] B:G:R:A 8:8:8:8 little endian
+	WlShmFormatXrgb2101010          WlShmFormat = 0x30335258 // 32-bit xRGB format, [31:0] x:R:G:B 2:10:10:10 little endian
+	WlShmFormatXbgr2101010          WlShmFormat = 0x30334258 // 32-bit xBGR format, [31:0] x:B:G:R 2:10:10:10 little endian
+	WlShmFormatRgbx1010102          WlShmFormat = 0x30335852 // 32-bit RGBx format, [31:0] R:G:B:x 10:10:10:2 little endian
+	WlShmFormatBgrx1010102          WlShmFormat = 0x30335842 // 32-bit BGRx format, [31:0] B:G:R:x 10:10:10:2 little endian
+	WlShmFormatArgb2101010          WlShmFormat = 0x30335241 // 32-bit ARGB format, [31:0] A:R:G:B 2:10:10:10 little endian
+	WlShmFormatAbgr2101010          WlShmFormat = 0x30334241 // 32-bit ABGR format, [31:0] A:B:G:R 2:10:10:10 little endian
+	WlShmFormatRgba1010102          WlShmFormat = 0x30334152 // 32-bit RGBA format, [31:0] R:G:B:A 10:10:10:2 little endian
+	WlShmFormatBgra1010102          WlShmFormat = 0x30334142 // 32-bit BGRA format, [31:0] B:G:R:A 10:10:10:2 little endian
+	WlShmFormatYuyv                 WlShmFormat = 0x56595559 // packed YCbCr format, [31:0] Cr0:Y1:Cb0:Y0 8:8:8:8 little endian
+	WlShmFormatYvyu                 WlShmFormat = 0x55595659 // packed YCbCr format, [31:0] Cb0:Y1:Cr0:Y0 8:8:8:8 little endian
+	WlShmFormatUyvy                 WlShmFormat = 0x59565955 // packed YCbCr format, [31:0] Y1:Cr0:Y0:Cb0 8:8:8:8 little endian
+	WlShmFormatVyuy                 WlShmFormat = 0x59555956 // packed YCbCr format, [31:0] Y1:Cb0:Y0:Cr0 8:8:8:8 little endian
+	WlShmFormatAyuv                 WlShmFormat = 0x56555941 // packed AYCbCr format, [31:0] A:Y:Cb:Cr 8:8:8:8 little endian
+	WlShmFormatNv12                 WlShmFormat = 0x3231564e // 2 plane YCbCr Cr:Cb format, 2x2 subsampled Cr:Cb plane
+	WlShmFormatNv21                 WlShmFormat = 0x3132564e // 2 plane YCbCr Cb:Cr format, 2x2 subsampled Cb:Cr plane
+	WlShmFormatNv16                 WlShmFormat = 0x3631564e // 2 plane YCbCr Cr:Cb format, 2x1 subsampled Cr:Cb plane
+	WlShmFormatNv61                 WlShmFormat = 0x3136564e // 2 plane YCbCr Cb:Cr format, 2x1 subsampled Cb:Cr plane
+	WlShmFormatYuv410               WlShmFormat = 0x39565559 // 3 plane YCbCr format, 4x4 subsampled Cb (1) and Cr (2) planes
+	WlShmFormatYvu410               WlShmFormat = 0x39555659 // 3 plane YCbCr format, 4x4 subsampled Cr (1) and Cb (2) planes
+	WlShmFormatYuv411               WlShmFormat = 0x31315559 // 3 plane YCbCr format, 4x1 subsampled Cb (1) and Cr (2) planes
+	WlShmFormatYvu411               WlShmFormat = 0x31315659 // 3 plane YCbCr format, 4x1 subsampled Cr (1) and Cb (2) planes
+	WlShmFormatYuv420               WlShmFormat = 0x32315559 // 3 plane YCbCr format, 2x2 subsampled Cb (1) and Cr (2) planes
+	WlShmFormatYvu420               WlShmFormat = 0x32315659 // 3 plane YCbCr format, 2x2 subsampled Cr (1) and Cb (2) planes
+	WlShmFormatYuv422               WlShmFormat = 0x36315559 // 3 plane YCbCr format, 2x1 subsampled Cb (1) and Cr (2) planes
+	WlShmFormatYvu422               WlShmFormat = 0x36315659 // 3 plane YCbCr format, 2x1 subsampled Cr (1) and Cb (2) planes
+	WlShmFormatYuv444               WlShmFormat = 0x34325559 // 3 plane YCbCr format, non-subsampled Cb (1) and Cr (2) planes
+	WlShmFormatYvu444               WlShmFormat = 0x34325659 // 3 plane YCbCr format, non-subsampled Cr (1) and Cb (2) planes
+	WlShmFormatR8                   WlShmFormat = 0x20203852 // [7:0] R
+	WlShmFormatR16                  WlShmFormat = 0x20363152 // [15:0] R little endian
+	WlShmFormatRg88                 WlShmFormat = 0x38384752 // [15:0] R:G 8:8 little endian
+	WlShmFormatGr88                 WlShmFormat = 0x38385247 // [15:0] G:R 8:8 little endian
+	WlShmFormatRg1616               WlShmFormat = 0x32334752 // [31:0] R:G 16:16 little endian
+	WlShmFormatGr1616               WlShmFormat = 0x32335247 // [31:0] G:R 16:16 little endian
+	WlShmFormatXrgb16161616f        WlShmFormat = 0x48345258 // [63:0] x:R:G:B 16:16:16:16 little endian
+	WlShmFormatXbgr16161616f        WlShmFormat = 0x48344258 // [63:0] x:B:G:R 16:16:16:16 little endian
+	WlShmFormatArgb16161616f        WlShmFormat = 0x48345241 // [63:0] A:R:G:B 16:16:16:16 little endian
+	WlShmFormatAbgr16161616f        WlShmFormat = 0x48344241 // [63:0] A:B:G:R 16:16:16:16 little endian
+	WlShmFormatXyuv8888             WlShmFormat = 0x56555958 // [31:0] X:Y:Cb:Cr 8:8:8:8 little endian
+	WlShmFormatVuy888               WlShmFormat = 0x34325556 // [23:0] Cr:Cb:Y 8:8:8 little endian
+	WlShmFormatVuy101010            WlShmFormat = 0x30335556 // Y followed by U then V, 10:10:10. Non-linear modifier only
+	WlShmFormatY210                 WlShmFormat = 0x30313259 // [63:0] Cr0:0:Y1:0:Cb0:0:Y0:0 10:6:10:6:10:6:10:6 little endian per 2 Y pixels
+	WlShmFormatY212                 WlShmFormat = 0x32313259 // [63:0] Cr0:0:Y1:0:Cb0:0:Y0:0 12:4:12:4:12:4:12:4 little endian per 2 Y pixels
+	WlShmFormatY216                 WlShmFormat = 0x36313259 // [63:0] Cr0:Y1:Cb0:Y0 16:16:16:16 little endian per 2 Y pixels
+	WlShmFormatY410                 WlShmFormat = 0x30313459 // [31:0] A:Cr:Y:Cb 2:10:10:10 little endian
+	WlShmFormatY412                 WlShmFormat = 0x32313459 // [63:0] A:0:Cr:0:Y:0:Cb:0 12:4:12:4:12:4:12:4 little endian
+	WlShmFormatY416                 WlShmFormat = 0x36313459 // [63:0] A:Cr:Y:Cb 16:16:16:16 little endian
+	WlShmFormatXvyu2101010          WlShmFormat = 0x30335658 // [31:0] X:Cr:Y:Cb 2:10:10:10 little endian
+	WlShmFormatXvyu1216161616       WlShmFormat = 0x36335658 // [63:0] X:0:Cr:0:Y:0:Cb:0 12:4:12:4:12:4:12:4 little endian
+	WlShmFormatXvyu16161616         WlShmFormat = 0x38345658 // [63:0] X:Cr:Y:Cb 16:16:16:16 little endian
+	WlShmFormatY0l0                 WlShmFormat = 0x304c3059 // [63:0] A3:A2:Y3:0:Cr0:0:Y2:0:A1:A0:Y1:0:Cb0:0:Y0:0 1:1:8:2:8:2:8:2:1:1:8:2:8:2:8:2 little endian
+	WlShmFormatX0l0                 WlShmFormat = 0x304c3058 // [63:0] X3:X2:Y3:0:Cr0:0:Y2:0:X1:X0:Y1:0:Cb0:0:Y0:0 1:1:8:2:8:2:8:2:1:1:8:2:8:2:8:2 little endian
+	WlShmFormatY0l2                 WlShmFormat = 0x324c3059 // [63:0] A3:A2:Y3:Cr0:Y2:A1:A0:Y1:Cb0:Y0 1:1:10:10:10:1:1:10:10:10 little endian
+	WlShmFormatX0l2                 WlShmFormat = 0x324c3058 // [63:0] X3:X2:Y3:Cr0:Y2:X1:X0:Y1:Cb0:Y0 1:1:10:10:10:1:1:10:10:10 little endian
+	WlShmFormatYuv4208bit           WlShmFormat = 0x38305559
+	WlShmFormatYuv42010bit          WlShmFormat = 0x30315559
+	WlShmFormatXrgb8888A8           WlShmFormat = 0x38415258
+	WlShmFormatXbgr8888A8           WlShmFormat = 0x38414258
+	WlShmFormatRgbx8888A8           WlShmFormat = 0x38415852
+	WlShmFormatBgrx8888A8           WlShmFormat = 0x38415842
+	WlShmFormatRgb888A8             WlShmFormat = 0x38413852
+	WlShmFormatBgr888A8             WlShmFormat = 0x38413842
+	WlShmFormatRgb565A8             WlShmFormat = 0x38413552
+	WlShmFormatBgr565A8             WlShmFormat = 0x38413542
+	WlShmFormatNv24                 WlShmFormat = 0x3432564e // non-subsampled Cr:Cb plane
+	WlShmFormatNv42                 WlShmFormat = 0x3234564e // non-subsampled Cb:Cr plane
+	WlShmFormatP210                 WlShmFormat = 0x30313250 // 2x1 subsampled Cr:Cb plane, 10 bit per channel
+	WlShmFormatP010                 WlShmFormat = 0x30313050 // 2x2 subsampled Cr:Cb plane 10 bits per channel
+	WlShmFormatP012                 WlShmFormat = 0x32313050 // 2x2 subsampled Cr:Cb plane 12 bits per channel
+	WlShmFormatP016                 WlShmFormat = 0x36313050 // 2x2 subsampled Cr:Cb plane 16 bits per channel
+	WlShmFormatAxbxgxrx106106106106 WlShmFormat = 0x30314241 // [63:0] A:x:B:x:G:x:R:x 10:6:10:6:10:6:10:6 little endian
+	WlShmFormatNv15                 WlShmFormat = 0x3531564e // 2x2 subsampled Cr:Cb plane
+	WlShmFormatQ410                 WlShmFormat = 0x30313451
+	WlShmFormatQ401                 WlShmFormat = 0x31303451
+	WlShmFormatXrgb16161616         WlShmFormat = 0x38345258 // [63:0] x:R:G:B 16:16:16:16 little endian
+	WlShmFormatXbgr16161616         WlShmFormat = 0x38344258 // [63:0] x:B:G:R 16:16:16:16 little endian
+	WlShmFormatArgb16161616         WlShmFormat = 0x38345241 // [63:0] A:R:G:B 16:16:16:16 little endian
+	WlShmFormatAbgr16161616         WlShmFormat = 0x38344241 // [63:0] A:B:G:R 16:16:16:16 little endian
+	WlShmFormatC1                   WlShmFormat = 0x20203143 // [7:0] C0:C1:C2:C3:C4:C5:C6:C7 1:1:1:1:1:1:1:1 eight pixels/byte
+	WlShmFormatC2                   WlShmFormat = 0x20203243 // [7:0] C0:C1:C2:C3 2:2:2:2 four pixels/byte
+	WlShmFormatC4                   WlShmFormat = 0x20203443 // [7:0] C0:C1 4:4 two pixels/byte
+	WlShmFormatD1                   WlShmFormat = 0x20203144 // [7:0] D0:D1:D2:D3:D4:D5:D6:D7 1:1:1:1:1:1:1:1 eight pixels/byte
+	WlShmFormatD2                   WlShmFormat = 0x20203244 // [7:0] D0:D1:D2:D3 2:2:2:2 four pixels/byte
+	WlShmFormatD4                   WlShmFormat = 0x20203444 // [7:0] D0:D1 4:4 two pixels/byte
+	WlShmFormatD8                   WlShmFormat = 0x20203844 // [7:0] D
+	WlShmFormatR1                   WlShmFormat = 0x20203152 // [7:0] R0:R1:R2:R3:R4:R5:R6:R7 1:1:1:1:1:1:1:1 eight pixels/byte
+	WlShmFormatR2                   WlShmFormat = 0x20203252 // [7:0] R0:R1:R2:R3 2:2:2:2 four pixels/byte
+	WlShmFormatR4                   WlShmFormat = 0x20203452 // [7:0] R0:R1 4:4 two pixels/byte
+	WlShmFormatR10                  WlShmFormat = 0x20303152 // [15:0] x:R 6:10 little endian
+	WlShmFormatR12                  WlShmFormat = 0x20323152 // [15:0] x:R 4:12 little endian
+	WlShmFormatAvuy8888             WlShmFormat = 0x59555641 // [31:0] A:Cr:Cb:Y 8:8:8:8 little endian
+	WlShmFormatXvuy8888             WlShmFormat = 0x59555658 // [31:0] X:Cr:Cb:Y 8:8:8:8 little endian
+	WlShmFormatP030                 WlShmFormat = 0x30333050 // 2x2 subsampled Cr:Cb plane 10 bits per channel packed
+)
+
+// WlShmCreatePoolRequest holds the arguments of a wl_shm.create_pool request
+type WlShmCreatePoolRequest struct {
+	Id   uint32
+	Fd   int
+	Size int32
+}
+
+// ParseWlShmCreatePoolRequest decodes a wl_shm.create_pool request
+func ParseWlShmCreatePoolRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShmCreatePoolRequest, error) {
+	idArg := NewObjectField()
+	fd := NewFdField()
+	size := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, fd, size); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shm.create_pool#%d: %v", packet.Address, err)
+	}
+	return &WlShmCreatePoolRequest{
+		Id:   uint32(*idArg),
+		Fd:   int(*fd),
+		Size: int32(*size),
+	}, nil
+}
+
+// SendWlShmFormat sends a wl_shm.format event: pixel format description
+func SendWlShmFormat(wsc *WaylandServerConn, id uint32, format WlShmFormat) {
+	pb := NewPacketBuilder(id, WlShmEventFormat)
+	pb.WithUint(uint32(format))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_buffer: content for a wl_surface
+const (
+	WlBufferInterface = "wl_buffer"
+	WlBufferVersion   = 1
+)
+
+// wl_buffer request opcodes
+const (
+	WlBufferRequestDestroy uint16 = 0 // destroy a buffer
+)
+
+// wl_buffer event opcodes
+const (
+	WlBufferEventRelease uint16 = 0 // compositor releases buffer
+)
+
+// SendWlBufferRelease sends a wl_buffer.release event: compositor releases buffer
+func SendWlBufferRelease(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlBufferEventRelease)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_data_offer: offer to transfer data
+const (
+	WlDataOfferInterface = "wl_data_offer"
+	WlDataOfferVersion   = 3
+)
+
+// wl_data_offer request opcodes
+const (
+	WlDataOfferRequestAccept     uint16 = 0 // accept one of the offered mime types
+	WlDataOfferRequestReceive    uint16 = 1 // request that the data is transferred
+	WlDataOfferRequestDestroy    uint16 = 2 // destroy data offer
+	WlDataOfferRequestFinish     uint16 = 3 // the offer will no longer be used
+	WlDataOfferRequestSetActions uint16 = 4 // set the available/preferred drag-and-drop actions
+)
+
+// wl_data_offer request since versions
+const (
+	WlDataOfferRequestFinishSince     = 3
+	WlDataOfferRequestSetActionsSince = 3
+)
+
+// wl_data_offer event opcodes
+const (
+	WlDataOfferEventOffer         uint16 = 0 // advertise offered mime type
+	WlDataOfferEventSourceActions uint16 = 1 // notify the source-side available actions
+	WlDataOfferEventAction        uint16 = 2 // notify the selected action
+)
+
+// wl_data_offer event since versions
+const (
+	WlDataOfferEventSourceActionsSince = 3
+	WlDataOfferEventActionSince        = 3
+)
+
+// WlDataOfferError:
+type WlDataOfferError uint32
+
+const (
+	WlDataOfferErrorInvalidFinish     WlDataOfferError = 0 // finish request was called untimely
+	WlDataOfferErrorInvalidActionMask WlDataOfferError = 1 // action mask contains invalid values
+	WlDataOfferErrorInvalidAction     WlDataOfferError = 2 // action argument has an invalid value
+	WlDataOfferErrorInvalidOffer      WlDataOfferError = 3 // offer doesn't accept this request
+)
+
+// WlDataOfferAcceptRequest holds the arguments of a wl_data_offer.accept request
+type WlDataOfferAcceptRequest struct {
+	Serial   uint32
+	MimeType *string
+}
+
+// ParseWlDataOfferAcceptRequest decodes a wl_data_offer.accept request
+func ParseWlDataOfferAcceptRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataOfferAcceptRequest, error) {
+	serial := NewUintField()
+	mimeType := NewNullableStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial, mimeType); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_offer.accept#%d: %v", packet.Address, err)
+	}
+	return &WlDataOfferAcceptRequest{
+		Serial:   uint32(*serial),
+		MimeType: mimeType.Ptr(),
+	}, nil
+}
+
+// WlDataOfferReceiveRequest holds the arguments of a wl_data_offer.receive request
+type WlDataOfferReceiveRequest struct {
+	MimeType string
+	Fd       int
+}
+
+// ParseWlDataOfferReceiveRequest decodes a wl_data_offer.receive request
+func ParseWlDataOfferReceiveRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataOfferReceiveRequest, error) {
+	mimeType := NewStringField()
+	fd := NewFdField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, mimeType, fd); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_offer.receive#%d: %v", packet.Address, err)
+	}
+	return &WlDataOfferReceiveRequest{
+		MimeType: string(*mimeType),
+		Fd:       int(*fd),
+	}, nil
+}
+
+// WlDataOfferSetActionsRequest holds the arguments of a wl_data_offer.set_actions request
+type WlDataOfferSetActionsRequest struct {
+	DndActions      WlDataDeviceManagerDndAction
+	PreferredAction WlDataDeviceManagerDndAction
+}
+
+// ParseWlDataOfferSetActionsRequest decodes a wl_data_offer.set_actions request
+func ParseWlDataOfferSetActionsRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataOfferSetActionsRequest, error) {
+	dndActions := NewUintField()
+	preferredAction := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, dndActions, preferredAction); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_offer.set_actions#%d: %v", packet.Address, err)
+	}
+	return &WlDataOfferSetActionsRequest{
+		DndActions:      WlDataDeviceManagerDndAction(*dndActions),
+		PreferredAction: WlDataDeviceManagerDndAction(*preferredAction),
+	}, nil
+}
+
+// SendWlDataOfferOffer sends a wl_data_offer.offer event: advertise offered mime type
+func SendWlDataOfferOffer(wsc *WaylandServerConn, id uint32, mimeType string) {
+	pb := NewPacketBuilder(id, WlDataOfferEventOffer)
+	pb.WithString(mimeType)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataOfferSourceActions sends a wl_data_offer.source_actions event: notify the source-side available actions
+func SendWlDataOfferSourceActions(wsc *WaylandServerConn, id uint32, sourceActions WlDataDeviceManagerDndAction) {
+	if wsc.registry.Version(id) < WlDataOfferEventSourceActionsSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlDataOfferEventSourceActions)
+	pb.WithUint(uint32(sourceActions))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataOfferAction sends a wl_data_offer.action event: notify the selected action
+func SendWlDataOfferAction(wsc *WaylandServerConn, id uint32, dndAction WlDataDeviceManagerDndAction) {
+	if wsc.registry.Version(id) < WlDataOfferEventActionSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlDataOfferEventAction)
+	pb.WithUint(uint32(dndAction))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_data_source: offer to transfer data
+const (
+	WlDataSourceInterface = "wl_data_source"
+	WlDataSourceVersion   = 3
+)
+
+// wl_data_source request opcodes
+const (
+	WlDataSourceRequestOffer      uint16 = 0 // add an offered mime type
+	WlDataSourceRequestDestroy    uint16 = 1 // destroy the data source
+	WlDataSourceRequestSetActions uint16 = 2 // set the available drag-and-drop actions
+)
+
+// wl_data_source request since versions
+const (
+	WlDataSourceRequestSetActionsSince = 3
+)
+
+// wl_data_source event opcodes
+const (
+	WlDataSourceEventTarget           uint16 = 0 // a target accepts an offered mime type
+	WlDataSourceEventSend             uint16 = 1 // send the data
+	WlDataSourceEventCancelled        uint16 = 2 // selection was cancelled
+	WlDataSourceEventDndDropPerformed uint16 = 3 // the drag-and-drop operation physically finished
+	WlDataSourceEventDndFinished      uint16 = 4 // the drag-and-drop operation concluded
+	WlDataSourceEventAction           uint16 = 5 // notify the selected action
+)
+
+// wl_data_source event since versions
+const (
+	WlDataSourceEventDndDropPerformedSince = 3
+	WlDataSourceEventDndFinishedSince      = 3
+	WlDataSourceEventActionSince           = 3
+)
+
+// WlDataSourceError:
+type WlDataSourceError uint32
+
+const (
+	WlDataSourceErrorInvalidActionMask WlDataSourceError = 0 // action mask contains invalid values
+	WlDataSourceErrorInvalidSource     WlDataSourceError = 1 // source doesn't accept this request
+)
+
+// WlDataSourceOfferRequest holds the arguments of a wl_data_source.offer request
+type WlDataSourceOfferRequest struct {
+	MimeType string
+}
+
+// ParseWlDataSourceOfferRequest decodes a wl_data_source.offer request
+func ParseWlDataSourceOfferRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataSourceOfferRequest, error) {
+	mimeType := NewStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, mimeType); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_source.offer#%d: %v", packet.Address, err)
+	}
+	return &WlDataSourceOfferRequest{
+		MimeType: string(*mimeType),
+	}, nil
+}
+
+// WlDataSourceSetActionsRequest holds the arguments of a wl_data_source.set_actions request
+type WlDataSourceSetActionsRequest struct {
+	DndActions WlDataDeviceManagerDndAction
+}
+
+// ParseWlDataSourceSetActionsRequest decodes a wl_data_source.set_actions request
+func ParseWlDataSourceSetActionsRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataSourceSetActionsRequest, error) {
+	dndActions := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, dndActions); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_source.set_actions#%d: %v", packet.Address, err)
+	}
+	return &WlDataSourceSetActionsRequest{
+		DndActions: WlDataDeviceManagerDndAction(*dndActions),
+	}, nil
+}
+
+// SendWlDataSourceTarget sends a wl_data_source.target event: a target accepts an offered mime type
+func SendWlDataSourceTarget(wsc *WaylandServerConn, id uint32, mimeType *string) {
+	pb := NewPacketBuilder(id, WlDataSourceEventTarget)
+	pb.WithNullableString(mimeType)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataSourceSend sends a wl_data_source.send event: send the data
+func SendWlDataSourceSend(wsc *WaylandServerConn, id uint32, mimeType string, fd int) {
+	pb := NewPacketBuilder(id, WlDataSourceEventSend)
+	pb.WithString(mimeType)
+	pb.WithFd(fd)
+	wsc.SendMessageWithFds(pb.Build(), pb.Fds())
+}
+
+// SendWlDataSourceCancelled sends a wl_data_source.cancelled event: selection was cancelled
+func SendWlDataSourceCancelled(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlDataSourceEventCancelled)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataSourceDndDropPerformed sends a wl_data_source.dnd_drop_performed event: the drag-and-drop operation physically finished
+func SendWlDataSourceDndDropPerformed(wsc *WaylandServerConn, id uint32) {
+	if wsc.registry.Version(id) < WlDataSourceEventDndDropPerformedSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlDataSourceEventDndDropPerformed)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataSourceDndFinished sends a wl_data_source.dnd_finished event: the drag-and-drop operation concluded
+func SendWlDataSourceDndFinished(wsc *WaylandServerConn, id uint32) {
+	if wsc.registry.Version(id) < WlDataSourceEventDndFinishedSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlDataSourceEventDndFinished)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataSourceAction sends a wl_data_source.action event: notify the selected action
+func SendWlDataSourceAction(wsc *WaylandServerConn, id uint32, dndAction WlDataDeviceManagerDndAction) {
+	if wsc.registry.Version(id) < WlDataSourceEventActionSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlDataSourceEventAction)
+	pb.WithUint(uint32(dndAction))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_data_device: data transfer device
+const (
+	WlDataDeviceInterface = "wl_data_device"
+	WlDataDeviceVersion   = 3
+)
+
+// wl_data_device request opcodes
+const (
+	WlDataDeviceRequestStartDrag    uint16 = 0 // start drag-and-drop operation
+	WlDataDeviceRequestSetSelection uint16 = 1 // copy data to the selection
+	WlDataDeviceRequestRelease      uint16 = 2 // destroy data device
+)
+
+// wl_data_device request since versions
+const (
+	WlDataDeviceRequestReleaseSince = 2
+)
+
+// wl_data_device event opcodes
+const (
+	WlDataDeviceEventDataOffer uint16 = 0 // introduce a new wl_data_offer
+	WlDataDeviceEventEnter     uint16 = 1 // initiate drag-and-drop session
+	WlDataDeviceEventLeave     uint16 = 2 // end drag-and-drop session
+	WlDataDeviceEventMotion    uint16 = 3 // drag-and-drop session motion
+	WlDataDeviceEventDrop      uint16 = 4 // end drag-and-drop session successfully
+	WlDataDeviceEventSelection uint16 = 5 // advertise new selection
+)
+
+// WlDataDeviceError:
+type WlDataDeviceError uint32
+
+const (
+	WlDataDeviceErrorRole       WlDataDeviceError = 0 // given wl_surface has another role
+	WlDataDeviceErrorUsedSource WlDataDeviceError = 1 // source has already been used
+)
+
+// WlDataDeviceStartDragRequest holds the arguments of a wl_data_device.start_drag request
+type WlDataDeviceStartDragRequest struct {
+	Source uint32
+	Origin uint32
+	Icon   uint32
+	Serial uint32
+}
+
+// ParseWlDataDeviceStartDragRequest decodes a wl_data_device.start_drag request
+func ParseWlDataDeviceStartDragRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataDeviceStartDragRequest, error) {
+	source := NewNullableObjectField()
+	origin := NewObjectField()
+	icon := NewNullableObjectField()
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, source, origin, icon, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_device.start_drag#%d: %v", packet.Address, err)
+	}
+	return &WlDataDeviceStartDragRequest{
+		Source: uint32(*source),
+		Origin: uint32(*origin),
+		Icon:   uint32(*icon),
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// WlDataDeviceSetSelectionRequest holds the arguments of a wl_data_device.set_selection request
+type WlDataDeviceSetSelectionRequest struct {
+	Source uint32
+	Serial uint32
+}
+
+// ParseWlDataDeviceSetSelectionRequest decodes a wl_data_device.set_selection request
+func ParseWlDataDeviceSetSelectionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataDeviceSetSelectionRequest, error) {
+	source := NewNullableObjectField()
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, source, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_device.set_selection#%d: %v", packet.Address, err)
+	}
+	return &WlDataDeviceSetSelectionRequest{
+		Source: uint32(*source),
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// SendWlDataDeviceDataOffer sends a wl_data_device.data_offer event: introduce a new wl_data_offer
+func SendWlDataDeviceDataOffer(wsc *WaylandServerConn, id uint32, idArg uint32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventDataOffer)
+	pb.WithUint(idArg)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataDeviceEnter sends a wl_data_device.enter event: initiate drag-and-drop session
+func SendWlDataDeviceEnter(wsc *WaylandServerConn, id uint32, serial uint32, surface uint32, x float32, y float32, idArg uint32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventEnter)
+	pb.WithUint(serial)
+	pb.WithUint(surface)
+	pb.WithFixed(x)
+	pb.WithFixed(y)
+	pb.WithUint(idArg)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataDeviceLeave sends a wl_data_device.leave event: end drag-and-drop session
+func SendWlDataDeviceLeave(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventLeave)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataDeviceMotion sends a wl_data_device.motion event: drag-and-drop session motion
+func SendWlDataDeviceMotion(wsc *WaylandServerConn, id uint32, time uint32, x float32, y float32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventMotion)
+	pb.WithUint(time)
+	pb.WithFixed(x)
+	pb.WithFixed(y)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataDeviceDrop sends a wl_data_device.drop event: end drag-and-drop session successfully
+func SendWlDataDeviceDrop(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventDrop)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlDataDeviceSelection sends a wl_data_device.selection event: advertise new selection
+func SendWlDataDeviceSelection(wsc *WaylandServerConn, id uint32, idArg uint32) {
+	pb := NewPacketBuilder(id, WlDataDeviceEventSelection)
+	pb.WithUint(idArg)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_data_device_manager: data transfer interface
+const (
+	WlDataDeviceManagerInterface = "wl_data_device_manager"
+	WlDataDeviceManagerVersion   = 3
+)
+
+// wl_data_device_manager request opcodes
+const (
+	WlDataDeviceManagerRequestCreateDataSource uint16 = 0 // create a new data source
+	WlDataDeviceManagerRequestGetDataDevice    uint16 = 1 // create a new data device
+)
+
+// WlDataDeviceManagerDndAction: drag and drop actions
+type WlDataDeviceManagerDndAction uint32
+
+const (
+	WlDataDeviceManagerDndActionNone WlDataDeviceManagerDndAction = 0 // no action
+	WlDataDeviceManagerDndActionCopy WlDataDeviceManagerDndAction = 1 // copy action
+	WlDataDeviceManagerDndActionMove WlDataDeviceManagerDndAction = 2 // move action
+	WlDataDeviceManagerDndActionAsk  WlDataDeviceManagerDndAction = 4 // ask action
+)
+
+// WlDataDeviceManagerCreateDataSourceRequest holds the arguments of a wl_data_device_manager.create_data_source request
+type WlDataDeviceManagerCreateDataSourceRequest struct {
+	Id uint32
+}
+
+// ParseWlDataDeviceManagerCreateDataSourceRequest decodes a wl_data_device_manager.create_data_source request
+func ParseWlDataDeviceManagerCreateDataSourceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataDeviceManagerCreateDataSourceRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_device_manager.create_data_source#%d: %v", packet.Address, err)
+	}
+	return &WlDataDeviceManagerCreateDataSourceRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// WlDataDeviceManagerGetDataDeviceRequest holds the arguments of a wl_data_device_manager.get_data_device request
+type WlDataDeviceManagerGetDataDeviceRequest struct {
+	Id   uint32
+	Seat uint32
+}
+
+// ParseWlDataDeviceManagerGetDataDeviceRequest decodes a wl_data_device_manager.get_data_device request
+func ParseWlDataDeviceManagerGetDataDeviceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlDataDeviceManagerGetDataDeviceRequest, error) {
+	idArg := NewObjectField()
+	seat := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, seat); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_data_device_manager.get_data_device#%d: %v", packet.Address, err)
+	}
+	return &WlDataDeviceManagerGetDataDeviceRequest{
+		Id:   uint32(*idArg),
+		Seat: uint32(*seat),
+	}, nil
+}
+
+// wl_shell: create desktop-style surfaces
+const (
+	WlShellInterface = "wl_shell"
+	WlShellVersion   = 1
+)
+
+// wl_shell request opcodes
+const (
+	WlShellRequestGetShellSurface uint16 = 0 // create a shell surface from a surface
+)
+
+// WlShellError:
+type WlShellError uint32
+
+const (
+	WlShellErrorRole WlShellError = 0 // given wl_surface has another role
+)
+
+// WlShellGetShellSurfaceRequest holds the arguments of a wl_shell.get_shell_surface request
+type WlShellGetShellSurfaceRequest struct {
+	Id      uint32
+	Surface uint32
+}
+
+// ParseWlShellGetShellSurfaceRequest decodes a wl_shell.get_shell_surface request
+func ParseWlShellGetShellSurfaceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellGetShellSurfaceRequest, error) {
+	idArg := NewObjectField()
+	surface := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, surface); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell.get_shell_surface#%d: %v", packet.Address, err)
+	}
+	return &WlShellGetShellSurfaceRequest{
+		Id:      uint32(*idArg),
+		Surface: uint32(*surface),
+	}, nil
+}
+
+// wl_shell_surface: desktop-style metadata interface
+const (
+	WlShellSurfaceInterface = "wl_shell_surface"
+	WlShellSurfaceVersion   = 1
+)
+
+// wl_shell_surface request opcodes
+const (
+	WlShellSurfaceRequestPong          uint16 = 0 // respond to a ping event
+	WlShellSurfaceRequestMove          uint16 = 1 // start an interactive move
+	WlShellSurfaceRequestResize        uint16 = 2 // start an interactive resize
+	WlShellSurfaceRequestSetToplevel   uint16 = 3 // make the surface a toplevel surface
+	WlShellSurfaceRequestSetTransient  uint16 = 4 // make the surface a transient surface
+	WlShellSurfaceRequestSetFullscreen uint16 = 5 // make the surface a fullscreen surface
+	WlShellSurfaceRequestSetPopup      uint16 = 6 // make the surface a popup surface
+	WlShellSurfaceRequestSetMaximized  uint16 = 7 // make the surface a maximized surface
+	WlShellSurfaceRequestSetTitle      uint16 = 8 // set surface title
+	WlShellSurfaceRequestSetClass      uint16 = 9 // set surface class
+)
+
+// wl_shell_surface event opcodes
+const (
+	WlShellSurfaceEventPing      uint16 = 0 // ping client
+	WlShellSurfaceEventConfigure uint16 = 1 // suggest resize
+	WlShellSurfaceEventPopupDone uint16 = 2 // popup interaction is done
+)
+
+// WlShellSurfaceResize: edge values for resizing
+type WlShellSurfaceResize uint32
+
+const (
+	WlShellSurfaceResizeNone        WlShellSurfaceResize = 0  // no edge
+	WlShellSurfaceResizeTop         WlShellSurfaceResize = 1  // top edge
+	WlShellSurfaceResizeBottom      WlShellSurfaceResize = 2  // bottom edge
+	WlShellSurfaceResizeLeft        WlShellSurfaceResize = 4  // left edge
+	WlShellSurfaceResizeTopLeft     WlShellSurfaceResize = 5  // top and left edges
+	WlShellSurfaceResizeBottomLeft  WlShellSurfaceResize = 6  // bottom and left edges
+	WlShellSurfaceResizeRight       WlShellSurfaceResize = 8  // right edge
+	WlShellSurfaceResizeTopRight    WlShellSurfaceResize = 9  // top and right edges
+	WlShellSurfaceResizeBottomRight WlShellSurfaceResize = 10 // bottom and right edges
+)
+
+// WlShellSurfaceTransient: details of transient behaviour
+type WlShellSurfaceTransient uint32
+
+const (
+	WlShellSurfaceTransientInactive WlShellSurfaceTransient = 0x1 // do not set keyboard focus
+)
+
+// WlShellSurfaceFullscreenMethod: different method to set the surface fullscreen
+type WlShellSurfaceFullscreenMethod uint32
+
+const (
+	WlShellSurfaceFullscreenMethodDefault WlShellSurfaceFullscreenMethod = 0 // no preference, apply default policy
+	WlShellSurfaceFullscreenMethodScale   WlShellSurfaceFullscreenMethod = 1 // scale, preserve the surface's aspect ratio and center on output
+	WlShellSurfaceFullscreenMethodDriver  WlShellSurfaceFullscreenMethod = 2 // switch output mode to the smallest mode that can fit the surface, add black borders to compensate size mismatch
+	WlShellSurfaceFullscreenMethodFill    WlShellSurfaceFullscreenMethod = 3 // no upscaling, center on output and add black borders to compensate size mismatch
+)
+
+// WlShellSurfacePongRequest holds the arguments of a wl_shell_surface.pong request
+type WlShellSurfacePongRequest struct {
+	Serial uint32
+}
+
+// ParseWlShellSurfacePongRequest decodes a wl_shell_surface.pong request
+func ParseWlShellSurfacePongRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfacePongRequest, error) {
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.pong#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfacePongRequest{
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// WlShellSurfaceMoveRequest holds the arguments of a wl_shell_surface.move request
+type WlShellSurfaceMoveRequest struct {
+	Seat   uint32
+	Serial uint32
+}
+
+// ParseWlShellSurfaceMoveRequest decodes a wl_shell_surface.move request
+func ParseWlShellSurfaceMoveRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceMoveRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.move#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceMoveRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// WlShellSurfaceResizeRequest holds the arguments of a wl_shell_surface.resize request
+type WlShellSurfaceResizeRequest struct {
+	Seat   uint32
+	Serial uint32
+	Edges  WlShellSurfaceResize
+}
+
+// ParseWlShellSurfaceResizeRequest decodes a wl_shell_surface.resize request
+func ParseWlShellSurfaceResizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceResizeRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	edges := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial, edges); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.resize#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceResizeRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+		Edges:  WlShellSurfaceResize(*edges),
+	}, nil
+}
+
+// WlShellSurfaceSetTransientRequest holds the arguments of a wl_shell_surface.set_transient request
+type WlShellSurfaceSetTransientRequest struct {
+	Parent uint32
+	X      int32
+	Y      int32
+	Flags  WlShellSurfaceTransient
+}
+
+// ParseWlShellSurfaceSetTransientRequest decodes a wl_shell_surface.set_transient request
+func ParseWlShellSurfaceSetTransientRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetTransientRequest, error) {
+	parent := NewObjectField()
+	x := NewIntField()
+	y := NewIntField()
+	flags := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, parent, x, y, flags); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_transient#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetTransientRequest{
+		Parent: uint32(*parent),
+		X:      int32(*x),
+		Y:      int32(*y),
+		Flags:  WlShellSurfaceTransient(*flags),
+	}, nil
+}
+
+// WlShellSurfaceSetFullscreenRequest holds the arguments of a wl_shell_surface.set_fullscreen request
+type WlShellSurfaceSetFullscreenRequest struct {
+	Method    WlShellSurfaceFullscreenMethod
+	Framerate uint32
+	Output    uint32
+}
+
+// ParseWlShellSurfaceSetFullscreenRequest decodes a wl_shell_surface.set_fullscreen request
+func ParseWlShellSurfaceSetFullscreenRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetFullscreenRequest, error) {
+	method := NewUintField()
+	framerate := NewUintField()
+	output := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, method, framerate, output); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_fullscreen#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetFullscreenRequest{
+		Method:    WlShellSurfaceFullscreenMethod(*method),
+		Framerate: uint32(*framerate),
+		Output:    uint32(*output),
+	}, nil
+}
+
+// WlShellSurfaceSetPopupRequest holds the arguments of a wl_shell_surface.set_popup request
+type WlShellSurfaceSetPopupRequest struct {
+	Seat   uint32
+	Serial uint32
+	Parent uint32
+	X      int32
+	Y      int32
+	Flags  WlShellSurfaceTransient
+}
+
+// ParseWlShellSurfaceSetPopupRequest decodes a wl_shell_surface.set_popup request
+func ParseWlShellSurfaceSetPopupRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetPopupRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	parent := NewObjectField()
+	x := NewIntField()
+	y := NewIntField()
+	flags := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial, parent, x, y, flags); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_popup#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetPopupRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+		Parent: uint32(*parent),
+		X:      int32(*x),
+		Y:      int32(*y),
+		Flags:  WlShellSurfaceTransient(*flags),
+	}, nil
+}
+
+// WlShellSurfaceSetMaximizedRequest holds the arguments of a wl_shell_surface.set_maximized request
+type WlShellSurfaceSetMaximizedRequest struct {
+	Output uint32
+}
+
+// ParseWlShellSurfaceSetMaximizedRequest decodes a wl_shell_surface.set_maximized request
+func ParseWlShellSurfaceSetMaximizedRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetMaximizedRequest, error) {
+	output := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, output); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_maximized#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetMaximizedRequest{
+		Output: uint32(*output),
+	}, nil
+}
+
+// WlShellSurfaceSetTitleRequest holds the arguments of a wl_shell_surface.set_title request
+type WlShellSurfaceSetTitleRequest struct {
+	Title string
+}
+
+// ParseWlShellSurfaceSetTitleRequest decodes a wl_shell_surface.set_title request
+func ParseWlShellSurfaceSetTitleRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetTitleRequest, error) {
+	title := NewStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, title); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_title#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetTitleRequest{
+		Title: string(*title),
+	}, nil
+}
+
+// WlShellSurfaceSetClassRequest holds the arguments of a wl_shell_surface.set_class request
+type WlShellSurfaceSetClassRequest struct {
+	Class string
+}
+
+// ParseWlShellSurfaceSetClassRequest decodes a wl_shell_surface.set_class request
+func ParseWlShellSurfaceSetClassRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlShellSurfaceSetClassRequest, error) {
+	class := NewStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, class); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_shell_surface.set_class#%d: %v", packet.Address, err)
+	}
+	return &WlShellSurfaceSetClassRequest{
+		Class: string(*class),
+	}, nil
+}
+
+// SendWlShellSurfacePing sends a wl_shell_surface.ping event: ping client
+func SendWlShellSurfacePing(wsc *WaylandServerConn, id uint32, serial uint32) {
+	pb := NewPacketBuilder(id, WlShellSurfaceEventPing)
+	pb.WithUint(serial)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlShellSurfaceConfigure sends a wl_shell_surface.configure event: suggest resize
+func SendWlShellSurfaceConfigure(wsc *WaylandServerConn, id uint32, edges WlShellSurfaceResize, width int32, height int32) {
+	pb := NewPacketBuilder(id, WlShellSurfaceEventConfigure)
+	pb.WithUint(uint32(edges))
+	pb.WithInt(int32(width))
+	pb.WithInt(int32(height))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlShellSurfacePopupDone sends a wl_shell_surface.popup_done event: popup interaction is done
+func SendWlShellSurfacePopupDone(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlShellSurfaceEventPopupDone)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_surface: an onscreen surface
+const (
+	WlSurfaceInterface = "wl_surface"
+	WlSurfaceVersion   = 6
+)
+
+// wl_surface request opcodes
+const (
+	WlSurfaceRequestDestroy            uint16 = 0  // delete surface
+	WlSurfaceRequestAttach             uint16 = 1  // set the surface contents
+	WlSurfaceRequestDamage             uint16 = 2  // mark part of the surface damaged
+	WlSurfaceRequestFrame              uint16 = 3  // request a frame throttling hint
+	WlSurfaceRequestSetOpaqueRegion    uint16 = 4  // set opaque region
+	WlSurfaceRequestSetInputRegion     uint16 = 5  // set input region
+	WlSurfaceRequestCommit             uint16 = 6  // commit pending surface state
+	WlSurfaceRequestSetBufferTransform uint16 = 7  // sets the buffer transformation
+	WlSurfaceRequestSetBufferScale     uint16 = 8  // sets the buffer scaling factor
+	WlSurfaceRequestDamageBuffer       uint16 = 9  // mark part of the surface damaged using buffer coordinates
+	WlSurfaceRequestOffset             uint16 = 10 // set the surface contents offset
+)
+
+// wl_surface request since versions
+const (
+	WlSurfaceRequestSetBufferTransformSince = 2
+	WlSurfaceRequestSetBufferScaleSince     = 3
+	WlSurfaceRequestDamageBufferSince       = 4
+	WlSurfaceRequestOffsetSince             = 5
+)
+
+// wl_surface event opcodes
+const (
+	WlSurfaceEventEnter                    uint16 = 0 // surface enters an output
+	WlSurfaceEventLeave                    uint16 = 1 // surface leaves an output
+	WlSurfaceEventPreferredBufferScale     uint16 = 2 // preferred buffer scale for the surface
+	WlSurfaceEventPreferredBufferTransform uint16 = 3 // preferred buffer transform for the surface
+)
+
+// wl_surface event since versions
+const (
+	WlSurfaceEventPreferredBufferScaleSince     = 6
+	WlSurfaceEventPreferredBufferTransformSince = 6
+)
+
+// WlSurfaceError: wl_surface error values
+type WlSurfaceError uint32
+
+const (
+	WlSurfaceErrorInvalidScale      WlSurfaceError = 0 // buffer scale value is invalid
+	WlSurfaceErrorInvalidTransform  WlSurfaceError = 1 // buffer transform value is invalid
+	WlSurfaceErrorInvalidSize       WlSurfaceError = 2 // buffer size is invalid
+	WlSurfaceErrorInvalidOffset     WlSurfaceError = 3 // buffer offset is invalid
+	WlSurfaceErrorDefunctRoleObject WlSurfaceError = 4 // surface was destroyed before its role object
+)
+
+// WlSurfaceAttachRequest holds the arguments of a wl_surface.attach request
+type WlSurfaceAttachRequest struct {
+	Buffer uint32
+	X      int32
+	Y      int32
+}
+
+// ParseWlSurfaceAttachRequest decodes a wl_surface.attach request
+func ParseWlSurfaceAttachRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceAttachRequest, error) {
+	buffer := NewNullableObjectField()
+	x := NewIntField()
+	y := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, buffer, x, y); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.attach#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceAttachRequest{
+		Buffer: uint32(*buffer),
+		X:      int32(*x),
+		Y:      int32(*y),
+	}, nil
+}
+
+// WlSurfaceDamageRequest holds the arguments of a wl_surface.damage request
+type WlSurfaceDamageRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseWlSurfaceDamageRequest decodes a wl_surface.damage request
+func ParseWlSurfaceDamageRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceDamageRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.damage#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceDamageRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// WlSurfaceFrameRequest holds the arguments of a wl_surface.frame request
+type WlSurfaceFrameRequest struct {
+	Callback uint32
+}
+
+// ParseWlSurfaceFrameRequest decodes a wl_surface.frame request
+func ParseWlSurfaceFrameRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceFrameRequest, error) {
+	callback := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, callback); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.frame#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceFrameRequest{
+		Callback: uint32(*callback),
+	}, nil
+}
+
+// WlSurfaceSetOpaqueRegionRequest holds the arguments of a wl_surface.set_opaque_region request
+type WlSurfaceSetOpaqueRegionRequest struct {
+	Region uint32
+}
+
+// ParseWlSurfaceSetOpaqueRegionRequest decodes a wl_surface.set_opaque_region request
+func ParseWlSurfaceSetOpaqueRegionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceSetOpaqueRegionRequest, error) {
+	region := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, region); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.set_opaque_region#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceSetOpaqueRegionRequest{
+		Region: uint32(*region),
+	}, nil
+}
+
+// WlSurfaceSetInputRegionRequest holds the arguments of a wl_surface.set_input_region request
+type WlSurfaceSetInputRegionRequest struct {
+	Region uint32
+}
+
+// ParseWlSurfaceSetInputRegionRequest decodes a wl_surface.set_input_region request
+func ParseWlSurfaceSetInputRegionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceSetInputRegionRequest, error) {
+	region := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, region); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.set_input_region#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceSetInputRegionRequest{
+		Region: uint32(*region),
+	}, nil
+}
+
+// WlSurfaceSetBufferTransformRequest holds the arguments of a wl_surface.set_buffer_transform request
+type WlSurfaceSetBufferTransformRequest struct {
+	Transform WlOutputTransform
+}
+
+// ParseWlSurfaceSetBufferTransformRequest decodes a wl_surface.set_buffer_transform request
+func ParseWlSurfaceSetBufferTransformRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceSetBufferTransformRequest, error) {
+	transform := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, transform); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.set_buffer_transform#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceSetBufferTransformRequest{
+		Transform: WlOutputTransform(*transform),
+	}, nil
+}
+
+// WlSurfaceSetBufferScaleRequest holds the arguments of a wl_surface.set_buffer_scale request
+type WlSurfaceSetBufferScaleRequest struct {
+	Scale int32
+}
+
+// ParseWlSurfaceSetBufferScaleRequest decodes a wl_surface.set_buffer_scale request
+func ParseWlSurfaceSetBufferScaleRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceSetBufferScaleRequest, error) {
+	scale := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, scale); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.set_buffer_scale#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceSetBufferScaleRequest{
+		Scale: int32(*scale),
+	}, nil
+}
+
+// WlSurfaceDamageBufferRequest holds the arguments of a wl_surface.damage_buffer request
+type WlSurfaceDamageBufferRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseWlSurfaceDamageBufferRequest decodes a wl_surface.damage_buffer request
+func ParseWlSurfaceDamageBufferRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceDamageBufferRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.damage_buffer#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceDamageBufferRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// WlSurfaceOffsetRequest holds the arguments of a wl_surface.offset request
+type WlSurfaceOffsetRequest struct {
+	X int32
+	Y int32
+}
+
+// ParseWlSurfaceOffsetRequest decodes a wl_surface.offset request
+func ParseWlSurfaceOffsetRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSurfaceOffsetRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_surface.offset#%d: %v", packet.Address, err)
+	}
+	return &WlSurfaceOffsetRequest{
+		X: int32(*x),
+		Y: int32(*y),
+	}, nil
+}
+
+// SendWlSurfaceEnter sends a wl_surface.enter event: surface enters an output
+func SendWlSurfaceEnter(wsc *WaylandServerConn, id uint32, output uint32) {
+	pb := NewPacketBuilder(id, WlSurfaceEventEnter)
+	pb.WithUint(output)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlSurfaceLeave sends a wl_surface.leave event: surface leaves an output
+func SendWlSurfaceLeave(wsc *WaylandServerConn, id uint32, output uint32) {
+	pb := NewPacketBuilder(id, WlSurfaceEventLeave)
+	pb.WithUint(output)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlSurfacePreferredBufferScale sends a wl_surface.preferred_buffer_scale event: preferred buffer scale for the surface
+func SendWlSurfacePreferredBufferScale(wsc *WaylandServerConn, id uint32, factor int32) {
+	if wsc.registry.Version(id) < WlSurfaceEventPreferredBufferScaleSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlSurfaceEventPreferredBufferScale)
+	pb.WithInt(int32(factor))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlSurfacePreferredBufferTransform sends a wl_surface.preferred_buffer_transform event: preferred buffer transform for the surface
+func SendWlSurfacePreferredBufferTransform(wsc *WaylandServerConn, id uint32, transform WlOutputTransform) {
+	if wsc.registry.Version(id) < WlSurfaceEventPreferredBufferTransformSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlSurfaceEventPreferredBufferTransform)
+	pb.WithUint(uint32(transform))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_seat: group of input devices
+const (
+	WlSeatInterface = "wl_seat"
+	WlSeatVersion   = 9
+)
+
+// wl_seat request opcodes
+const (
+	WlSeatRequestGetPointer  uint16 = 0 // return pointer object
+	WlSeatRequestGetKeyboard uint16 = 1 // return keyboard object
+	WlSeatRequestGetTouch    uint16 = 2 // return touch object
+	WlSeatRequestRelease     uint16 = 3 // release the seat object
+)
+
+// wl_seat request since versions
+const (
+	WlSeatRequestReleaseSince = 5
+)
+
+// wl_seat event opcodes
+const (
+	WlSeatEventCapabilities uint16 = 0 // seat capabilities changed
+	WlSeatEventName         uint16 = 1 // unique identifier for this seat
+)
+
+// wl_seat event since versions
+const (
+	WlSeatEventNameSince = 2
+)
+
+// WlSeatCapability: seat capability bitmask
+type WlSeatCapability uint32
+
+const (
+	WlSeatCapabilityPointer  WlSeatCapability = 1 // the seat has pointer devices
+	WlSeatCapabilityKeyboard WlSeatCapability = 2 // the seat has one or more keyboards
+	WlSeatCapabilityTouch    WlSeatCapability = 4 // the seat has touch devices
+)
+
+// WlSeatError: wl_seat error values
+type WlSeatError uint32
+
+const (
+	WlSeatErrorMissingCapability WlSeatError = 0 // get_pointer, get_keyboard or get_touch called on seat without the matching capability
+)
+
+// WlSeatGetPointerRequest holds the arguments of a wl_seat.get_pointer request
+type WlSeatGetPointerRequest struct {
+	Id uint32
+}
+
+// ParseWlSeatGetPointerRequest decodes a wl_seat.get_pointer request
+func ParseWlSeatGetPointerRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSeatGetPointerRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_seat.get_pointer#%d: %v", packet.Address, err)
+	}
+	return &WlSeatGetPointerRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// WlSeatGetKeyboardRequest holds the arguments of a wl_seat.get_keyboard request
+type WlSeatGetKeyboardRequest struct {
+	Id uint32
+}
+
+// ParseWlSeatGetKeyboardRequest decodes a wl_seat.get_keyboard request
+func ParseWlSeatGetKeyboardRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSeatGetKeyboardRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_seat.get_keyboard#%d: %v", packet.Address, err)
+	}
+	return &WlSeatGetKeyboardRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// WlSeatGetTouchRequest holds the arguments of a wl_seat.get_touch request
+type WlSeatGetTouchRequest struct {
+	Id uint32
+}
+
+// ParseWlSeatGetTouchRequest decodes a wl_seat.get_touch request
+func ParseWlSeatGetTouchRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSeatGetTouchRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_seat.get_touch#%d: %v", packet.Address, err)
+	}
+	return &WlSeatGetTouchRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// SendWlSeatCapabilities sends a wl_seat.capabilities event: seat capabilities changed
+func SendWlSeatCapabilities(wsc *WaylandServerConn, id uint32, capabilities WlSeatCapability) {
+	pb := NewPacketBuilder(id, WlSeatEventCapabilities)
+	pb.WithUint(uint32(capabilities))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlSeatName sends a wl_seat.name event: unique identifier for this seat
+func SendWlSeatName(wsc *WaylandServerConn, id uint32, name string) {
+	if wsc.registry.Version(id) < WlSeatEventNameSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlSeatEventName)
+	pb.WithString(name)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_pointer: pointer input device
+const (
+	WlPointerInterface = "wl_pointer"
+	WlPointerVersion   = 9
+)
+
+// wl_pointer request opcodes
+const (
+	WlPointerRequestSetCursor uint16 = 0 // set the pointer surface
+	WlPointerRequestRelease   uint16 = 1 // release the pointer object
+)
+
+// wl_pointer request since versions
+const (
+	WlPointerRequestReleaseSince = 3
+)
+
+// wl_pointer event opcodes
+const (
+	WlPointerEventEnter                 uint16 = 0  // enter event
+	WlPointerEventLeave                 uint16 = 1  // leave event
+	WlPointerEventMotion                uint16 = 2  // pointer motion event
+	WlPointerEventButton                uint16 = 3  // pointer button event
+	WlPointerEventAxis                  uint16 = 4  // axis event
+	WlPointerEventFrame                 uint16 = 5  // end of a pointer event sequence
+	WlPointerEventAxisSource            uint16 = 6  // axis source event
+	WlPointerEventAxisStop              uint16 = 7  // axis stop event
+	WlPointerEventAxisDiscrete          uint16 = 8  // axis click event
+	WlPointerEventAxisValue120          uint16 = 9  // axis high-resolution scroll event
+	WlPointerEventAxisRelativeDirection uint16 = 10 // axis relative physical direction event
+)
+
+// wl_pointer event since versions
+const (
+	WlPointerEventFrameSince                 = 5
+	WlPointerEventAxisSourceSince            = 5
+	WlPointerEventAxisStopSince              = 5
+	WlPointerEventAxisDiscreteSince          = 5
+	WlPointerEventAxisValue120Since          = 8
+	WlPointerEventAxisRelativeDirectionSince = 9
+)
+
+// WlPointerError:
+type WlPointerError uint32
+
+const (
+	WlPointerErrorRole WlPointerError = 0 // given wl_surface has another role
+)
+
+// WlPointerButtonState: physical button state
+type WlPointerButtonState uint32
+
+const (
+	WlPointerButtonStateReleased WlPointerButtonState = 0 // the button is not pressed
+	WlPointerButtonStatePressed  WlPointerButtonState = 1 // the button is pressed
+)
+
+// WlPointerAxis: axis types
+type WlPointerAxis uint32
+
+const (
+	WlPointerAxisVerticalScroll   WlPointerAxis = 0 // vertical axis
+	WlPointerAxisHorizontalScroll WlPointerAxis = 1 // horizontal axis
+)
+
+// WlPointerAxisSource: axis source types
+type WlPointerAxisSource uint32
+
+const (
+	WlPointerAxisSourceWheel      WlPointerAxisSource = 0 // a physical wheel rotation
+	WlPointerAxisSourceFinger     WlPointerAxisSource = 1 // finger on a touch surface
+	WlPointerAxisSourceContinuous WlPointerAxisSource = 2 // continuous coordinate space
+	WlPointerAxisSourceWheelTilt  WlPointerAxisSource = 3 // a physical wheel tilt
+)
+
+// WlPointerAxisRelativeDirection: axis relative direction
+type WlPointerAxisRelativeDirection uint32
+
+const (
+	WlPointerAxisRelativeDirectionIdentical WlPointerAxisRelativeDirection = 0 // physical motion matches axis direction
+	WlPointerAxisRelativeDirectionInverted  WlPointerAxisRelativeDirection = 1 // physical motion is the inverse of the axis direction
+)
+
+// WlPointerSetCursorRequest holds the arguments of a wl_pointer.set_cursor request
+type WlPointerSetCursorRequest struct {
+	Serial   uint32
+	Surface  uint32
+	HotspotX int32
+	HotspotY int32
+}
+
+// ParseWlPointerSetCursorRequest decodes a wl_pointer.set_cursor request
+func ParseWlPointerSetCursorRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlPointerSetCursorRequest, error) {
+	serial := NewUintField()
+	surface := NewNullableObjectField()
+	hotspotX := NewIntField()
+	hotspotY := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial, surface, hotspotX, hotspotY); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_pointer.set_cursor#%d: %v", packet.Address, err)
+	}
+	return &WlPointerSetCursorRequest{
+		Serial:   uint32(*serial),
+		Surface:  uint32(*surface),
+		HotspotX: int32(*hotspotX),
+		HotspotY: int32(*hotspotY),
+	}, nil
+}
+
+// SendWlPointerEnter sends a wl_pointer.enter event: enter event
+func SendWlPointerEnter(wsc *WaylandServerConn, id uint32, serial uint32, surface uint32, surfaceX float32, surfaceY float32) {
+	pb := NewPacketBuilder(id, WlPointerEventEnter)
+	pb.WithUint(serial)
+	pb.WithUint(surface)
+	pb.WithFixed(surfaceX)
+	pb.WithFixed(surfaceY)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerLeave sends a wl_pointer.leave event: leave event
+func SendWlPointerLeave(wsc *WaylandServerConn, id uint32, serial uint32, surface uint32) {
+	pb := NewPacketBuilder(id, WlPointerEventLeave)
+	pb.WithUint(serial)
+	pb.WithUint(surface)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerMotion sends a wl_pointer.motion event: pointer motion event
+func SendWlPointerMotion(wsc *WaylandServerConn, id uint32, time uint32, surfaceX float32, surfaceY float32) {
+	pb := NewPacketBuilder(id, WlPointerEventMotion)
+	pb.WithUint(time)
+	pb.WithFixed(surfaceX)
+	pb.WithFixed(surfaceY)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerButton sends a wl_pointer.button event: pointer button event
+func SendWlPointerButton(wsc *WaylandServerConn, id uint32, serial uint32, time uint32, button uint32, state WlPointerButtonState) {
+	pb := NewPacketBuilder(id, WlPointerEventButton)
+	pb.WithUint(serial)
+	pb.WithUint(time)
+	pb.WithUint(button)
+	pb.WithUint(uint32(state))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxis sends a wl_pointer.axis event: axis event
+func SendWlPointerAxis(wsc *WaylandServerConn, id uint32, time uint32, axis WlPointerAxis, value float32) {
+	pb := NewPacketBuilder(id, WlPointerEventAxis)
+	pb.WithUint(time)
+	pb.WithUint(uint32(axis))
+	pb.WithFixed(value)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerFrame sends a wl_pointer.frame event: end of a pointer event sequence
+func SendWlPointerFrame(wsc *WaylandServerConn, id uint32) {
+	if wsc.registry.Version(id) < WlPointerEventFrameSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventFrame)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxisSource sends a wl_pointer.axis_source event: axis source event
+func SendWlPointerAxisSource(wsc *WaylandServerConn, id uint32, axisSource WlPointerAxisSource) {
+	if wsc.registry.Version(id) < WlPointerEventAxisSourceSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventAxisSource)
+	pb.WithUint(uint32(axisSource))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxisStop sends a wl_pointer.axis_stop event: axis stop event
+func SendWlPointerAxisStop(wsc *WaylandServerConn, id uint32, time uint32, axis WlPointerAxis) {
+	if wsc.registry.Version(id) < WlPointerEventAxisStopSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventAxisStop)
+	pb.WithUint(time)
+	pb.WithUint(uint32(axis))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxisDiscrete sends a wl_pointer.axis_discrete event: axis click event
+func SendWlPointerAxisDiscrete(wsc *WaylandServerConn, id uint32, axis WlPointerAxis, discrete int32) {
+	if wsc.registry.Version(id) < WlPointerEventAxisDiscreteSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventAxisDiscrete)
+	pb.WithUint(uint32(axis))
+	pb.WithInt(int32(discrete))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxisValue120 sends a wl_pointer.axis_value120 event: axis high-resolution scroll event
+func SendWlPointerAxisValue120(wsc *WaylandServerConn, id uint32, axis WlPointerAxis, value120 int32) {
+	if wsc.registry.Version(id) < WlPointerEventAxisValue120Since {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventAxisValue120)
+	pb.WithUint(uint32(axis))
+	pb.WithInt(int32(value120))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlPointerAxisRelativeDirection sends a wl_pointer.axis_relative_direction event: axis relative physical direction event
+func SendWlPointerAxisRelativeDirection(wsc *WaylandServerConn, id uint32, axis WlPointerAxis, direction WlPointerAxisRelativeDirection) {
+	if wsc.registry.Version(id) < WlPointerEventAxisRelativeDirectionSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlPointerEventAxisRelativeDirection)
+	pb.WithUint(uint32(axis))
+	pb.WithUint(uint32(direction))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_keyboard: keyboard input device
+const (
+	WlKeyboardInterface = "wl_keyboard"
+	WlKeyboardVersion   = 9
+)
+
+// wl_keyboard request opcodes
+const (
+	WlKeyboardRequestRelease uint16 = 0 // release the keyboard object
+)
+
+// wl_keyboard request since versions
+const (
+	WlKeyboardRequestReleaseSince = 3
+)
+
+// wl_keyboard event opcodes
+const (
+	WlKeyboardEventKeymap     uint16 = 0 // keyboard mapping
+	WlKeyboardEventEnter      uint16 = 1 // enter event
+	WlKeyboardEventLeave      uint16 = 2 // leave event
+	WlKeyboardEventKey        uint16 = 3 // key event
+	WlKeyboardEventModifiers  uint16 = 4 // modifier and group state
+	WlKeyboardEventRepeatInfo uint16 = 5 // repeat rate and delay
+)
+
+// wl_keyboard event since versions
+const (
+	WlKeyboardEventRepeatInfoSince = 4
+)
+
+// WlKeyboardKeymapFormat: keyboard mapping format
+type WlKeyboardKeymapFormat uint32
+
+const (
+	WlKeyboardKeymapFormatNoKeymap WlKeyboardKeymapFormat = 0 // no keymap; client must understand how to interpret the raw keycode
+	WlKeyboardKeymapFormatXkbV1    WlKeyboardKeymapFormat = 1 // libxkbcommon compatible, null-terminated string; to determine the xkb keycode, clients must add 8 to the key event keycode
+)
+
+// WlKeyboardKeyState: physical key state
+type WlKeyboardKeyState uint32
+
+const (
+	WlKeyboardKeyStateReleased WlKeyboardKeyState = 0 // key is not pressed
+	WlKeyboardKeyStatePressed  WlKeyboardKeyState = 1 // key is pressed
+)
+
+// SendWlKeyboardKeymap sends a wl_keyboard.keymap event: keyboard mapping
+func SendWlKeyboardKeymap(wsc *WaylandServerConn, id uint32, format WlKeyboardKeymapFormat, fd int, size uint32) {
+	pb := NewPacketBuilder(id, WlKeyboardEventKeymap)
+	pb.WithUint(uint32(format))
+	pb.WithFd(fd)
+	pb.WithUint(size)
+	wsc.SendMessageWithFds(pb.Build(), pb.Fds())
+}
+
+// SendWlKeyboardEnter sends a wl_keyboard.enter event: enter event
+func SendWlKeyboardEnter(wsc *WaylandServerConn, id uint32, serial uint32, surface uint32, keys []byte) {
+	pb := NewPacketBuilder(id, WlKeyboardEventEnter)
+	pb.WithUint(serial)
+	pb.WithUint(surface)
+	pb.WithArray(keys)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlKeyboardLeave sends a wl_keyboard.leave event: leave event
+func SendWlKeyboardLeave(wsc *WaylandServerConn, id uint32, serial uint32, surface uint32) {
+	pb := NewPacketBuilder(id, WlKeyboardEventLeave)
+	pb.WithUint(serial)
+	pb.WithUint(surface)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlKeyboardKey sends a wl_keyboard.key event: key event
+func SendWlKeyboardKey(wsc *WaylandServerConn, id uint32, serial uint32, time uint32, key uint32, state WlKeyboardKeyState) {
+	pb := NewPacketBuilder(id, WlKeyboardEventKey)
+	pb.WithUint(serial)
+	pb.WithUint(time)
+	pb.WithUint(key)
+	pb.WithUint(uint32(state))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlKeyboardModifiers sends a wl_keyboard.modifiers event: modifier and group state
+func SendWlKeyboardModifiers(wsc *WaylandServerConn, id uint32, serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) {
+	pb := NewPacketBuilder(id, WlKeyboardEventModifiers)
+	pb.WithUint(serial)
+	pb.WithUint(modsDepressed)
+	pb.WithUint(modsLatched)
+	pb.WithUint(modsLocked)
+	pb.WithUint(group)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlKeyboardRepeatInfo sends a wl_keyboard.repeat_info event: repeat rate and delay
+func SendWlKeyboardRepeatInfo(wsc *WaylandServerConn, id uint32, rate int32, delay int32) {
+	if wsc.registry.Version(id) < WlKeyboardEventRepeatInfoSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlKeyboardEventRepeatInfo)
+	pb.WithInt(int32(rate))
+	pb.WithInt(int32(delay))
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_touch: touchscreen input device
+const (
+	WlTouchInterface = "wl_touch"
+	WlTouchVersion   = 9
+)
+
+// wl_touch request opcodes
+const (
+	WlTouchRequestRelease uint16 = 0 // release the touch object
+)
+
+// wl_touch request since versions
+const (
+	WlTouchRequestReleaseSince = 3
+)
+
+// wl_touch event opcodes
+const (
+	WlTouchEventDown        uint16 = 0 // touch down event and beginning of a touch sequence
+	WlTouchEventUp          uint16 = 1 // end of a touch event sequence
+	WlTouchEventMotion      uint16 = 2 // update of touch point coordinates
+	WlTouchEventFrame       uint16 = 3 // end of touch frame event
+	WlTouchEventCancel      uint16 = 4 // touch session cancelled
+	WlTouchEventShape       uint16 = 5 // update shape of touch point
+	WlTouchEventOrientation uint16 = 6 // update orientation of touch point
+)
+
+// wl_touch event since versions
+const (
+	WlTouchEventShapeSince       = 6
+	WlTouchEventOrientationSince = 6
+)
+
+// SendWlTouchDown sends a wl_touch.down event: touch down event and beginning of a touch sequence
+func SendWlTouchDown(wsc *WaylandServerConn, id uint32, serial uint32, time uint32, surface uint32, idArg int32, x float32, y float32) {
+	pb := NewPacketBuilder(id, WlTouchEventDown)
+	pb.WithUint(serial)
+	pb.WithUint(time)
+	pb.WithUint(surface)
+	pb.WithInt(int32(idArg))
+	pb.WithFixed(x)
+	pb.WithFixed(y)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchUp sends a wl_touch.up event: end of a touch event sequence
+func SendWlTouchUp(wsc *WaylandServerConn, id uint32, serial uint32, time uint32, idArg int32) {
+	pb := NewPacketBuilder(id, WlTouchEventUp)
+	pb.WithUint(serial)
+	pb.WithUint(time)
+	pb.WithInt(int32(idArg))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchMotion sends a wl_touch.motion event: update of touch point coordinates
+func SendWlTouchMotion(wsc *WaylandServerConn, id uint32, time uint32, idArg int32, x float32, y float32) {
+	pb := NewPacketBuilder(id, WlTouchEventMotion)
+	pb.WithUint(time)
+	pb.WithInt(int32(idArg))
+	pb.WithFixed(x)
+	pb.WithFixed(y)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchFrame sends a wl_touch.frame event: end of touch frame event
+func SendWlTouchFrame(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlTouchEventFrame)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchCancel sends a wl_touch.cancel event: touch session cancelled
+func SendWlTouchCancel(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, WlTouchEventCancel)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchShape sends a wl_touch.shape event: update shape of touch point
+func SendWlTouchShape(wsc *WaylandServerConn, id uint32, idArg int32, major float32, minor float32) {
+	if wsc.registry.Version(id) < WlTouchEventShapeSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlTouchEventShape)
+	pb.WithInt(int32(idArg))
+	pb.WithFixed(major)
+	pb.WithFixed(minor)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlTouchOrientation sends a wl_touch.orientation event: update orientation of touch point
+func SendWlTouchOrientation(wsc *WaylandServerConn, id uint32, idArg int32, orientation float32) {
+	if wsc.registry.Version(id) < WlTouchEventOrientationSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlTouchEventOrientation)
+	pb.WithInt(int32(idArg))
+	pb.WithFixed(orientation)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_output: compositor output region
+const (
+	WlOutputInterface = "wl_output"
+	WlOutputVersion   = 4
+)
+
+// wl_output request opcodes
+const (
+	WlOutputRequestRelease uint16 = 0 // release the output object
+)
+
+// wl_output request since versions
+const (
+	WlOutputRequestReleaseSince = 3
+)
+
+// wl_output event opcodes
+const (
+	WlOutputEventGeometry    uint16 = 0 // properties of the output
+	WlOutputEventMode        uint16 = 1 // advertise available modes for the output
+	WlOutputEventDone        uint16 = 2 // sent all information about output
+	WlOutputEventScale       uint16 = 3 // output scaling properties
+	WlOutputEventName        uint16 = 4 // name of this output
+	WlOutputEventDescription uint16 = 5 // human-readable description of this output
+)
+
+// wl_output event since versions
+const (
+	WlOutputEventDoneSince        = 2
+	WlOutputEventScaleSince       = 2
+	WlOutputEventNameSince        = 4
+	WlOutputEventDescriptionSince = 4
+)
+
+// WlOutputSubpixel: subpixel geometry information
+type WlOutputSubpixel uint32
+
+const (
+	WlOutputSubpixelUnknown       WlOutputSubpixel = 0 // unknown geometry
+	WlOutputSubpixelNone          WlOutputSubpixel = 1 // no geometry
+	WlOutputSubpixelHorizontalRgb WlOutputSubpixel = 2 // horizontal RGB
+	WlOutputSubpixelHorizontalBgr WlOutputSubpixel = 3 // horizontal BGR
+	WlOutputSubpixelVerticalRgb   WlOutputSubpixel = 4 // vertical RGB
+	WlOutputSubpixelVerticalBgr   WlOutputSubpixel = 5 // vertical BGR
+)
+
+// WlOutputTransform: transformation applied to buffer contents
+type WlOutputTransform uint32
+
+const (
+	WlOutputTransformNormal     WlOutputTransform = 0 // no transform
+	WlOutputTransform90         WlOutputTransform = 1 // 90 degrees counter-clockwise
+	WlOutputTransform180        WlOutputTransform = 2 // 180 degrees counter-clockwise
+	WlOutputTransform270        WlOutputTransform = 3 // 270 degrees counter-clockwise
+	WlOutputTransformFlipped    WlOutputTransform = 4 // 180 degree flip around a vertical axis
+	WlOutputTransformFlipped90  WlOutputTransform = 5 // flip and rotate 90 degrees counter-clockwise
+	WlOutputTransformFlipped180 WlOutputTransform = 6 // flip and rotate 180 degrees counter-clockwise
+	WlOutputTransformFlipped270 WlOutputTransform = 7 // flip and rotate 270 degrees counter-clockwise
+)
+
+// WlOutputMode: mode information
+type WlOutputMode uint32
+
+const (
+	WlOutputModeCurrent   WlOutputMode = 0x1 // indicates this is the current mode
+	WlOutputModePreferred WlOutputMode = 0x2 // indicates this is the preferred mode
+)
+
+// SendWlOutputGeometry sends a wl_output.geometry event: properties of the output
+func SendWlOutputGeometry(wsc *WaylandServerConn, id uint32, x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel WlOutputSubpixel, make string, model string, transform WlOutputTransform) {
+	pb := NewPacketBuilder(id, WlOutputEventGeometry)
+	pb.WithInt(int32(x))
+	pb.WithInt(int32(y))
+	pb.WithInt(int32(physicalWidth))
+	pb.WithInt(int32(physicalHeight))
+	pb.WithInt(int32(subpixel))
+	pb.WithString(make)
+	pb.WithString(model)
+	pb.WithInt(int32(transform))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlOutputMode sends a wl_output.mode event: advertise available modes for the output
+func SendWlOutputMode(wsc *WaylandServerConn, id uint32, flags WlOutputMode, width int32, height int32, refresh int32) {
+	pb := NewPacketBuilder(id, WlOutputEventMode)
+	pb.WithUint(uint32(flags))
+	pb.WithInt(int32(width))
+	pb.WithInt(int32(height))
+	pb.WithInt(int32(refresh))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlOutputDone sends a wl_output.done event: sent all information about output
+func SendWlOutputDone(wsc *WaylandServerConn, id uint32) {
+	if wsc.registry.Version(id) < WlOutputEventDoneSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlOutputEventDone)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlOutputScale sends a wl_output.scale event: output scaling properties
+func SendWlOutputScale(wsc *WaylandServerConn, id uint32, factor int32) {
+	if wsc.registry.Version(id) < WlOutputEventScaleSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlOutputEventScale)
+	pb.WithInt(int32(factor))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlOutputName sends a wl_output.name event: name of this output
+func SendWlOutputName(wsc *WaylandServerConn, id uint32, name string) {
+	if wsc.registry.Version(id) < WlOutputEventNameSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlOutputEventName)
+	pb.WithString(name)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendWlOutputDescription sends a wl_output.description event: human-readable description of this output
+func SendWlOutputDescription(wsc *WaylandServerConn, id uint32, description string) {
+	if wsc.registry.Version(id) < WlOutputEventDescriptionSince {
+		return
+	}
+	pb := NewPacketBuilder(id, WlOutputEventDescription)
+	pb.WithString(description)
+	wsc.SendMessage(pb.Build())
+}
+
+// wl_region: region interface
+const (
+	WlRegionInterface = "wl_region"
+	WlRegionVersion   = 1
+)
+
+// wl_region request opcodes
+const (
+	WlRegionRequestDestroy  uint16 = 0 // destroy region
+	WlRegionRequestAdd      uint16 = 1 // add rectangle to region
+	WlRegionRequestSubtract uint16 = 2 // subtract rectangle from region
+)
+
+// WlRegionAddRequest holds the arguments of a wl_region.add request
+type WlRegionAddRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseWlRegionAddRequest decodes a wl_region.add request
+func ParseWlRegionAddRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlRegionAddRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_region.add#%d: %v", packet.Address, err)
+	}
+	return &WlRegionAddRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// WlRegionSubtractRequest holds the arguments of a wl_region.subtract request
+type WlRegionSubtractRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseWlRegionSubtractRequest decodes a wl_region.subtract request
+func ParseWlRegionSubtractRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlRegionSubtractRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_region.subtract#%d: %v", packet.Address, err)
+	}
+	return &WlRegionSubtractRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// wl_subcompositor: sub-surface compositing
+const (
+	WlSubcompositorInterface = "wl_subcompositor"
+	WlSubcompositorVersion   = 1
+)
+
+// wl_subcompositor request opcodes
+const (
+	WlSubcompositorRequestDestroy       uint16 = 0 // unbind from the subcompositor interface
+	WlSubcompositorRequestGetSubsurface uint16 = 1 // give a surface the role sub-surface
+)
+
+// WlSubcompositorError:
+type WlSubcompositorError uint32
+
+const (
+	WlSubcompositorErrorBadSurface WlSubcompositorError = 0 // the to-be sub-surface is invalid
+	WlSubcompositorErrorBadParent  WlSubcompositorError = 1 // the to-be sub-surface parent is invalid
+)
+
+// WlSubcompositorGetSubsurfaceRequest holds the arguments of a wl_subcompositor.get_subsurface request
+type WlSubcompositorGetSubsurfaceRequest struct {
+	Id      uint32
+	Surface uint32
+	Parent  uint32
+}
+
+// ParseWlSubcompositorGetSubsurfaceRequest decodes a wl_subcompositor.get_subsurface request
+func ParseWlSubcompositorGetSubsurfaceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSubcompositorGetSubsurfaceRequest, error) {
+	idArg := NewObjectField()
+	surface := NewObjectField()
+	parent := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, surface, parent); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_subcompositor.get_subsurface#%d: %v", packet.Address, err)
+	}
+	return &WlSubcompositorGetSubsurfaceRequest{
+		Id:      uint32(*idArg),
+		Surface: uint32(*surface),
+		Parent:  uint32(*parent),
+	}, nil
+}
+
+// wl_subsurface: sub-surface interface to a wl_surface
+const (
+	WlSubsurfaceInterface = "wl_subsurface"
+	WlSubsurfaceVersion   = 1
+)
+
+// wl_subsurface request opcodes
+const (
+	WlSubsurfaceRequestDestroy     uint16 = 0 // remove sub-surface interface
+	WlSubsurfaceRequestSetPosition uint16 = 1 // reposition the sub-surface
+	WlSubsurfaceRequestPlaceAbove  uint16 = 2 // restack the sub-surface
+	WlSubsurfaceRequestPlaceBelow  uint16 = 3 // restack the sub-surface
+	WlSubsurfaceRequestSetSync     uint16 = 4 // set sub-surface to synchronized mode
+	WlSubsurfaceRequestSetDesync   uint16 = 5 // set sub-surface to desynchronized mode
+)
+
+// WlSubsurfaceError:
+type WlSubsurfaceError uint32
+
+const (
+	WlSubsurfaceErrorBadSurface WlSubsurfaceError = 0 // wl_surface is not a sibling or the parent
+)
+
+// WlSubsurfaceSetPositionRequest holds the arguments of a wl_subsurface.set_position request
+type WlSubsurfaceSetPositionRequest struct {
+	X int32
+	Y int32
+}
+
+// ParseWlSubsurfaceSetPositionRequest decodes a wl_subsurface.set_position request
+func ParseWlSubsurfaceSetPositionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSubsurfaceSetPositionRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_subsurface.set_position#%d: %v", packet.Address, err)
+	}
+	return &WlSubsurfaceSetPositionRequest{
+		X: int32(*x),
+		Y: int32(*y),
+	}, nil
+}
+
+// WlSubsurfacePlaceAboveRequest holds the arguments of a wl_subsurface.place_above request
+type WlSubsurfacePlaceAboveRequest struct {
+	Sibling uint32
+}
+
+// ParseWlSubsurfacePlaceAboveRequest decodes a wl_subsurface.place_above request
+func ParseWlSubsurfacePlaceAboveRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSubsurfacePlaceAboveRequest, error) {
+	sibling := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, sibling); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_subsurface.place_above#%d: %v", packet.Address, err)
+	}
+	return &WlSubsurfacePlaceAboveRequest{
+		Sibling: uint32(*sibling),
+	}, nil
+}
+
+// WlSubsurfacePlaceBelowRequest holds the arguments of a wl_subsurface.place_below request
+type WlSubsurfacePlaceBelowRequest struct {
+	Sibling uint32
+}
+
+// ParseWlSubsurfacePlaceBelowRequest decodes a wl_subsurface.place_below request
+func ParseWlSubsurfacePlaceBelowRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*WlSubsurfacePlaceBelowRequest, error) {
+	sibling := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, sibling); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for wl_subsurface.place_below#%d: %v", packet.Address, err)
+	}
+	return &WlSubsurfacePlaceBelowRequest{
+		Sibling: uint32(*sibling),
+	}, nil
+}
+
+func init() {
+	registerInterfaces(
+		&InterfaceSpec{Name: WlDisplayInterface, Version: WlDisplayVersion,
+			Requests: []MessageSpec{
+				{Name: "sync", Args: []ArgSpec{{Name: "callback", Type: "new_id", Interface: "wl_callback"}}},
+				{Name: "get_registry", Args: []ArgSpec{{Name: "registry", Type: "new_id", Interface: "wl_registry"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "error", Args: []ArgSpec{{Name: "object_id", Type: "object"}, {Name: "code", Type: "uint"}, {Name: "message", Type: "string"}}},
+				{Name: "delete_id", Args: []ArgSpec{{Name: "id", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlRegistryInterface, Version: WlRegistryVersion,
+			Requests: []MessageSpec{
+				{Name: "bind", Args: []ArgSpec{{Name: "name", Type: "uint"}, {Name: "id", Type: "new_id"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "global", Args: []ArgSpec{{Name: "name", Type: "uint"}, {Name: "interface", Type: "string"}, {Name: "version", Type: "uint"}}},
+				{Name: "global_remove", Args: []ArgSpec{{Name: "name", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlCallbackInterface, Version: WlCallbackVersion,
+			Events: []MessageSpec{
+				{Name: "done", Args: []ArgSpec{{Name: "callback_data", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlCompositorInterface, Version: WlCompositorVersion,
+			Requests: []MessageSpec{
+				{Name: "create_surface", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_surface"}}},
+				{Name: "create_region", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_region"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlShmPoolInterface, Version: WlShmPoolVersion,
+			Requests: []MessageSpec{
+				{Name: "create_buffer", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_buffer"}, {Name: "offset", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}, {Name: "stride", Type: "int"}, {Name: "format", Type: "uint"}}},
+				{Name: "destroy"},
+				{Name: "resize", Args: []ArgSpec{{Name: "size", Type: "int"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlShmInterface, Version: WlShmVersion,
+			Requests: []MessageSpec{
+				{Name: "create_pool", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_shm_pool"}, {Name: "fd", Type: "fd"}, {Name: "size", Type: "int"}}},
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "format", Args: []ArgSpec{{Name: "format", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlBufferInterface, Version: WlBufferVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+			},
+			Events: []MessageSpec{
+				{Name: "release"},
+			},
+		},
+		&InterfaceSpec{Name: WlDataOfferInterface, Version: WlDataOfferVersion,
+			Requests: []MessageSpec{
+				{Name: "accept", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "mime_type", Type: "string", AllowNull: true}}},
+				{Name: "receive", Args: []ArgSpec{{Name: "mime_type", Type: "string"}, {Name: "fd", Type: "fd"}}},
+				{Name: "destroy"},
+				{Name: "finish"},
+				{Name: "set_actions", Args: []ArgSpec{{Name: "dnd_actions", Type: "uint"}, {Name: "preferred_action", Type: "uint"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "offer", Args: []ArgSpec{{Name: "mime_type", Type: "string"}}},
+				{Name: "source_actions", Args: []ArgSpec{{Name: "source_actions", Type: "uint"}}},
+				{Name: "action", Args: []ArgSpec{{Name: "dnd_action", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlDataSourceInterface, Version: WlDataSourceVersion,
+			Requests: []MessageSpec{
+				{Name: "offer", Args: []ArgSpec{{Name: "mime_type", Type: "string"}}},
+				{Name: "destroy"},
+				{Name: "set_actions", Args: []ArgSpec{{Name: "dnd_actions", Type: "uint"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "target", Args: []ArgSpec{{Name: "mime_type", Type: "string", AllowNull: true}}},
+				{Name: "send", Args: []ArgSpec{{Name: "mime_type", Type: "string"}, {Name: "fd", Type: "fd"}}},
+				{Name: "cancelled"},
+				{Name: "dnd_drop_performed"},
+				{Name: "dnd_finished"},
+				{Name: "action", Args: []ArgSpec{{Name: "dnd_action", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlDataDeviceInterface, Version: WlDataDeviceVersion,
+			Requests: []MessageSpec{
+				{Name: "start_drag", Args: []ArgSpec{{Name: "source", Type: "object", Interface: "wl_data_source", AllowNull: true}, {Name: "origin", Type: "object", Interface: "wl_surface"}, {Name: "icon", Type: "object", Interface: "wl_surface", AllowNull: true}, {Name: "serial", Type: "uint"}}},
+				{Name: "set_selection", Args: []ArgSpec{{Name: "source", Type: "object", Interface: "wl_data_source", AllowNull: true}, {Name: "serial", Type: "uint"}}},
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "data_offer", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_data_offer"}}},
+				{Name: "enter", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}, {Name: "x", Type: "fixed"}, {Name: "y", Type: "fixed"}, {Name: "id", Type: "object", Interface: "wl_data_offer", AllowNull: true}}},
+				{Name: "leave"},
+				{Name: "motion", Args: []ArgSpec{{Name: "time", Type: "uint"}, {Name: "x", Type: "fixed"}, {Name: "y", Type: "fixed"}}},
+				{Name: "drop"},
+				{Name: "selection", Args: []ArgSpec{{Name: "id", Type: "object", Interface: "wl_data_offer", AllowNull: true}}},
+			},
+		},
+		&InterfaceSpec{Name: WlDataDeviceManagerInterface, Version: WlDataDeviceManagerVersion,
+			Requests: []MessageSpec{
+				{Name: "create_data_source", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_data_source"}}},
+				{Name: "get_data_device", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_data_device"}, {Name: "seat", Type: "object", Interface: "wl_seat"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlShellInterface, Version: WlShellVersion,
+			Requests: []MessageSpec{
+				{Name: "get_shell_surface", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_shell_surface"}, {Name: "surface", Type: "object", Interface: "wl_surface"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlShellSurfaceInterface, Version: WlShellSurfaceVersion,
+			Requests: []MessageSpec{
+				{Name: "pong", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+				{Name: "move", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}}},
+				{Name: "resize", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}, {Name: "edges", Type: "uint"}}},
+				{Name: "set_toplevel"},
+				{Name: "set_transient", Args: []ArgSpec{{Name: "parent", Type: "object", Interface: "wl_surface"}, {Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "flags", Type: "uint"}}},
+				{Name: "set_fullscreen", Args: []ArgSpec{{Name: "method", Type: "uint"}, {Name: "framerate", Type: "uint"}, {Name: "output", Type: "object", Interface: "wl_output", AllowNull: true}}},
+				{Name: "set_popup", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}, {Name: "parent", Type: "object", Interface: "wl_surface"}, {Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "flags", Type: "uint"}}},
+				{Name: "set_maximized", Args: []ArgSpec{{Name: "output", Type: "object", Interface: "wl_output", AllowNull: true}}},
+				{Name: "set_title", Args: []ArgSpec{{Name: "title", Type: "string"}}},
+				{Name: "set_class", Args: []ArgSpec{{Name: "class_", Type: "string"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "ping", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+				{Name: "configure", Args: []ArgSpec{{Name: "edges", Type: "uint"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "popup_done"},
+			},
+		},
+		&InterfaceSpec{Name: WlSurfaceInterface, Version: WlSurfaceVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "attach", Args: []ArgSpec{{Name: "buffer", Type: "object", Interface: "wl_buffer", AllowNull: true}, {Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+				{Name: "damage", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "frame", Args: []ArgSpec{{Name: "callback", Type: "new_id", Interface: "wl_callback"}}},
+				{Name: "set_opaque_region", Args: []ArgSpec{{Name: "region", Type: "object", Interface: "wl_region", AllowNull: true}}},
+				{Name: "set_input_region", Args: []ArgSpec{{Name: "region", Type: "object", Interface: "wl_region", AllowNull: true}}},
+				{Name: "commit"},
+				{Name: "set_buffer_transform", Args: []ArgSpec{{Name: "transform", Type: "int"}}},
+				{Name: "set_buffer_scale", Args: []ArgSpec{{Name: "scale", Type: "int"}}},
+				{Name: "damage_buffer", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "offset", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "enter", Args: []ArgSpec{{Name: "output", Type: "object", Interface: "wl_output"}}},
+				{Name: "leave", Args: []ArgSpec{{Name: "output", Type: "object", Interface: "wl_output"}}},
+				{Name: "preferred_buffer_scale", Args: []ArgSpec{{Name: "factor", Type: "int"}}},
+				{Name: "preferred_buffer_transform", Args: []ArgSpec{{Name: "transform", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlSeatInterface, Version: WlSeatVersion,
+			Requests: []MessageSpec{
+				{Name: "get_pointer", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_pointer"}}},
+				{Name: "get_keyboard", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_keyboard"}}},
+				{Name: "get_touch", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_touch"}}},
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "capabilities", Args: []ArgSpec{{Name: "capabilities", Type: "uint"}}},
+				{Name: "name", Args: []ArgSpec{{Name: "name", Type: "string"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlPointerInterface, Version: WlPointerVersion,
+			Requests: []MessageSpec{
+				{Name: "set_cursor", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface", AllowNull: true}, {Name: "hotspot_x", Type: "int"}, {Name: "hotspot_y", Type: "int"}}},
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "enter", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}, {Name: "surface_x", Type: "fixed"}, {Name: "surface_y", Type: "fixed"}}},
+				{Name: "leave", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}}},
+				{Name: "motion", Args: []ArgSpec{{Name: "time", Type: "uint"}, {Name: "surface_x", Type: "fixed"}, {Name: "surface_y", Type: "fixed"}}},
+				{Name: "button", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "time", Type: "uint"}, {Name: "button", Type: "uint"}, {Name: "state", Type: "uint"}}},
+				{Name: "axis", Args: []ArgSpec{{Name: "time", Type: "uint"}, {Name: "axis", Type: "uint"}, {Name: "value", Type: "fixed"}}},
+				{Name: "frame"},
+				{Name: "axis_source", Args: []ArgSpec{{Name: "axis_source", Type: "uint"}}},
+				{Name: "axis_stop", Args: []ArgSpec{{Name: "time", Type: "uint"}, {Name: "axis", Type: "uint"}}},
+				{Name: "axis_discrete", Args: []ArgSpec{{Name: "axis", Type: "uint"}, {Name: "discrete", Type: "int"}}},
+				{Name: "axis_value120", Args: []ArgSpec{{Name: "axis", Type: "uint"}, {Name: "value120", Type: "int"}}},
+				{Name: "axis_relative_direction", Args: []ArgSpec{{Name: "axis", Type: "uint"}, {Name: "direction", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlKeyboardInterface, Version: WlKeyboardVersion,
+			Requests: []MessageSpec{
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "keymap", Args: []ArgSpec{{Name: "format", Type: "uint"}, {Name: "fd", Type: "fd"}, {Name: "size", Type: "uint"}}},
+				{Name: "enter", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}, {Name: "keys", Type: "array"}}},
+				{Name: "leave", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}}},
+				{Name: "key", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "time", Type: "uint"}, {Name: "key", Type: "uint"}, {Name: "state", Type: "uint"}}},
+				{Name: "modifiers", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "mods_depressed", Type: "uint"}, {Name: "mods_latched", Type: "uint"}, {Name: "mods_locked", Type: "uint"}, {Name: "group", Type: "uint"}}},
+				{Name: "repeat_info", Args: []ArgSpec{{Name: "rate", Type: "int"}, {Name: "delay", Type: "int"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlTouchInterface, Version: WlTouchVersion,
+			Requests: []MessageSpec{
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "down", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "time", Type: "uint"}, {Name: "surface", Type: "object", Interface: "wl_surface"}, {Name: "id", Type: "int"}, {Name: "x", Type: "fixed"}, {Name: "y", Type: "fixed"}}},
+				{Name: "up", Args: []ArgSpec{{Name: "serial", Type: "uint"}, {Name: "time", Type: "uint"}, {Name: "id", Type: "int"}}},
+				{Name: "motion", Args: []ArgSpec{{Name: "time", Type: "uint"}, {Name: "id", Type: "int"}, {Name: "x", Type: "fixed"}, {Name: "y", Type: "fixed"}}},
+				{Name: "frame"},
+				{Name: "cancel"},
+				{Name: "shape", Args: []ArgSpec{{Name: "id", Type: "int"}, {Name: "major", Type: "fixed"}, {Name: "minor", Type: "fixed"}}},
+				{Name: "orientation", Args: []ArgSpec{{Name: "id", Type: "int"}, {Name: "orientation", Type: "fixed"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlOutputInterface, Version: WlOutputVersion,
+			Requests: []MessageSpec{
+				{Name: "release"},
+			},
+			Events: []MessageSpec{
+				{Name: "geometry", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "physical_width", Type: "int"}, {Name: "physical_height", Type: "int"}, {Name: "subpixel", Type: "int"}, {Name: "make", Type: "string"}, {Name: "model", Type: "string"}, {Name: "transform", Type: "int"}}},
+				{Name: "mode", Args: []ArgSpec{{Name: "flags", Type: "uint"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}, {Name: "refresh", Type: "int"}}},
+				{Name: "done"},
+				{Name: "scale", Args: []ArgSpec{{Name: "factor", Type: "int"}}},
+				{Name: "name", Args: []ArgSpec{{Name: "name", Type: "string"}}},
+				{Name: "description", Args: []ArgSpec{{Name: "description", Type: "string"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlRegionInterface, Version: WlRegionVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "add", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "subtract", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlSubcompositorInterface, Version: WlSubcompositorVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "get_subsurface", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "wl_subsurface"}, {Name: "surface", Type: "object", Interface: "wl_surface"}, {Name: "parent", Type: "object", Interface: "wl_surface"}}},
+			},
+		},
+		&InterfaceSpec{Name: WlSubsurfaceInterface, Version: WlSubsurfaceVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "set_position", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+				{Name: "place_above", Args: []ArgSpec{{Name: "sibling", Type: "object", Interface: "wl_surface"}}},
+				{Name: "place_below", Args: []ArgSpec{{Name: "sibling", Type: "object", Interface: "wl_surface"}}},
+				{Name: "set_sync"},
+				{Name: "set_desync"},
+			},
+		},
+	)
+}
@@ -0,0 +1,904 @@
+// Code generated by wayland/scanner from xdg-shell.xml. DO NOT EDIT.
+
+package wayland
+
+// xdg_wm_base: create desktop-style surfaces
+const (
+	XdgWmBaseInterface = "xdg_wm_base"
+	XdgWmBaseVersion   = 6
+)
+
+// xdg_wm_base request opcodes
+const (
+	XdgWmBaseRequestDestroy          uint16 = 0 // destroy xdg_wm_base
+	XdgWmBaseRequestCreatePositioner uint16 = 1 // create a positioner object
+	XdgWmBaseRequestGetXdgSurface    uint16 = 2 // create a shell surface from a surface
+	XdgWmBaseRequestPong             uint16 = 3 // respond to a ping event
+)
+
+// xdg_wm_base event opcodes
+const (
+	XdgWmBaseEventPing uint16 = 0 // check if the client is alive
+)
+
+// XdgWmBaseError:
+type XdgWmBaseError uint32
+
+const (
+	XdgWmBaseErrorRole                XdgWmBaseError = 0 // given wl_surface has another role
+	XdgWmBaseErrorDefunctSurfaces     XdgWmBaseError = 1 // xdg_wm_base was destroyed before children
+	XdgWmBaseErrorNotTheTopmostPopup  XdgWmBaseError = 2 // the client tried to map or destroy a non-topmost popup
+	XdgWmBaseErrorInvalidPopupParent  XdgWmBaseError = 3 // the client specified an invalid popup parent surface
+	XdgWmBaseErrorInvalidSurfaceState XdgWmBaseError = 4 // the client provided an invalid surface state
+	XdgWmBaseErrorInvalidPositioner   XdgWmBaseError = 5 // the client provided an invalid positioner
+	XdgWmBaseErrorUnresponsive        XdgWmBaseError = 6 // the client didn’t respond to a ping event in time
+)
+
+// XdgWmBaseCreatePositionerRequest holds the arguments of a xdg_wm_base.create_positioner request
+type XdgWmBaseCreatePositionerRequest struct {
+	Id uint32
+}
+
+// ParseXdgWmBaseCreatePositionerRequest decodes a xdg_wm_base.create_positioner request
+func ParseXdgWmBaseCreatePositionerRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgWmBaseCreatePositionerRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_wm_base.create_positioner#%d: %v", packet.Address, err)
+	}
+	return &XdgWmBaseCreatePositionerRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// XdgWmBaseGetXdgSurfaceRequest holds the arguments of a xdg_wm_base.get_xdg_surface request
+type XdgWmBaseGetXdgSurfaceRequest struct {
+	Id      uint32
+	Surface uint32
+}
+
+// ParseXdgWmBaseGetXdgSurfaceRequest decodes a xdg_wm_base.get_xdg_surface request
+func ParseXdgWmBaseGetXdgSurfaceRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgWmBaseGetXdgSurfaceRequest, error) {
+	idArg := NewObjectField()
+	surface := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, surface); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_wm_base.get_xdg_surface#%d: %v", packet.Address, err)
+	}
+	return &XdgWmBaseGetXdgSurfaceRequest{
+		Id:      uint32(*idArg),
+		Surface: uint32(*surface),
+	}, nil
+}
+
+// XdgWmBasePongRequest holds the arguments of a xdg_wm_base.pong request
+type XdgWmBasePongRequest struct {
+	Serial uint32
+}
+
+// ParseXdgWmBasePongRequest decodes a xdg_wm_base.pong request
+func ParseXdgWmBasePongRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgWmBasePongRequest, error) {
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_wm_base.pong#%d: %v", packet.Address, err)
+	}
+	return &XdgWmBasePongRequest{
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// SendXdgWmBasePing sends a xdg_wm_base.ping event: check if the client is alive
+func SendXdgWmBasePing(wsc *WaylandServerConn, id uint32, serial uint32) {
+	pb := NewPacketBuilder(id, XdgWmBaseEventPing)
+	pb.WithUint(serial)
+	wsc.SendMessage(pb.Build())
+}
+
+// xdg_positioner: child surface positioner
+const (
+	XdgPositionerInterface = "xdg_positioner"
+	XdgPositionerVersion   = 6
+)
+
+// xdg_positioner request opcodes
+const (
+	XdgPositionerRequestDestroy                 uint16 = 0 // destroy the xdg_positioner object
+	XdgPositionerRequestSetSize                 uint16 = 1 // set the size of the to-be positioned rectangle
+	XdgPositionerRequestSetAnchorRect           uint16 = 2 // set the anchor rectangle within the parent surface
+	XdgPositionerRequestSetAnchor               uint16 = 3 // set anchor rectangle anchor
+	XdgPositionerRequestSetGravity              uint16 = 4 // set child surface gravity
+	XdgPositionerRequestSetConstraintAdjustment uint16 = 5 // set the adjustment to be done when constrained
+	XdgPositionerRequestSetOffset               uint16 = 6 // set surface position offset
+	XdgPositionerRequestSetReactive             uint16 = 7 // continuously reconstrain the surface
+	XdgPositionerRequestSetParentSize           uint16 = 8
+	XdgPositionerRequestSetParentConfigure      uint16 = 9 // set parent configure this is a response to
+)
+
+// xdg_positioner request since versions
+const (
+	XdgPositionerRequestSetReactiveSince        = 3
+	XdgPositionerRequestSetParentSizeSince      = 3
+	XdgPositionerRequestSetParentConfigureSince = 3
+)
+
+// XdgPositionerError:
+type XdgPositionerError uint32
+
+const (
+	XdgPositionerErrorInvalidInput XdgPositionerError = 0 // invalid input provided
+)
+
+// XdgPositionerAnchor:
+type XdgPositionerAnchor uint32
+
+const (
+	XdgPositionerAnchorNone        XdgPositionerAnchor = 0
+	XdgPositionerAnchorTop         XdgPositionerAnchor = 1
+	XdgPositionerAnchorBottom      XdgPositionerAnchor = 2
+	XdgPositionerAnchorLeft        XdgPositionerAnchor = 3
+	XdgPositionerAnchorRight       XdgPositionerAnchor = 4
+	XdgPositionerAnchorTopLeft     XdgPositionerAnchor = 5
+	XdgPositionerAnchorBottomLeft  XdgPositionerAnchor = 6
+	XdgPositionerAnchorTopRight    XdgPositionerAnchor = 7
+	XdgPositionerAnchorBottomRight XdgPositionerAnchor = 8
+)
+
+// XdgPositionerGravity:
+type XdgPositionerGravity uint32
+
+const (
+	XdgPositionerGravityNone        XdgPositionerGravity = 0
+	XdgPositionerGravityTop         XdgPositionerGravity = 1
+	XdgPositionerGravityBottom      XdgPositionerGravity = 2
+	XdgPositionerGravityLeft        XdgPositionerGravity = 3
+	XdgPositionerGravityRight       XdgPositionerGravity = 4
+	XdgPositionerGravityTopLeft     XdgPositionerGravity = 5
+	XdgPositionerGravityBottomLeft  XdgPositionerGravity = 6
+	XdgPositionerGravityTopRight    XdgPositionerGravity = 7
+	XdgPositionerGravityBottomRight XdgPositionerGravity = 8
+)
+
+// XdgPositionerConstraintAdjustment: constraint adjustments
+type XdgPositionerConstraintAdjustment uint32
+
+const (
+	XdgPositionerConstraintAdjustmentNone    XdgPositionerConstraintAdjustment = 0
+	XdgPositionerConstraintAdjustmentSlideX  XdgPositionerConstraintAdjustment = 1
+	XdgPositionerConstraintAdjustmentSlideY  XdgPositionerConstraintAdjustment = 2
+	XdgPositionerConstraintAdjustmentFlipX   XdgPositionerConstraintAdjustment = 4
+	XdgPositionerConstraintAdjustmentFlipY   XdgPositionerConstraintAdjustment = 8
+	XdgPositionerConstraintAdjustmentResizeX XdgPositionerConstraintAdjustment = 16
+	XdgPositionerConstraintAdjustmentResizeY XdgPositionerConstraintAdjustment = 32
+)
+
+// XdgPositionerSetSizeRequest holds the arguments of a xdg_positioner.set_size request
+type XdgPositionerSetSizeRequest struct {
+	Width  int32
+	Height int32
+}
+
+// ParseXdgPositionerSetSizeRequest decodes a xdg_positioner.set_size request
+func ParseXdgPositionerSetSizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetSizeRequest, error) {
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_size#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetSizeRequest{
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// XdgPositionerSetAnchorRectRequest holds the arguments of a xdg_positioner.set_anchor_rect request
+type XdgPositionerSetAnchorRectRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseXdgPositionerSetAnchorRectRequest decodes a xdg_positioner.set_anchor_rect request
+func ParseXdgPositionerSetAnchorRectRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetAnchorRectRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_anchor_rect#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetAnchorRectRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// XdgPositionerSetAnchorRequest holds the arguments of a xdg_positioner.set_anchor request
+type XdgPositionerSetAnchorRequest struct {
+	Anchor XdgPositionerAnchor
+}
+
+// ParseXdgPositionerSetAnchorRequest decodes a xdg_positioner.set_anchor request
+func ParseXdgPositionerSetAnchorRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetAnchorRequest, error) {
+	anchor := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, anchor); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_anchor#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetAnchorRequest{
+		Anchor: XdgPositionerAnchor(*anchor),
+	}, nil
+}
+
+// XdgPositionerSetGravityRequest holds the arguments of a xdg_positioner.set_gravity request
+type XdgPositionerSetGravityRequest struct {
+	Gravity XdgPositionerGravity
+}
+
+// ParseXdgPositionerSetGravityRequest decodes a xdg_positioner.set_gravity request
+func ParseXdgPositionerSetGravityRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetGravityRequest, error) {
+	gravity := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, gravity); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_gravity#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetGravityRequest{
+		Gravity: XdgPositionerGravity(*gravity),
+	}, nil
+}
+
+// XdgPositionerSetConstraintAdjustmentRequest holds the arguments of a xdg_positioner.set_constraint_adjustment request
+type XdgPositionerSetConstraintAdjustmentRequest struct {
+	ConstraintAdjustment XdgPositionerConstraintAdjustment
+}
+
+// ParseXdgPositionerSetConstraintAdjustmentRequest decodes a xdg_positioner.set_constraint_adjustment request
+func ParseXdgPositionerSetConstraintAdjustmentRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetConstraintAdjustmentRequest, error) {
+	constraintAdjustment := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, constraintAdjustment); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_constraint_adjustment#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetConstraintAdjustmentRequest{
+		ConstraintAdjustment: XdgPositionerConstraintAdjustment(*constraintAdjustment),
+	}, nil
+}
+
+// XdgPositionerSetOffsetRequest holds the arguments of a xdg_positioner.set_offset request
+type XdgPositionerSetOffsetRequest struct {
+	X int32
+	Y int32
+}
+
+// ParseXdgPositionerSetOffsetRequest decodes a xdg_positioner.set_offset request
+func ParseXdgPositionerSetOffsetRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetOffsetRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_offset#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetOffsetRequest{
+		X: int32(*x),
+		Y: int32(*y),
+	}, nil
+}
+
+// XdgPositionerSetParentSizeRequest holds the arguments of a xdg_positioner.set_parent_size request
+type XdgPositionerSetParentSizeRequest struct {
+	ParentWidth  int32
+	ParentHeight int32
+}
+
+// ParseXdgPositionerSetParentSizeRequest decodes a xdg_positioner.set_parent_size request
+func ParseXdgPositionerSetParentSizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetParentSizeRequest, error) {
+	parentWidth := NewIntField()
+	parentHeight := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, parentWidth, parentHeight); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_parent_size#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetParentSizeRequest{
+		ParentWidth:  int32(*parentWidth),
+		ParentHeight: int32(*parentHeight),
+	}, nil
+}
+
+// XdgPositionerSetParentConfigureRequest holds the arguments of a xdg_positioner.set_parent_configure request
+type XdgPositionerSetParentConfigureRequest struct {
+	Serial uint32
+}
+
+// ParseXdgPositionerSetParentConfigureRequest decodes a xdg_positioner.set_parent_configure request
+func ParseXdgPositionerSetParentConfigureRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPositionerSetParentConfigureRequest, error) {
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_positioner.set_parent_configure#%d: %v", packet.Address, err)
+	}
+	return &XdgPositionerSetParentConfigureRequest{
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// xdg_surface: desktop user interface surface base interface
+const (
+	XdgSurfaceInterface = "xdg_surface"
+	XdgSurfaceVersion   = 6
+)
+
+// xdg_surface request opcodes
+const (
+	XdgSurfaceRequestDestroy           uint16 = 0 // destroy the xdg_surface
+	XdgSurfaceRequestGetToplevel       uint16 = 1 // assign the xdg_toplevel surface role
+	XdgSurfaceRequestGetPopup          uint16 = 2 // assign the xdg_popup surface role
+	XdgSurfaceRequestSetWindowGeometry uint16 = 3 // set the new window geometry
+	XdgSurfaceRequestAckConfigure      uint16 = 4 // ack a configure event
+)
+
+// xdg_surface event opcodes
+const (
+	XdgSurfaceEventConfigure uint16 = 0 // suggest a surface change
+)
+
+// XdgSurfaceError:
+type XdgSurfaceError uint32
+
+const (
+	XdgSurfaceErrorNotConstructed     XdgSurfaceError = 1 // Surface was not fully constructed
+	XdgSurfaceErrorAlreadyConstructed XdgSurfaceError = 2 // Surface was already constructed
+	XdgSurfaceErrorUnconfiguredBuffer XdgSurfaceError = 3 // Attaching a buffer to an unconfigured surface
+	XdgSurfaceErrorInvalidSerial      XdgSurfaceError = 4 // Invalid serial number when acking a configure event
+	XdgSurfaceErrorInvalidSize        XdgSurfaceError = 5 // Width or height was zero or negative
+	XdgSurfaceErrorDefunctRoleObject  XdgSurfaceError = 6 // Surface was destroyed before its role object
+)
+
+// XdgSurfaceGetToplevelRequest holds the arguments of a xdg_surface.get_toplevel request
+type XdgSurfaceGetToplevelRequest struct {
+	Id uint32
+}
+
+// ParseXdgSurfaceGetToplevelRequest decodes a xdg_surface.get_toplevel request
+func ParseXdgSurfaceGetToplevelRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgSurfaceGetToplevelRequest, error) {
+	idArg := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_surface.get_toplevel#%d: %v", packet.Address, err)
+	}
+	return &XdgSurfaceGetToplevelRequest{
+		Id: uint32(*idArg),
+	}, nil
+}
+
+// XdgSurfaceGetPopupRequest holds the arguments of a xdg_surface.get_popup request
+type XdgSurfaceGetPopupRequest struct {
+	Id         uint32
+	Parent     uint32
+	Positioner uint32
+}
+
+// ParseXdgSurfaceGetPopupRequest decodes a xdg_surface.get_popup request
+func ParseXdgSurfaceGetPopupRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgSurfaceGetPopupRequest, error) {
+	idArg := NewObjectField()
+	parent := NewNullableObjectField()
+	positioner := NewObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, idArg, parent, positioner); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_surface.get_popup#%d: %v", packet.Address, err)
+	}
+	return &XdgSurfaceGetPopupRequest{
+		Id:         uint32(*idArg),
+		Parent:     uint32(*parent),
+		Positioner: uint32(*positioner),
+	}, nil
+}
+
+// XdgSurfaceSetWindowGeometryRequest holds the arguments of a xdg_surface.set_window_geometry request
+type XdgSurfaceSetWindowGeometryRequest struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// ParseXdgSurfaceSetWindowGeometryRequest decodes a xdg_surface.set_window_geometry request
+func ParseXdgSurfaceSetWindowGeometryRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgSurfaceSetWindowGeometryRequest, error) {
+	x := NewIntField()
+	y := NewIntField()
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, x, y, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_surface.set_window_geometry#%d: %v", packet.Address, err)
+	}
+	return &XdgSurfaceSetWindowGeometryRequest{
+		X:      int32(*x),
+		Y:      int32(*y),
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// XdgSurfaceAckConfigureRequest holds the arguments of a xdg_surface.ack_configure request
+type XdgSurfaceAckConfigureRequest struct {
+	Serial uint32
+}
+
+// ParseXdgSurfaceAckConfigureRequest decodes a xdg_surface.ack_configure request
+func ParseXdgSurfaceAckConfigureRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgSurfaceAckConfigureRequest, error) {
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_surface.ack_configure#%d: %v", packet.Address, err)
+	}
+	return &XdgSurfaceAckConfigureRequest{
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// SendXdgSurfaceConfigure sends a xdg_surface.configure event: suggest a surface change
+func SendXdgSurfaceConfigure(wsc *WaylandServerConn, id uint32, serial uint32) {
+	pb := NewPacketBuilder(id, XdgSurfaceEventConfigure)
+	pb.WithUint(serial)
+	wsc.SendMessage(pb.Build())
+}
+
+// xdg_toplevel: toplevel surface
+const (
+	XdgToplevelInterface = "xdg_toplevel"
+	XdgToplevelVersion   = 6
+)
+
+// xdg_toplevel request opcodes
+const (
+	XdgToplevelRequestDestroy         uint16 = 0  // destroy the xdg_toplevel
+	XdgToplevelRequestSetParent       uint16 = 1  // set the parent of this surface
+	XdgToplevelRequestSetTitle        uint16 = 2  // set surface title
+	XdgToplevelRequestSetAppId        uint16 = 3  // set application ID
+	XdgToplevelRequestShowWindowMenu  uint16 = 4  // show the window menu
+	XdgToplevelRequestMove            uint16 = 5  // start an interactive move
+	XdgToplevelRequestResize          uint16 = 6  // start an interactive resize
+	XdgToplevelRequestSetMaxSize      uint16 = 7  // set the maximum size
+	XdgToplevelRequestSetMinSize      uint16 = 8  // set the minimum size
+	XdgToplevelRequestSetMaximized    uint16 = 9  // maximize the window
+	XdgToplevelRequestUnsetMaximized  uint16 = 10 // unmaximize the window
+	XdgToplevelRequestSetFullscreen   uint16 = 11 // set the window as fullscreen on an output
+	XdgToplevelRequestUnsetFullscreen uint16 = 12 // unset the window as fullscreen
+	XdgToplevelRequestSetMinimized    uint16 = 13 // set the window as minimized
+)
+
+// xdg_toplevel event opcodes
+const (
+	XdgToplevelEventConfigure       uint16 = 0 // suggest a surface change
+	XdgToplevelEventClose           uint16 = 1 // surface wants to be closed
+	XdgToplevelEventConfigureBounds uint16 = 2 // recommended window geometry bounds
+	XdgToplevelEventWmCapabilities  uint16 = 3 // compositor capabilities
+)
+
+// xdg_toplevel event since versions
+const (
+	XdgToplevelEventConfigureBoundsSince = 4
+	XdgToplevelEventWmCapabilitiesSince  = 5
+)
+
+// XdgToplevelError:
+type XdgToplevelError uint32
+
+const (
+	XdgToplevelErrorInvalidResizeEdge XdgToplevelError = 0 // provided value is not a valid variant of the resize_edge enum
+	XdgToplevelErrorInvalidParent     XdgToplevelError = 1 // invalid parent toplevel
+	XdgToplevelErrorInvalidSize       XdgToplevelError = 2 // client provided an invalid min or max size
+)
+
+// XdgToplevelResizeEdge: edge values for resizing
+type XdgToplevelResizeEdge uint32
+
+const (
+	XdgToplevelResizeEdgeNone        XdgToplevelResizeEdge = 0
+	XdgToplevelResizeEdgeTop         XdgToplevelResizeEdge = 1
+	XdgToplevelResizeEdgeBottom      XdgToplevelResizeEdge = 2
+	XdgToplevelResizeEdgeLeft        XdgToplevelResizeEdge = 4
+	XdgToplevelResizeEdgeTopLeft     XdgToplevelResizeEdge = 5
+	XdgToplevelResizeEdgeBottomLeft  XdgToplevelResizeEdge = 6
+	XdgToplevelResizeEdgeRight       XdgToplevelResizeEdge = 8
+	XdgToplevelResizeEdgeTopRight    XdgToplevelResizeEdge = 9
+	XdgToplevelResizeEdgeBottomRight XdgToplevelResizeEdge = 10
+)
+
+// XdgToplevelState: types of state on the surface
+type XdgToplevelState uint32
+
+const (
+	XdgToplevelStateMaximized   XdgToplevelState = 1 // the surface is maximized
+	XdgToplevelStateFullscreen  XdgToplevelState = 2 // the surface is fullscreen
+	XdgToplevelStateResizing    XdgToplevelState = 3 // the surface is being resized
+	XdgToplevelStateActivated   XdgToplevelState = 4 // the surface is now activated
+	XdgToplevelStateTiledLeft   XdgToplevelState = 5
+	XdgToplevelStateTiledRight  XdgToplevelState = 6
+	XdgToplevelStateTiledTop    XdgToplevelState = 7
+	XdgToplevelStateTiledBottom XdgToplevelState = 8
+	XdgToplevelStateSuspended   XdgToplevelState = 9
+)
+
+// XdgToplevelWmCapabilities:
+type XdgToplevelWmCapabilities uint32
+
+const (
+	XdgToplevelWmCapabilitiesWindowMenu XdgToplevelWmCapabilities = 1 // show_window_menu is available
+	XdgToplevelWmCapabilitiesMaximize   XdgToplevelWmCapabilities = 2 // set_maximized and unset_maximized are available
+	XdgToplevelWmCapabilitiesFullscreen XdgToplevelWmCapabilities = 3 // set_fullscreen and unset_fullscreen are available
+	XdgToplevelWmCapabilitiesMinimize   XdgToplevelWmCapabilities = 4 // set_minimized is available
+)
+
+// XdgToplevelSetParentRequest holds the arguments of a xdg_toplevel.set_parent request
+type XdgToplevelSetParentRequest struct {
+	Parent uint32
+}
+
+// ParseXdgToplevelSetParentRequest decodes a xdg_toplevel.set_parent request
+func ParseXdgToplevelSetParentRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetParentRequest, error) {
+	parent := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, parent); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_parent#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetParentRequest{
+		Parent: uint32(*parent),
+	}, nil
+}
+
+// XdgToplevelSetTitleRequest holds the arguments of a xdg_toplevel.set_title request
+type XdgToplevelSetTitleRequest struct {
+	Title string
+}
+
+// ParseXdgToplevelSetTitleRequest decodes a xdg_toplevel.set_title request
+func ParseXdgToplevelSetTitleRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetTitleRequest, error) {
+	title := NewStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, title); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_title#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetTitleRequest{
+		Title: string(*title),
+	}, nil
+}
+
+// XdgToplevelSetAppIdRequest holds the arguments of a xdg_toplevel.set_app_id request
+type XdgToplevelSetAppIdRequest struct {
+	AppId string
+}
+
+// ParseXdgToplevelSetAppIdRequest decodes a xdg_toplevel.set_app_id request
+func ParseXdgToplevelSetAppIdRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetAppIdRequest, error) {
+	appId := NewStringField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, appId); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_app_id#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetAppIdRequest{
+		AppId: string(*appId),
+	}, nil
+}
+
+// XdgToplevelShowWindowMenuRequest holds the arguments of a xdg_toplevel.show_window_menu request
+type XdgToplevelShowWindowMenuRequest struct {
+	Seat   uint32
+	Serial uint32
+	X      int32
+	Y      int32
+}
+
+// ParseXdgToplevelShowWindowMenuRequest decodes a xdg_toplevel.show_window_menu request
+func ParseXdgToplevelShowWindowMenuRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelShowWindowMenuRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	x := NewIntField()
+	y := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial, x, y); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.show_window_menu#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelShowWindowMenuRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+		X:      int32(*x),
+		Y:      int32(*y),
+	}, nil
+}
+
+// XdgToplevelMoveRequest holds the arguments of a xdg_toplevel.move request
+type XdgToplevelMoveRequest struct {
+	Seat   uint32
+	Serial uint32
+}
+
+// ParseXdgToplevelMoveRequest decodes a xdg_toplevel.move request
+func ParseXdgToplevelMoveRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelMoveRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.move#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelMoveRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// XdgToplevelResizeRequest holds the arguments of a xdg_toplevel.resize request
+type XdgToplevelResizeRequest struct {
+	Seat   uint32
+	Serial uint32
+	Edges  XdgToplevelResizeEdge
+}
+
+// ParseXdgToplevelResizeRequest decodes a xdg_toplevel.resize request
+func ParseXdgToplevelResizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelResizeRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	edges := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial, edges); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.resize#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelResizeRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+		Edges:  XdgToplevelResizeEdge(*edges),
+	}, nil
+}
+
+// XdgToplevelSetMaxSizeRequest holds the arguments of a xdg_toplevel.set_max_size request
+type XdgToplevelSetMaxSizeRequest struct {
+	Width  int32
+	Height int32
+}
+
+// ParseXdgToplevelSetMaxSizeRequest decodes a xdg_toplevel.set_max_size request
+func ParseXdgToplevelSetMaxSizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetMaxSizeRequest, error) {
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_max_size#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetMaxSizeRequest{
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// XdgToplevelSetMinSizeRequest holds the arguments of a xdg_toplevel.set_min_size request
+type XdgToplevelSetMinSizeRequest struct {
+	Width  int32
+	Height int32
+}
+
+// ParseXdgToplevelSetMinSizeRequest decodes a xdg_toplevel.set_min_size request
+func ParseXdgToplevelSetMinSizeRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetMinSizeRequest, error) {
+	width := NewIntField()
+	height := NewIntField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, width, height); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_min_size#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetMinSizeRequest{
+		Width:  int32(*width),
+		Height: int32(*height),
+	}, nil
+}
+
+// XdgToplevelSetFullscreenRequest holds the arguments of a xdg_toplevel.set_fullscreen request
+type XdgToplevelSetFullscreenRequest struct {
+	Output uint32
+}
+
+// ParseXdgToplevelSetFullscreenRequest decodes a xdg_toplevel.set_fullscreen request
+func ParseXdgToplevelSetFullscreenRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgToplevelSetFullscreenRequest, error) {
+	output := NewNullableObjectField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, output); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_toplevel.set_fullscreen#%d: %v", packet.Address, err)
+	}
+	return &XdgToplevelSetFullscreenRequest{
+		Output: uint32(*output),
+	}, nil
+}
+
+// SendXdgToplevelConfigure sends a xdg_toplevel.configure event: suggest a surface change
+func SendXdgToplevelConfigure(wsc *WaylandServerConn, id uint32, width int32, height int32, states []byte) {
+	pb := NewPacketBuilder(id, XdgToplevelEventConfigure)
+	pb.WithInt(int32(width))
+	pb.WithInt(int32(height))
+	pb.WithArray(states)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendXdgToplevelClose sends a xdg_toplevel.close event: surface wants to be closed
+func SendXdgToplevelClose(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, XdgToplevelEventClose)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendXdgToplevelConfigureBounds sends a xdg_toplevel.configure_bounds event: recommended window geometry bounds
+func SendXdgToplevelConfigureBounds(wsc *WaylandServerConn, id uint32, width int32, height int32) {
+	if wsc.registry.Version(id) < XdgToplevelEventConfigureBoundsSince {
+		return
+	}
+	pb := NewPacketBuilder(id, XdgToplevelEventConfigureBounds)
+	pb.WithInt(int32(width))
+	pb.WithInt(int32(height))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendXdgToplevelWmCapabilities sends a xdg_toplevel.wm_capabilities event: compositor capabilities
+func SendXdgToplevelWmCapabilities(wsc *WaylandServerConn, id uint32, capabilities []byte) {
+	if wsc.registry.Version(id) < XdgToplevelEventWmCapabilitiesSince {
+		return
+	}
+	pb := NewPacketBuilder(id, XdgToplevelEventWmCapabilities)
+	pb.WithArray(capabilities)
+	wsc.SendMessage(pb.Build())
+}
+
+// xdg_popup: short-lived, popup surfaces for menus
+const (
+	XdgPopupInterface = "xdg_popup"
+	XdgPopupVersion   = 6
+)
+
+// xdg_popup request opcodes
+const (
+	XdgPopupRequestDestroy    uint16 = 0 // remove xdg_popup interface
+	XdgPopupRequestGrab       uint16 = 1 // make the popup take an explicit grab
+	XdgPopupRequestReposition uint16 = 2 // recalculate the popup's location
+)
+
+// xdg_popup request since versions
+const (
+	XdgPopupRequestRepositionSince = 3
+)
+
+// xdg_popup event opcodes
+const (
+	XdgPopupEventConfigure    uint16 = 0 // configure the popup surface
+	XdgPopupEventPopupDone    uint16 = 1 // popup interaction is done
+	XdgPopupEventRepositioned uint16 = 2 // signal the completion of a repositioned request
+)
+
+// xdg_popup event since versions
+const (
+	XdgPopupEventRepositionedSince = 3
+)
+
+// XdgPopupError:
+type XdgPopupError uint32
+
+const (
+	XdgPopupErrorInvalidGrab XdgPopupError = 0 // tried to grab after being mapped
+)
+
+// XdgPopupGrabRequest holds the arguments of a xdg_popup.grab request
+type XdgPopupGrabRequest struct {
+	Seat   uint32
+	Serial uint32
+}
+
+// ParseXdgPopupGrabRequest decodes a xdg_popup.grab request
+func ParseXdgPopupGrabRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPopupGrabRequest, error) {
+	seat := NewObjectField()
+	serial := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, seat, serial); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_popup.grab#%d: %v", packet.Address, err)
+	}
+	return &XdgPopupGrabRequest{
+		Seat:   uint32(*seat),
+		Serial: uint32(*serial),
+	}, nil
+}
+
+// XdgPopupRepositionRequest holds the arguments of a xdg_popup.reposition request
+type XdgPopupRepositionRequest struct {
+	Positioner uint32
+	Token      uint32
+}
+
+// ParseXdgPopupRepositionRequest decodes a xdg_popup.reposition request
+func ParseXdgPopupRepositionRequest(wsc *WaylandServerConn, packet *WaylandMessage) (*XdgPopupRepositionRequest, error) {
+	positioner := NewObjectField()
+	token := NewUintField()
+	if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, positioner, token); err != nil {
+		return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, "invalid arguments for xdg_popup.reposition#%d: %v", packet.Address, err)
+	}
+	return &XdgPopupRepositionRequest{
+		Positioner: uint32(*positioner),
+		Token:      uint32(*token),
+	}, nil
+}
+
+// SendXdgPopupConfigure sends a xdg_popup.configure event: configure the popup surface
+func SendXdgPopupConfigure(wsc *WaylandServerConn, id uint32, x int32, y int32, width int32, height int32) {
+	pb := NewPacketBuilder(id, XdgPopupEventConfigure)
+	pb.WithInt(int32(x))
+	pb.WithInt(int32(y))
+	pb.WithInt(int32(width))
+	pb.WithInt(int32(height))
+	wsc.SendMessage(pb.Build())
+}
+
+// SendXdgPopupPopupDone sends a xdg_popup.popup_done event: popup interaction is done
+func SendXdgPopupPopupDone(wsc *WaylandServerConn, id uint32) {
+	pb := NewPacketBuilder(id, XdgPopupEventPopupDone)
+	wsc.SendMessage(pb.Build())
+}
+
+// SendXdgPopupRepositioned sends a xdg_popup.repositioned event: signal the completion of a repositioned request
+func SendXdgPopupRepositioned(wsc *WaylandServerConn, id uint32, token uint32) {
+	if wsc.registry.Version(id) < XdgPopupEventRepositionedSince {
+		return
+	}
+	pb := NewPacketBuilder(id, XdgPopupEventRepositioned)
+	pb.WithUint(token)
+	wsc.SendMessage(pb.Build())
+}
+
+func init() {
+	registerInterfaces(
+		&InterfaceSpec{Name: XdgWmBaseInterface, Version: XdgWmBaseVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "create_positioner", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "xdg_positioner"}}},
+				{Name: "get_xdg_surface", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "xdg_surface"}, {Name: "surface", Type: "object", Interface: "wl_surface"}}},
+				{Name: "pong", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "ping", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: XdgPositionerInterface, Version: XdgPositionerVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "set_size", Args: []ArgSpec{{Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "set_anchor_rect", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "set_anchor", Args: []ArgSpec{{Name: "anchor", Type: "uint"}}},
+				{Name: "set_gravity", Args: []ArgSpec{{Name: "gravity", Type: "uint"}}},
+				{Name: "set_constraint_adjustment", Args: []ArgSpec{{Name: "constraint_adjustment", Type: "uint"}}},
+				{Name: "set_offset", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+				{Name: "set_reactive"},
+				{Name: "set_parent_size", Args: []ArgSpec{{Name: "parent_width", Type: "int"}, {Name: "parent_height", Type: "int"}}},
+				{Name: "set_parent_configure", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: XdgSurfaceInterface, Version: XdgSurfaceVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "get_toplevel", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "xdg_toplevel"}}},
+				{Name: "get_popup", Args: []ArgSpec{{Name: "id", Type: "new_id", Interface: "xdg_popup"}, {Name: "parent", Type: "object", Interface: "xdg_surface", AllowNull: true}, {Name: "positioner", Type: "object", Interface: "xdg_positioner"}}},
+				{Name: "set_window_geometry", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "ack_configure", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "configure", Args: []ArgSpec{{Name: "serial", Type: "uint"}}},
+			},
+		},
+		&InterfaceSpec{Name: XdgToplevelInterface, Version: XdgToplevelVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "set_parent", Args: []ArgSpec{{Name: "parent", Type: "object", Interface: "xdg_toplevel", AllowNull: true}}},
+				{Name: "set_title", Args: []ArgSpec{{Name: "title", Type: "string"}}},
+				{Name: "set_app_id", Args: []ArgSpec{{Name: "app_id", Type: "string"}}},
+				{Name: "show_window_menu", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}, {Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+				{Name: "move", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}}},
+				{Name: "resize", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}, {Name: "edges", Type: "uint"}}},
+				{Name: "set_max_size", Args: []ArgSpec{{Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "set_min_size", Args: []ArgSpec{{Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "set_maximized"},
+				{Name: "unset_maximized"},
+				{Name: "set_fullscreen", Args: []ArgSpec{{Name: "output", Type: "object", Interface: "wl_output", AllowNull: true}}},
+				{Name: "unset_fullscreen"},
+				{Name: "set_minimized"},
+			},
+			Events: []MessageSpec{
+				{Name: "configure", Args: []ArgSpec{{Name: "width", Type: "int"}, {Name: "height", Type: "int"}, {Name: "states", Type: "array"}}},
+				{Name: "close"},
+				{Name: "configure_bounds", Args: []ArgSpec{{Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "wm_capabilities", Args: []ArgSpec{{Name: "capabilities", Type: "array"}}},
+			},
+		},
+		&InterfaceSpec{Name: XdgPopupInterface, Version: XdgPopupVersion,
+			Requests: []MessageSpec{
+				{Name: "destroy"},
+				{Name: "grab", Args: []ArgSpec{{Name: "seat", Type: "object", Interface: "wl_seat"}, {Name: "serial", Type: "uint"}}},
+				{Name: "reposition", Args: []ArgSpec{{Name: "positioner", Type: "object", Interface: "xdg_positioner"}, {Name: "token", Type: "uint"}}},
+			},
+			Events: []MessageSpec{
+				{Name: "configure", Args: []ArgSpec{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}, {Name: "width", Type: "int"}, {Name: "height", Type: "int"}}},
+				{Name: "popup_done"},
+				{Name: "repositioned", Args: []ArgSpec{{Name: "token", Type: "uint"}}},
+			},
+		},
+	)
+}
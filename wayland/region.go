@@ -3,8 +3,8 @@ package wayland
 import (
 	"fmt"
 	"image"
+	"nyctal/model"
 	"nyctal/utils"
-	"slices"
 )
 
 type Area struct {
@@ -31,10 +31,10 @@ func NewRegion(id uint32, wsc *WaylandServerConn) *Region {
 // This is needed because input areas and opaque areas are defined by different defaults.
 func (u *Region) Intersects(point image.Point) (bool, bool) {
 	utils.Debug(int(u.wsc.id), fmt.Sprintf("region#%d", u.id), fmt.Sprintf("computing intersection %v", point))
+	// later areas take precedence, the areas are not reversed in place as copies are read while rendering
 	areas := u.rects.Inner()
-	slices.Reverse(areas)
-	for _, area := range areas {
-		if point.In(area.rect) {
+	for i := len(areas) - 1; i >= 0; i-- {
+		if area := areas[i]; point.In(area.rect) {
 			return true, !area.subtract
 		}
 	}
@@ -42,40 +42,57 @@ func (u *Region) Intersects(point image.Point) (bool, bool) {
 	return false, false
 }
 
+// Copy returns a copy of the region, surfaces keep a copy so that later changes to the region object do
+// not affect them
+func (u *Region) Copy() *Region {
+	rects := utils.NewStack[Area]()
+	for _, area := range u.rects.Inner() {
+		rects.Push(area)
+	}
+	return &Region{id: u.id, rects: rects, wsc: u.wsc}
+}
+
+// Rects returns the region as rectangles, which may overlap
+func (u *Region) Rects() []image.Rectangle {
+	var rects []image.Rectangle
+	for _, area := range u.rects.Inner() {
+		if !area.subtract {
+			rects = append(rects, area.rect)
+			continue
+		}
+		var remaining []image.Rectangle
+		for _, rect := range rects {
+			remaining = append(remaining, model.Subtract(rect, []image.Rectangle{area.rect})...)
+		}
+		rects = remaining
+	}
+	return rects
+}
+
 func (u *Region) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlRegionRequestDestroy:
 		wsc.registry.Destroy(u.id)
-		// destroy
-		wsc.SendMessage(
-			NewPacketBuilder(0x1, 0x01).WithUint(u.id).
-				Build())
 		return nil
-	case 1:
+	case WlRegionRequestAdd:
 		// Add the specified rectangle to the region.
-		x := NewIntField()
-		y := NewIntField()
-		width := NewIntField()
-		height := NewIntField()
-		if err := ParsePacketStructure(packet.Data, x, y, width, height); err != nil {
+		req, err := ParseWlRegionAddRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		u.rects.Push(Area{rect: image.Rect(int(*x), int(*y), int(*x)+int(*width), int(*y)+int(*height)), subtract: false})
+		u.rects.Push(Area{rect: image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height)), subtract: false})
 		return nil
-	case 2:
+	case WlRegionRequestSubtract:
 		// Substract the specified rectangle to the region.
-		x := NewIntField()
-		y := NewIntField()
-		width := NewIntField()
-		height := NewIntField()
-		if err := ParsePacketStructure(packet.Data, x, y, width, height); err != nil {
+		req, err := ParseWlRegionSubtractRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		u.rects.Push(Area{rect: image.Rect(int(*x), int(*y), int(*x)+int(*width), int(*y)+int(*height)), subtract: true})
+		u.rects.Push(Area{rect: image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height)), subtract: true})
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on region: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlRegionInterface)
 	}
 
 }
@@ -15,7 +15,7 @@ type NullObject struct {
 }
 
 func (no *NullObject) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
-	return fmt.Errorf("handle messsage called on nil object...this is not valid")
+	return NewProtocolError(1, WlDisplayErrorInvalidObject, "invalid object 0: handle messsage called on nil object")
 }
 
 type BaseObject struct {
@@ -24,31 +24,62 @@ type BaseObject struct {
 func (no *BaseObject) Destroy() {
 }
 
+// Clients allocate ids from the bottom of the id space and the server from the top, an id is only
+// reused once the object it named has been destroyed (and, for client ids, delete_id sent)
+const (
+	clientIdMin uint32 = 0x00000001
+	clientIdMax uint32 = 0xfeffffff
+	serverIdMin uint32 = 0xff000000
+	serverIdMax uint32 = 0xffffffff
+)
+
 type Registry struct {
-	objects map[uint32]Object
-	lock    sync.Mutex
+	objects  map[uint32]Object
+	versions map[uint32]uint32
+	lock     sync.Mutex
+	// nextServerId is the next id to try for an object created by the server
+	nextServerId uint32
+	// deleted is called when a client created object is destroyed, to send wl_display.delete_id
+	deleted func(id uint32)
 }
 
-func NewRegistry() *Registry {
-	return &Registry{objects: make(map[uint32]Object)}
+func NewRegistry(deleted func(id uint32)) *Registry {
+	return &Registry{
+		objects:      map[uint32]Object{0: &NullObject{}},
+		versions:     map[uint32]uint32{0: 1},
+		nextServerId: serverIdMin,
+		deleted:      deleted,
+	}
 }
 
+// Close destroys every object without sending delete_id, as the client is going away
 func (r *Registry) Close() {
 	r.lock.Lock()
-	defer r.lock.Unlock()
-	for id, obj := range r.objects {
+	objects := r.objects
+	r.objects = make(map[uint32]Object)
+	r.versions = make(map[uint32]uint32)
+	r.lock.Unlock()
+	for _, obj := range objects {
 		obj.Destroy()
-		delete(r.objects, id)
 	}
 }
 
+// Destroy removes an object from the registry, objects created by the client have their id released
+// with wl_display.delete_id. Destroying an id that does not exist (or was already destroyed) does nothing.
 func (r *Registry) Destroy(id uint32) {
 	r.lock.Lock()
-	defer r.lock.Unlock()
-	if obj, ok := r.objects[id]; ok {
-		obj.Destroy()
-	}
+	obj, ok := r.objects[id]
 	delete(r.objects, id)
+	delete(r.versions, id)
+	r.lock.Unlock()
+	// objects are destroyed outside of the lock so that they can destroy the objects they own
+	if !ok {
+		return
+	}
+	obj.Destroy()
+	if id >= clientIdMin && id <= clientIdMax && r.deleted != nil {
+		r.deleted(id)
+	}
 }
 
 func (r *Registry) Get(id uint32) (Object, error) {
@@ -61,13 +92,57 @@ func (r *Registry) Get(id uint32) (Object, error) {
 	}
 }
 
-func (r *Registry) New(id uint32, obj Object) {
+// New adds an object created by the client to the registry with the version of the interface the client
+// is using. Objects created by a request share the version of the object the request was sent to, objects
+// created by wl_registry.bind have the version requested by the client. The id must be in the client range
+// and not in use.
+func (r *Registry) New(id uint32, obj Object, version uint32) error {
+	if id < clientIdMin || id > clientIdMax {
+		return NewProtocolError(1, WlDisplayErrorInvalidObject, "invalid new id %d, outside of the client range", id)
+	}
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	if o, ok := r.objects[id]; ok {
-		o.Destroy()
+	if _, ok := r.objects[id]; ok {
+		return NewProtocolError(1, WlDisplayErrorInvalidObject, "invalid new id %d, already in use", id)
 	}
 	r.objects[id] = obj
+	r.versions[id] = version
+	return nil
+}
+
+// NewServerObject adds an object created by the server (e.g. wl_data_offer) to the registry, returning
+// the id it was allocated
+func (r *Registry) NewServerObject(obj Object, version uint32) (uint32, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for range serverIdMax - serverIdMin {
+		id := r.nextServerId
+		if r.nextServerId == serverIdMax {
+			r.nextServerId = serverIdMin
+		} else {
+			r.nextServerId += 1
+		}
+		if _, ok := r.objects[id]; !ok {
+			r.objects[id] = obj
+			r.versions[id] = version
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no server ids left")
+}
+
+// Len returns the number of live objects
+func (r *Registry) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.objects)
+}
+
+// Version returns the version of the interface bound for the object, or 0 if the object does not exist
+func (r *Registry) Version(id uint32) uint32 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.versions[id]
 }
 
 func (r *Registry) FindDataDevice() *DataDevice {
@@ -92,6 +167,31 @@ func (r *Registry) FindSeat() *Seat {
 	return nil
 }
 
+// Outputs returns every wl_output the client has bound
+func (r *Registry) Outputs() []*Output {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var outputs []*Output
+	for _, object := range r.objects {
+		if output, ok := object.(*Output); ok {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+func (r *Registry) Surfaces() []*Surface {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var surfaces []*Surface
+	for _, object := range r.objects {
+		if surface, ok := object.(*Surface); ok {
+			surfaces = append(surfaces, surface)
+		}
+	}
+	return surfaces
+}
+
 func (r *Registry) FindOutput() *Output {
 	r.lock.Lock()
 	defer r.lock.Unlock()
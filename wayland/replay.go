@@ -0,0 +1,370 @@
+package wayland
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+
+	"nyctal/model"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReplayReport summarises a replay, Mismatch describes the first event or frame that differed from the capture
+type ReplayReport struct {
+	Requests int
+	Events   int
+	Frames   int
+	Mismatch string
+}
+
+func (r *ReplayReport) Ok() bool {
+	return r.Mismatch == ""
+}
+
+// errReplayDisconnected stops a replay once the server has disconnected the client, as the original
+// session would have ended there too
+var errReplayDisconnected = errors.New("client disconnected")
+
+// maskedArgs are event arguments that legitimately differ between runs
+var maskedArgs = map[string]bool{"serial": true, "time": true, "callback_data": true}
+
+// Replay runs a capture (see SetCapture) as a new client of the server, which should have no other
+// clients. Requests, fds, buffer contents, input and frames are fed to the server in the order they were
+// recorded and the events sent and frames rendered are compared with the capture.
+func (ws *WaylandServer) Replay(capture io.Reader) (*ReplayReport, error) {
+	records, err := ReadCapture(capture)
+	if len(records) == 0 {
+		return nil, err
+	}
+	// a truncated capture (e.g. the compositor was killed) is replayed as far as it goes
+
+	pair, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not create socketpair: %v", err)
+	}
+	defer unix.Close(pair[1])
+	// events are compared using the replay's own capture, those sent to the socket are discarded
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if n, err := unix.Read(pair[1], buf); n <= 0 || err != nil {
+				return
+			}
+		}
+	}()
+
+	wsc := ws.newConn(nil, pair[0])
+	var replayed bytes.Buffer
+	wsc.SetCapture(&replayed)
+
+	rp := &replayer{ws: ws, wsc: wsc, ifaces: interfaceMap{1: WlDisplayInterface}, pools: make(map[uint32]int),
+		replayed: &replayed, replayedRead: len(captureMagic), replayedIfaces: interfaceMap{1: WlDisplayInterface}, serials: make(map[uint32]uint32)}
+	report := &ReplayReport{}
+	for _, record := range records {
+		err := rp.replay(record, report)
+		wsc.Flush()
+		if err == errReplayDisconnected {
+			break
+		}
+		if err != nil {
+			report.Mismatch = err.Error()
+			break
+		}
+	}
+	wsc.SetCapture(nil)
+	ws.removeConn(wsc)
+	rp.close()
+
+	if report.Mismatch == "" {
+		actual, _ := ReadCapture(&replayed)
+		report.Mismatch = compareCaptures(summarize(records), summarize(actual))
+	}
+	return report, nil
+}
+
+type replayer struct {
+	ws     *WaylandServer
+	wsc    *WaylandServerConn
+	ifaces interfaceMap
+	// fds are writable copies of the memfds queued for the server, pools holds those used for shm pools
+	fds   []int
+	pools map[uint32]int
+
+	// serials are compositor wide so those sent during the replay differ from the capture, the serials of
+	// captured and replayed events are paired up in order so that those in requests can be translated
+	replayed       *bytes.Buffer
+	replayedRead   int
+	replayedIfaces interfaceMap
+	captured       []uint32
+	sent           []uint32
+	serials        map[uint32]uint32
+}
+
+func (rp *replayer) close() {
+	for _, fd := range rp.fds {
+		unix.Close(fd)
+	}
+	for _, fd := range rp.pools {
+		unix.Close(fd)
+	}
+}
+
+func (rp *replayer) replay(record CaptureRecord, report *ReplayReport) error {
+	data := record.Data
+	switch record.Kind {
+	case CaptureRequest:
+		if len(data) < 8 {
+			return fmt.Errorf("truncated request")
+		}
+		packet := &WaylandMessage{Address: binary.LittleEndian.Uint32(data), Opcode: binary.LittleEndian.Uint16(data[4:]), Length: uint16(len(data)), Data: data[8:]}
+		iface, msg, _ := rp.ifaces.describe(packet.Address, packet.Opcode, packet.Data, true)
+		report.Requests += 1
+		if msg != nil {
+			packet.Data = rp.translateSerials(msg, packet.Data)
+		}
+
+		queued := len(rp.wsc.fds.Inner())
+		err := rp.ws.dispatch(rp.wsc, packet)
+		consumed := rp.fds[:queued-len(rp.wsc.fds.Inner())]
+		rp.fds = rp.fds[len(consumed):]
+		if iface == WlShmInterface && msg != nil && msg.Name == "create_pool" && len(consumed) == 1 && len(packet.Data) >= 4 {
+			id := binary.LittleEndian.Uint32(packet.Data)
+			if old, ok := rp.pools[id]; ok {
+				unix.Close(old)
+			}
+			rp.pools[id] = consumed[0]
+		} else {
+			for _, fd := range consumed {
+				unix.Close(fd)
+			}
+		}
+		// the client was disconnected, the comparison will show whether it was originally
+		if err != nil {
+			return errReplayDisconnected
+		}
+	case CaptureEvent:
+		if len(data) >= 8 {
+			_, msg, _ := rp.ifaces.describe(binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint16(data[4:]), data[8:], false)
+			rp.captured = append(rp.captured, eventSerials(msg, data[8:])...)
+		}
+		report.Events += 1
+	case CaptureFd:
+		if len(data) < 8 {
+			return fmt.Errorf("truncated fd")
+		}
+		fd, err := unix.MemfdCreate("replay", unix.MFD_CLOEXEC)
+		if err != nil {
+			return err
+		}
+		unix.Ftruncate(fd, int64(binary.LittleEndian.Uint64(data)))
+		dup, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err != nil {
+			unix.Close(fd)
+			return err
+		}
+		rp.fds = append(rp.fds, dup)
+		rp.wsc.fds.Push(fd)
+	case CaptureSHM:
+		if len(data) < 8 {
+			return fmt.Errorf("truncated shm")
+		}
+		if fd, ok := rp.pools[binary.LittleEndian.Uint32(data)]; ok {
+			unix.Pwrite(fd, data[8:], int64(binary.LittleEndian.Uint32(data[4:])))
+		}
+	case CaptureFrame:
+		if len(data) < 12 {
+			return fmt.Errorf("truncated frame")
+		}
+		report.Frames += 1
+		client, err := rp.client(binary.LittleEndian.Uint32(data))
+		if err != nil {
+			return err
+		}
+		width, height := int(binary.LittleEndian.Uint32(data[4:])), int(binary.LittleEndian.Uint32(data[8:]))
+		client.Buffer(model.EmptyBGRA(image.Rect(0, 0, width, height)), width, height)
+	case CapturePointer, CaptureKeyboard, CaptureFocus, CapturePointerLeave:
+		values := make([]uint32, len(data)/4)
+		for i := range values {
+			values[i] = binary.LittleEndian.Uint32(data[i*4:])
+		}
+		if len(values) < 1 {
+			return fmt.Errorf("truncated input")
+		}
+		client, err := rp.client(values[0])
+		if err != nil {
+			return err
+		}
+		switch {
+		case record.Kind == CaptureFocus:
+			client.ProcessFocus()
+		case record.Kind == CapturePointerLeave:
+			client.HandlePointerLeave()
+		case record.Kind == CaptureKeyboard && len(values) == 5:
+			client.ProcessKeyboardEvent(model.KeyboardEvent{Time: values[1], Key: values[2], State: values[3], Modifiers: values[4]})
+		case record.Kind == CapturePointer && len(values) == 5:
+			switch values[1] {
+			case 0:
+				client.ProcessPointerEvent(model.PointerEvent{Move: &model.PointerMoveEvent{Time: values[2], MX: math.Float32frombits(values[3]), MY: math.Float32frombits(values[4])}})
+			case 1:
+				client.ProcessPointerEvent(model.PointerEvent{Button: &model.PointerButtonEvent{Time: values[2], Button: values[3], State: values[4]}})
+			case 2:
+				client.ProcessPointerEvent(model.PointerEvent{Axis: &model.PointerAxisEvent{Time: values[2], Axis: values[3], Value: math.Float32frombits(values[4])}})
+			}
+		default:
+			return fmt.Errorf("truncated input")
+		}
+	}
+	return nil
+}
+
+// translateSerials replaces the serials of a request with those sent during the replay in place of the
+// captured ones, returning a copy of body if any were replaced
+func (rp *replayer) translateSerials(msg *MessageSpec, body []byte) []byte {
+	// pair up the serials of the events sent since the last request
+	rest := rp.replayed.Bytes()[rp.replayedRead:]
+	r := bytes.NewReader(rest)
+	for {
+		record, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		rp.replayedRead = len(rp.replayed.Bytes()) - r.Len()
+		if len(record.Data) < 8 || (record.Kind != CaptureEvent && record.Kind != CaptureRequest) {
+			continue
+		}
+		_, sent, _ := rp.replayedIfaces.describe(binary.LittleEndian.Uint32(record.Data), binary.LittleEndian.Uint16(record.Data[4:]), record.Data[8:], record.Kind == CaptureRequest)
+		if record.Kind == CaptureEvent {
+			rp.sent = append(rp.sent, eventSerials(sent, record.Data[8:])...)
+		}
+	}
+	paired := min(len(rp.captured), len(rp.sent))
+	for i := range paired {
+		rp.serials[rp.captured[i]] = rp.sent[i]
+	}
+	rp.captured, rp.sent = rp.captured[paired:], rp.sent[paired:]
+
+	offsets := serialOffsets(msg, body)
+	if len(offsets) == 0 {
+		return body
+	}
+	body = bytes.Clone(body)
+	for _, offset := range offsets {
+		if serial, ok := rp.serials[binary.LittleEndian.Uint32(body[offset:])]; ok {
+			binary.LittleEndian.PutUint32(body[offset:], serial)
+		}
+	}
+	return body
+}
+
+// eventSerials returns the serials an event carries
+func eventSerials(msg *MessageSpec, body []byte) []uint32 {
+	var serials []uint32
+	for _, offset := range serialOffsets(msg, body) {
+		serials = append(serials, binary.LittleEndian.Uint32(body[offset:]))
+	}
+	return serials
+}
+
+// serialOffsets returns where the serial arguments of a message are in its body
+func serialOffsets(msg *MessageSpec, body []byte) []int {
+	if msg == nil {
+		return nil
+	}
+	var offsets []int
+	buf := body
+	for _, arg := range msg.Args {
+		var err error
+		offset := len(body) - len(buf)
+		switch arg.Type {
+		case "int", "uint", "fixed", "object":
+			_, buf, err = readUint32(buf)
+		case "new_id":
+			if arg.Interface == "" {
+				_, _, buf, err = readString(buf)
+				if err == nil {
+					_, buf, err = readUint32(buf)
+				}
+			}
+			if err == nil {
+				_, buf, err = readUint32(buf)
+			}
+		case "string", "array":
+			_, buf, err = readBlob(buf)
+		}
+		if err != nil {
+			return offsets
+		}
+		if arg.Type == "uint" && arg.Name == "serial" {
+			offsets = append(offsets, offset)
+		}
+	}
+	return offsets
+}
+
+// client finds the window of an xdg_surface
+func (rp *replayer) client(xdgSurface uint32) (*WaylandClient, error) {
+	obj, err := rp.wsc.registry.Get(xdgSurface)
+	if surface, ok := obj.(*XDG_Surface); ok && err == nil {
+		if client, ok := rp.ws.workspace.GetTopLevel(surface.uniq).(*WaylandClient); ok {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("xdg_surface#%d has no window to render or send input to", xdgSurface)
+}
+
+// summarize describes every event and frame of a capture, with any arguments that vary between runs masked
+func summarize(records []CaptureRecord) []string {
+	ifaces := interfaceMap{1: WlDisplayInterface}
+	var summary []string
+	for _, record := range records {
+		data := record.Data
+		switch record.Kind {
+		case CaptureRequest, CaptureEvent:
+			if len(data) < 8 {
+				continue
+			}
+			id, opcode := binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint16(data[4:])
+			iface, msg, args := ifaces.describe(id, opcode, data[8:], record.Kind == CaptureRequest)
+			if record.Kind == CaptureRequest {
+				continue
+			}
+			if msg == nil {
+				summary = append(summary, fmt.Sprintf("%s#%d.[opcode %d]", iface, id, opcode))
+				continue
+			}
+			for i := range args {
+				if i < len(msg.Args) && maskedArgs[msg.Args[i].Name] {
+					args[i] = "*"
+				}
+			}
+			summary = append(summary, fmt.Sprintf("%s#%d.%s(%s)", iface, id, msg.Name, strings.Join(args, ", ")))
+		case CaptureFrame:
+			if len(data) == 20 {
+				summary = append(summary, fmt.Sprintf("frame of xdg_surface#%d at %dx%d with contents %016x",
+					binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint32(data[4:]), binary.LittleEndian.Uint32(data[8:]), binary.LittleEndian.Uint64(data[12:])))
+			}
+		}
+	}
+	return summary
+}
+
+func compareCaptures(expected, actual []string) string {
+	for i := range min(len(expected), len(actual)) {
+		if expected[i] != actual[i] {
+			return fmt.Sprintf("#%d: expected %s, got %s", i, expected[i], actual[i])
+		}
+	}
+	if len(expected) > len(actual) {
+		return fmt.Sprintf("#%d: expected %s, got nothing", len(actual), expected[len(actual)])
+	}
+	if len(actual) > len(expected) {
+		return fmt.Sprintf("#%d: expected nothing, got %s", len(expected), actual[len(expected)])
+	}
+	return ""
+}
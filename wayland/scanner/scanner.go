@@ -0,0 +1,518 @@
+// scanner reads wayland protocol xml definitions (see the specs directory) and
+// emits Go bindings for package wayland: opcode constants, version info, enum types,
+// typed request decoders and event senders.
+//
+// It is run via `go generate` from the wayland package, adding support for a new protocol is
+// a matter of dropping its xml into specs and adding a go:generate line.
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Protocol struct {
+	Name       string      `xml:"name,attr"`
+	Interfaces []Interface `xml:"interface"`
+}
+
+type Description struct {
+	Summary string `xml:"summary,attr"`
+}
+
+type Interface struct {
+	Name        string      `xml:"name,attr"`
+	Version     int         `xml:"version,attr"`
+	Description Description `xml:"description"`
+	Requests    []Message   `xml:"request"`
+	Events      []Message   `xml:"event"`
+	Enums       []Enum      `xml:"enum"`
+}
+
+type Message struct {
+	Name        string      `xml:"name,attr"`
+	Type        string      `xml:"type,attr"`
+	Since       int         `xml:"since,attr"`
+	Description Description `xml:"description"`
+	Args        []Arg       `xml:"arg"`
+}
+
+type Arg struct {
+	Name      string `xml:"name,attr"`
+	Type      string `xml:"type,attr"`
+	Interface string `xml:"interface,attr"`
+	AllowNull bool   `xml:"allow-null,attr"`
+	Enum      string `xml:"enum,attr"`
+	Summary   string `xml:"summary,attr"`
+}
+
+type Enum struct {
+	Name        string      `xml:"name,attr"`
+	Bitfield    bool        `xml:"bitfield,attr"`
+	Description Description `xml:"description"`
+	Entries     []Entry     `xml:"entry"`
+}
+
+type Entry struct {
+	Name    string `xml:"name,attr"`
+	Value   string `xml:"value,attr"`
+	Summary string `xml:"summary,attr"`
+}
+
+// goName converts a wayland snake_case identifier into an exported Go identifier
+// e.g. wl_surface => WlSurface, set_buffer_scale => SetBufferScale
+func goName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+// comment collapses xml whitespace so that summaries fit on a single comment line
+func comment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// reserved contains identifiers that generated code cannot use for local variables
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "id": true, "wsc": true, "packet": true, "err": true, "req": true, "pb": true,
+}
+
+// localName converts a wayland snake_case identifier into an unexported Go identifier
+// that is safe to use as a local variable or parameter
+func localName(name string) string {
+	n := goName(name)
+	n = strings.ToLower(n[:1]) + n[1:]
+	if reserved[n] {
+		n += "Arg"
+	}
+	return n
+}
+
+// enumType resolves an enum reference (either "name" or "interface.name") to its generated Go type
+func enumType(iface string, enum string) string {
+	if strings.Contains(enum, ".") {
+		parts := strings.SplitN(enum, ".", 2)
+		return goName(parts[0]) + goName(parts[1])
+	}
+	return goName(iface) + goName(enum)
+}
+
+// argType returns the Go type used to represent an argument in decoded requests and event senders
+func argType(iface string, arg Arg) string {
+	switch arg.Type {
+	case "int":
+		if arg.Enum != "" {
+			return enumType(iface, arg.Enum)
+		}
+		return "int32"
+	case "uint":
+		if arg.Enum != "" {
+			return enumType(iface, arg.Enum)
+		}
+		return "uint32"
+	case "fixed":
+		return "float32"
+	case "string":
+		return "string"
+	case "object", "new_id":
+		return "uint32"
+	case "array":
+		return "[]byte"
+	case "fd":
+		return "int"
+	}
+	return ""
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	usesFmt bool
+}
+
+func (g *generator) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteString("\n")
+}
+
+func (g *generator) generate(source string, protocol *Protocol) ([]byte, error) {
+	for _, iface := range protocol.Interfaces {
+		if err := g.generateInterface(&iface); err != nil {
+			return nil, fmt.Errorf("%s: %v", iface.Name, err)
+		}
+	}
+	g.generateSpecs(protocol)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by wayland/scanner from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package wayland\n")
+	if g.usesFmt {
+		fmt.Fprintf(&out, "\nimport \"fmt\"\n")
+	}
+	out.Write(g.buf.Bytes())
+	return out.Bytes(), nil
+}
+
+// generateSpecs emits a description of every message in the protocol, used to decode messages
+// generically at runtime (e.g. for protocol tracing)
+func (g *generator) generateSpecs(protocol *Protocol) {
+	g.p("")
+	g.p("func init() {")
+	g.p("registerInterfaces(")
+	for _, iface := range protocol.Interfaces {
+		name := goName(iface.Name)
+		g.p("&InterfaceSpec{Name: %sInterface, Version: %sVersion,", name, name)
+		g.generateMessageSpecs("Requests", iface.Requests)
+		g.generateMessageSpecs("Events", iface.Events)
+		g.p("},")
+	}
+	g.p(")")
+	g.p("}")
+}
+
+func (g *generator) generateMessageSpecs(field string, messages []Message) {
+	if len(messages) == 0 {
+		return
+	}
+	g.p("%s: []MessageSpec{", field)
+	for _, msg := range messages {
+		var args []string
+		for _, arg := range msg.Args {
+			spec := fmt.Sprintf("{Name: %q, Type: %q", arg.Name, arg.Type)
+			if arg.Interface != "" {
+				spec += fmt.Sprintf(", Interface: %q", arg.Interface)
+			}
+			if arg.AllowNull {
+				spec += ", AllowNull: true"
+			}
+			args = append(args, spec+"}")
+		}
+		if len(args) == 0 {
+			g.p("{Name: %q},", msg.Name)
+		} else {
+			g.p("{Name: %q, Args: []ArgSpec{%s}},", msg.Name, strings.Join(args, ", "))
+		}
+	}
+	g.p("},")
+}
+
+func (g *generator) generateInterface(iface *Interface) error {
+	name := goName(iface.Name)
+	g.p("")
+	g.p("// %s: %s", iface.Name, comment(iface.Description.Summary))
+	g.p("const (")
+	g.p("%sInterface = %q", name, iface.Name)
+	g.p("%sVersion = %d", name, iface.Version)
+	g.p(")")
+
+	g.generateOpcodes(iface, "Request", iface.Requests)
+	g.generateOpcodes(iface, "Event", iface.Events)
+
+	for _, enum := range iface.Enums {
+		if err := g.generateEnum(iface, &enum); err != nil {
+			return err
+		}
+	}
+
+	for _, request := range iface.Requests {
+		if err := g.generateRequestParser(iface, &request); err != nil {
+			return fmt.Errorf("%s: %v", request.Name, err)
+		}
+	}
+
+	for _, event := range iface.Events {
+		if err := g.generateEventSender(iface, &event); err != nil {
+			return fmt.Errorf("%s: %v", event.Name, err)
+		}
+	}
+	return nil
+}
+
+func (g *generator) generateOpcodes(iface *Interface, kind string, messages []Message) {
+	if len(messages) == 0 {
+		return
+	}
+	name := goName(iface.Name)
+	g.p("")
+	g.p("// %s %s opcodes", iface.Name, strings.ToLower(kind))
+	g.p("const (")
+	for opcode, msg := range messages {
+		if summary := comment(msg.Description.Summary); summary != "" {
+			g.p("%s%s%s uint16 = %d // %s", name, kind, goName(msg.Name), opcode, summary)
+		} else {
+			g.p("%s%s%s uint16 = %d", name, kind, goName(msg.Name), opcode)
+		}
+	}
+	g.p(")")
+
+	// only messages introduced after version 1 need their version recorded
+	var since []Message
+	for _, msg := range messages {
+		if msg.Since > 1 {
+			since = append(since, msg)
+		}
+	}
+	if len(since) > 0 {
+		g.p("")
+		g.p("// %s %s since versions", iface.Name, strings.ToLower(kind))
+		g.p("const (")
+		for _, msg := range since {
+			g.p("%s%s%sSince = %d", name, kind, goName(msg.Name), msg.Since)
+		}
+		g.p(")")
+	}
+}
+
+func (g *generator) generateEnum(iface *Interface, enum *Enum) error {
+	typ := goName(iface.Name) + goName(enum.Name)
+	g.p("")
+	g.p("// %s: %s", typ, comment(enum.Description.Summary))
+	g.p("type %s uint32", typ)
+	g.p("")
+	g.p("const (")
+	for _, entry := range enum.Entries {
+		if _, err := strconv.ParseUint(entry.Value, 0, 32); err != nil {
+			return fmt.Errorf("enum %s.%s: invalid value %q", enum.Name, entry.Name, entry.Value)
+		}
+		if entry.Summary != "" {
+			g.p("%s%s %s = %s // %s", typ, goName(entry.Name), typ, entry.Value, comment(entry.Summary))
+		} else {
+			g.p("%s%s %s = %s", typ, goName(entry.Name), typ, entry.Value)
+		}
+	}
+	g.p(")")
+	return nil
+}
+
+// field describes how a single wire argument is parsed by ParsePacketStructureWithFds
+type field struct {
+	local  string
+	ctor   string
+	member string
+	conv   string
+	// value is the expression used to read the decoded value out of the field
+	value string
+}
+
+// requestField returns the packet field used to decode a request argument
+func requestField(iface string, arg Arg) (field, error) {
+	local := localName(arg.Name)
+	f := field{local: local, member: goName(arg.Name), conv: argType(iface, arg)}
+	switch arg.Type {
+	case "int":
+		f.ctor = "NewIntField"
+	case "uint":
+		f.ctor = "NewUintField"
+	case "fixed":
+		f.ctor = "NewFixedField"
+	case "object":
+		f.ctor = "NewObjectField"
+		if arg.AllowNull {
+			f.ctor = "NewNullableObjectField"
+		}
+	case "new_id":
+		f.ctor = "NewObjectField"
+		if arg.Interface == "" {
+			f.ctor = "NewNewIdField"
+			f.value = local + ".Id"
+		}
+	case "string":
+		f.ctor = "NewStringField"
+		if arg.AllowNull {
+			f.ctor = "NewNullableStringField"
+			f.value = local + ".Ptr()"
+		}
+	case "array":
+		f.ctor = "NewArrayField"
+	case "fd":
+		f.ctor = "NewFdField"
+	default:
+		return f, fmt.Errorf("unsupported request argument type %s", arg.Type)
+	}
+	if f.value == "" {
+		f.value = fmt.Sprintf("%s(*%s)", f.conv, local)
+	}
+	return f, nil
+}
+
+func (g *generator) generateRequestParser(iface *Interface, request *Message) error {
+	if len(request.Args) == 0 {
+		return nil
+	}
+	typ := goName(iface.Name) + goName(request.Name) + "Request"
+	qualified := iface.Name + "." + request.Name
+
+	var fields []field
+	for _, arg := range request.Args {
+		f, err := requestField(iface.Name, arg)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+
+	g.p("")
+	g.p("// %s holds the arguments of a %s request", typ, qualified)
+	g.p("type %s struct {", typ)
+	for i, f := range fields {
+		if f.ctor == "NewNewIdField" {
+			// new_id without a fixed interface are sent as (interface, version, id)
+			g.p("Interface string")
+			g.p("Version uint32")
+		}
+		if request.Args[i].Type == "string" && request.Args[i].AllowNull {
+			g.p("%s *string", f.member)
+		} else {
+			g.p("%s %s", f.member, f.conv)
+		}
+	}
+	g.p("}")
+	g.p("")
+	g.p("// Parse%s decodes a %s request", typ, qualified)
+	g.p("func Parse%s(wsc *WaylandServerConn, packet *WaylandMessage) (*%s, error) {", typ, typ)
+	var locals []string
+	for _, f := range fields {
+		g.p("%s := %s()", f.local, f.ctor)
+		locals = append(locals, f.local)
+	}
+	g.p("if err := ParsePacketStructureWithFds(packet.Data, wsc.fds, %s); err != nil {", strings.Join(locals, ", "))
+	g.p("return nil, NewProtocolError(packet.Address, WlDisplayErrorInvalidMethod, \"invalid arguments for %s#%%d: %%v\", packet.Address, err)", qualified)
+	g.p("}")
+	g.p("return &%s{", typ)
+	for _, f := range fields {
+		if f.ctor == "NewNewIdField" {
+			g.p("Interface: %s.Interface,", f.local)
+			g.p("Version: %s.Version,", f.local)
+		}
+		g.p("%s: %s,", f.member, f.value)
+	}
+	g.p("}, nil")
+	g.p("}")
+	return nil
+}
+
+func (g *generator) generateEventSender(iface *Interface, event *Message) error {
+	name := goName(iface.Name)
+	params := []string{"wsc *WaylandServerConn", "id uint32"}
+	var with []string
+	fds := 0
+	for _, arg := range event.Args {
+		local := localName(arg.Name)
+		typ := argType(iface.Name, arg)
+		if arg.Type == "string" && arg.AllowNull {
+			typ = "*string"
+		}
+		if arg.Type == "new_id" && arg.Interface == "" {
+			params = append(params, local+"Interface string", local+"Version uint32")
+		}
+		params = append(params, local+" "+typ)
+		switch arg.Type {
+		case "int":
+			with = append(with, fmt.Sprintf("WithInt(int32(%s))", local))
+		case "uint", "object":
+			if arg.Enum != "" {
+				with = append(with, fmt.Sprintf("WithUint(uint32(%s))", local))
+			} else {
+				with = append(with, fmt.Sprintf("WithUint(%s)", local))
+			}
+		case "new_id":
+			if arg.Interface == "" {
+				with = append(with, fmt.Sprintf("WithNewId(%sInterface, %sVersion, %s)", local, local, local))
+			} else {
+				with = append(with, fmt.Sprintf("WithUint(%s)", local))
+			}
+		case "fixed":
+			with = append(with, fmt.Sprintf("WithFixed(%s)", local))
+		case "string":
+			if arg.AllowNull {
+				with = append(with, fmt.Sprintf("WithNullableString(%s)", local))
+			} else {
+				with = append(with, fmt.Sprintf("WithString(%s)", local))
+			}
+		case "array":
+			with = append(with, fmt.Sprintf("WithArray(%s)", local))
+		case "fd":
+			with = append(with, fmt.Sprintf("WithFd(%s)", local))
+			fds++
+		default:
+			return fmt.Errorf("unsupported event argument type %s", arg.Type)
+		}
+	}
+
+	g.p("")
+	g.p("// Send%s%s sends a %s.%s event: %s", name, goName(event.Name), iface.Name, event.Name, comment(event.Description.Summary))
+	g.p("func Send%s%s(%s) {", name, goName(event.Name), strings.Join(params, ", "))
+	if event.Since > 1 {
+		// clients that bound an older version of the interface cannot decode the event
+		g.p("if wsc.registry.Version(id) < %sEvent%sSince {", name, goName(event.Name))
+		g.p("return")
+		g.p("}")
+	}
+	g.p("pb := NewPacketBuilder(id, %sEvent%s)", name, goName(event.Name))
+	for _, w := range with {
+		g.p("pb.%s", w)
+	}
+	if fds > 0 {
+		g.p("wsc.SendMessageWithFds(pb.Build(), pb.Fds())")
+	} else {
+		g.p("wsc.SendMessage(pb.Build())")
+	}
+	g.p("}")
+	return nil
+}
+
+func main() {
+	output := flag.String("o", "", "output file")
+	flag.Parse()
+	if flag.NArg() != 1 || *output == "" {
+		fmt.Fprintf(os.Stderr, "usage: scanner -o <output.go> <protocol.xml>\n")
+		os.Exit(2)
+	}
+
+	source := flag.Arg(0)
+	data, err := os.ReadFile(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scanner: %v\n", err)
+		os.Exit(1)
+	}
+
+	protocol := new(Protocol)
+	if err := xml.Unmarshal(data, protocol); err != nil {
+		fmt.Fprintf(os.Stderr, "scanner: could not parse %s: %v\n", source, err)
+		os.Exit(1)
+	}
+
+	g := new(generator)
+	generated, err := g.generate(filepath.Base(source), protocol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scanner: %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := format.Source(generated)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scanner: generated invalid code: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "scanner: %v\n", err)
+		os.Exit(1)
+	}
+}
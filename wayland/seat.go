@@ -14,23 +14,22 @@ type Seat struct {
 	id           uint32
 	keyboard     *Keyboard
 	mouse        *Pointer
-	serial       uint32
 	DataDevice   *DataDevice
 	pointerFocus *XDG_Surface
 }
 
 func (s *Seat) Grab(surface *XDG_Surface) {
 	if s.keyboard != nil {
-		s.keyboard.Leave(s.serial)
-		s.serial += 1
-		s.keyboard.Enter(s.serial, surface.surface)
+		wsc := s.keyboard.wsc
+		s.keyboard.Leave(wsc.nextSerial(SerialKeyboardLeave, false))
+		s.keyboard.Enter(wsc.nextSerial(SerialKeyboardEnter, false), surface.surface)
 	}
 }
 
 func (s *Seat) ProcessKeyboardEvent(ev model.KeyboardEvent) {
 	if s.keyboard != nil {
-		s.serial += 1
-		s.keyboard.ProcessKeyboardEvent(ev, s.serial)
+		serial := s.keyboard.wsc.nextSerial(SerialKey, WlKeyboardKeyState(ev.State) == WlKeyboardKeyStatePressed)
+		s.keyboard.ProcessKeyboardEvent(ev, serial)
 	}
 }
 
@@ -62,11 +61,8 @@ func (s *Seat) ProcessPointerEvent(wsc *WaylandServerConn, ev model.PointerEvent
 		// we intersected with nothing
 		if is == nil {
 			if s.pointerFocus != nil {
-				s.serial += 1
-				utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
-				wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x01).
-					WithUint(s.serial).
-					WithUint(s.pointerFocus.surface.id).Build())
+				utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse.id), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
+				SendWlPointerLeave(wsc, s.mouse.id, wsc.nextSerial(SerialPointerLeave, false), s.pointerFocus.surface.id)
 				s.pointerFocus.hasPointer = false
 				s.pointerFocus = nil
 			}
@@ -77,12 +73,9 @@ func (s *Seat) ProcessPointerEvent(wsc *WaylandServerConn, ev model.PointerEvent
 			s.pointerFocus = is
 			s.pointerFocus.hasPointer = false
 		} else if is.id != s.pointerFocus.id {
-			s.serial += 1
-			utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
-			wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x01).
-				WithUint(s.serial).
-				WithUint(s.pointerFocus.surface.id).Build())
-			utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
+			utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse.id), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
+			SendWlPointerLeave(wsc, s.mouse.id, wsc.nextSerial(SerialPointerLeave, false), s.pointerFocus.surface.id)
+			utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse.id), fmt.Sprintf("leave %d ", s.pointerFocus.surface.id))
 			s.pointerFocus.hasPointer = false
 			s.pointerFocus = is
 		}
@@ -102,96 +95,86 @@ func (s *Seat) ProcessPointerEvent(wsc *WaylandServerConn, ev model.PointerEvent
 			if !top.hasPointer {
 
 				if s.keyboard != nil {
-					s.serial += 1
-					s.keyboard.Leave(s.serial)
-					s.serial += 1
-					s.keyboard.Enter(s.serial, top.surface)
+					s.keyboard.Leave(wsc.nextSerial(SerialKeyboardLeave, false))
+					s.keyboard.Enter(wsc.nextSerial(SerialKeyboardEnter, false), top.surface)
 				}
 
-				s.serial += 1
-				pb := NewPacketBuilder(s.mouse.id, 0x00).
-					WithUint(s.serial).
-					WithUint(top.surface.id)
-
+				serial := wsc.nextSerial(SerialPointerEnter, false)
 				if ev.Move != nil {
-					pb = pb.WithFixed(ev.Move.MX)
-					pb = pb.WithFixed(ev.Move.MY)
+					SendWlPointerEnter(wsc, s.mouse.id, serial, top.surface.id, ev.Move.MX, ev.Move.MY)
 				} else {
-					pb = pb.WithFixed(0)
-					pb = pb.WithFixed(0)
-
+					SendWlPointerEnter(wsc, s.mouse.id, serial, top.surface.id, 0, 0)
 				}
-				wsc.SendMessage(pb.Build())
-				utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse), fmt.Sprintf("enter %d %v", top.surface.id, ev.Move))
+				utils.Debug(int(wsc.id), fmt.Sprintf("wl_pointer#%d", s.mouse.id), fmt.Sprintf("enter %d %v", top.surface.id, ev.Move))
 				top.hasPointer = true
-				wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x05).Build())
+				SendWlPointerFrame(wsc, s.mouse.id)
 
 			}
 
 			if ev.Move != nil {
-				wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x02).
-					WithUint(ev.Move.Time).
-					WithFixed(ev.Move.MX).
-					WithFixed(ev.Move.MY).Build())
-				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse), "motion")
+				SendWlPointerMotion(wsc, s.mouse.id, ev.Move.Time, ev.Move.MX, ev.Move.MY)
+				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse.id), "motion")
 			}
 
 			if ev.Button != nil {
-				s.serial += 1
-				wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x03).
-					WithUint(s.serial).
-					WithUint(ev.Button.Time).
-					WithUint(ev.Button.Button).
-					WithUint(ev.Button.State).Build())
-				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse), "button")
+				serial := wsc.nextSerial(SerialButton, WlPointerButtonState(ev.Button.State) == WlPointerButtonStatePressed)
+				SendWlPointerButton(wsc, s.mouse.id, serial, ev.Button.Time, ev.Button.Button, WlPointerButtonState(ev.Button.State))
+				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse.id), "button")
 			}
 
 			if ev.Axis != nil {
-				wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x04).
-					WithUint(ev.Axis.Time).
-					WithUint(ev.Axis.Axis).
-					WithFixed(ev.Axis.Value).Build())
-				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse), "axis")
+				SendWlPointerAxis(wsc, s.mouse.id, ev.Axis.Time, WlPointerAxis(ev.Axis.Axis), ev.Axis.Value)
+				//utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse.id), "axis")
 			}
-			wsc.SendMessage(NewPacketBuilder(s.mouse.id, 0x05).Build())
-			//	utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse), "frame")
+			SendWlPointerFrame(wsc, s.mouse.id)
+			//	utils.Debug(fmt.Sprintf("wl_pointer#%d", s.mouse.id), "frame")
 		}
 	}
 }
 
-func NewSeat(wsc *WaylandServerConn, id uint32) *Seat {
+func NewSeat(wsc *WaylandServerConn, id uint32, version uint32) (*Seat, error) {
+	seat := &Seat{id: id}
+	if err := wsc.registry.New(id, seat, version); err != nil {
+		return nil, err
+	}
 
-	wsc.SendMessage(NewPacketBuilder(id, 0x00).WithUint(0x03).Build())
+	SendWlSeatCapabilities(wsc, id, WlSeatCapabilityPointer|WlSeatCapabilityKeyboard)
 	utils.Debug(int(wsc.id), fmt.Sprintf("wl_seat#%d", id), "capabilities")
-	wsc.SendMessage(NewPacketBuilder(id, 0x01).WithString("default").Build())
+	SendWlSeatName(wsc, id, "default")
 
-	return &Seat{id: id}
+	return seat, nil
 }
 
 func (u *Seat) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlSeatRequestGetPointer:
 
-		mouse_id := NewUintField()
-		if err := ParsePacketStructure(packet.Data, mouse_id); err != nil {
+		req, err := ParseWlSeatGetPointerRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		u.mouse = &Pointer{server: u.server, id: uint32(*mouse_id)}
-		wsc.registry.New(uint32(*mouse_id), u.mouse)
-		utils.Debug(int(wsc.id), "wl_seat", fmt.Sprintf("get_pointer#%d", u.mouse))
+		mouse := &Pointer{server: u.server, id: req.Id}
+		if err := wsc.registry.New(req.Id, mouse, wsc.registry.Version(packet.Address)); err != nil {
+			return err
+		}
+		u.mouse = mouse
+		utils.Debug(int(wsc.id), "wl_seat", fmt.Sprintf("get_pointer#%d", u.mouse.id))
 		return nil
-	case 1:
-		keyboard_id := NewUintField()
-		if err := ParsePacketStructure(packet.Data, keyboard_id); err != nil {
+	case WlSeatRequestGetKeyboard:
+		req, err := ParseWlSeatGetKeyboardRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		keyboard, err := NewKeyboard(req.Id, wsc.registry.Version(packet.Address), wsc)
+		if err != nil {
 			return err
 		}
-		kbid := uint32(*keyboard_id)
-		u.keyboard = NewKeyboard(kbid, wsc)
+		u.keyboard = keyboard
 		utils.Debug(int(wsc.id), "wl_seat", fmt.Sprintf("get_keyboard#%d", u.keyboard.id))
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on wl_seat: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlSeatInterface)
 	}
 
 }
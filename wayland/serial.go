@@ -0,0 +1,88 @@
+package wayland
+
+import (
+	"sync"
+
+	"nyctal/model"
+)
+
+// SerialKind is the event a serial was sent with
+type SerialKind uint8
+
+const (
+	SerialKeyboardEnter SerialKind = iota + 1
+	SerialKeyboardLeave
+	SerialKey
+	SerialModifiers
+	SerialPointerEnter
+	SerialPointerLeave
+	SerialButton
+	SerialConfigure
+)
+
+// maxSerials is how many of the most recent serials are remembered, requests carrying an older serial
+// are treated as if the serial was never sent
+const maxSerials = 256
+
+type serialEvent struct {
+	client  model.GlobalIdx
+	kind    SerialKind
+	pressed bool
+}
+
+// Serials allocates serials for the whole compositor, so that a serial identifies a single event sent to a
+// single client. Requests that are only allowed in response to input (grabs, moves, setting the selection
+// or cursor) are checked against the event their serial came from.
+type Serials struct {
+	lock   sync.Mutex
+	last   uint32
+	events [maxSerials]serialEvent
+}
+
+// Next allocates the serial for an event sent to client, pressed is whether a button or key went down
+func (s *Serials) Next(client model.GlobalIdx, kind SerialKind, pressed bool) uint32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.last += 1
+	// 0 is never sent, so that it can never be valid
+	if s.last == 0 {
+		s.last = 1
+	}
+	s.events[s.last%maxSerials] = serialEvent{client: client, kind: kind, pressed: pressed}
+	return s.last
+}
+
+func (s *Serials) lookup(serial uint32) (serialEvent, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if serial == 0 || s.last-serial >= maxSerials {
+		return serialEvent{}, false
+	}
+	return s.events[serial%maxSerials], true
+}
+
+// nextSerial allocates a serial for an event sent to the client
+func (c *WaylandServerConn) nextSerial(kind SerialKind, pressed bool) uint32 {
+	return c.serials.Next(c.id, kind, pressed)
+}
+
+// validSerial reports whether serial was recently sent to the client with one of the kinds of event given,
+// button and key serials are only valid if they were for a press
+func (c *WaylandServerConn) validSerial(serial uint32, kinds ...SerialKind) bool {
+	ev, ok := c.serials.lookup(serial)
+	if !ok || ev.client != c.id {
+		return false
+	}
+	for _, kind := range kinds {
+		if ev.kind == kind {
+			return ev.pressed || (kind != SerialButton && kind != SerialKey)
+		}
+	}
+	return false
+}
+
+// validInputSerial reports whether serial came from a button or key press of the client, as required
+// for grabs and interactive moves
+func (c *WaylandServerConn) validInputSerial(serial uint32) bool {
+	return c.validSerial(serial, SerialButton, SerialKey)
+}
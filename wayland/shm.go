@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"nyctal/utils"
+
+	"golang.org/x/sys/unix"
 )
 
 type SHM struct {
@@ -14,29 +16,31 @@ type SHM struct {
 func (u *SHM) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-		newId := NewUintField()
-		size := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId, size); err != nil {
+	case WlShmRequestCreatePool:
+		req, err := ParseWlShmCreatePoolRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		utils.Debug(int(wsc.id), fmt.Sprintf("shm#%d", u.id), fmt.Sprintf("wm_shm_pool#%d %d", *newId, *size))
+		utils.Debug(int(wsc.id), fmt.Sprintf("shm#%d", u.id), fmt.Sprintf("wm_shm_pool#%d %d", req.Id, req.Size))
 
-		fd, err := wsc.fds.Pop()
-		if err != nil {
-			return fmt.Errorf("expected an fd, but could not pop from queue: %v", err)
+		if req.Size <= 0 {
+			unix.Close(req.Fd)
+			return NewProtocolError(u.id, WlShmErrorInvalidStride, "invalid size (%d)", req.Size)
 		}
-
-		pool, err := NewSHMPool(uint32(*newId), wsc, fd, uint32(*size))
-		if err != nil {
+		pool, err := NewSHMPool(req.Id, wsc, req.Fd, uint32(req.Size))
+		if _, ok := err.(*ProtocolError); ok {
+			return err
+		} else if err != nil {
+			return NewProtocolError(u.id, WlShmErrorInvalidFd, "failed to create pool: %v", err)
+		}
+		if err := wsc.registry.New(req.Id, pool, wsc.registry.Version(packet.Address)); err != nil {
 			return err
 		}
-		wsc.registry.New(uint32(*newId), pool)
 		return nil
 
 	default:
-		return fmt.Errorf("unknown opcode called on unbound object: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlShmInterface)
 	}
 
 }
@@ -2,7 +2,9 @@ package wayland
 
 import (
 	"fmt"
+	"sync"
 
+	"nyctal/model"
 	"nyctal/utils"
 
 	"golang.org/x/sys/unix"
@@ -14,29 +16,49 @@ type SHMPool struct {
 
 	size       uint32
 	mappedData []byte
+
+	// refs counts the pool object and the buffers created from it, the memory is unmapped once all of
+	// them have been destroyed
+	lock sync.Mutex
+	refs int
 }
 
 func NewSHMPool(id uint32, wsc *WaylandServerConn, fd int, size uint32) (*SHMPool, error) {
 	defer unix.Close(fd)
-	if size > 2048*2048*16 {
-		return nil, fmt.Errorf("attempting to make pool of huge size: %v", size/1024/1024)
+	if err := wsc.reserveSHM(int64(size)); err != nil {
+		return nil, err
 	}
 	data, err := unix.Mmap(fd, 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
 	if err != nil {
+		wsc.releaseSHM(int64(size))
 		return nil, err
 	}
 
-	pool := &SHMPool{id: id, wsc: wsc, size: size, mappedData: data}
+	pool := &SHMPool{id: id, wsc: wsc, size: size, mappedData: data, refs: 1}
 	utils.Debug(int(wsc.id), "shmpool", fmt.Sprintf("created pool %v %p", pool.id, pool.mappedData))
 	return pool, nil
 }
 
+// Destroy destroys the pool object, buffers created from it remain valid
 func (u *SHMPool) Destroy() {
+	u.unref()
+}
 
-	if u.mappedData != nil {
+func (u *SHMPool) ref() {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.refs += 1
+}
+
+func (u *SHMPool) unref() {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.refs -= 1
+	if u.refs == 0 && u.mappedData != nil {
 		utils.Debug(int(u.wsc.id), "shmpool", fmt.Sprintf("destroying pool %v %p", u.id, u.mappedData))
 		err := unix.Munmap(u.mappedData)
 		u.mappedData = nil
+		u.wsc.releaseSHM(int64(u.size))
 		utils.Debug(int(u.wsc.id), "shmpool", fmt.Sprintf("destroying pool %v %v", u.id, err))
 	}
 }
@@ -44,51 +66,58 @@ func (u *SHMPool) Destroy() {
 func (u *SHMPool) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlShmPoolRequestCreateBuffer:
 
-		if u.mappedData == nil {
-			return fmt.Errorf("pool has not been initialized")
+		req, err := ParseWlShmPoolCreateBufferRequest(wsc, packet)
+		if err != nil {
+			return err
 		}
 
-		newId := NewUintField()
-		offset := NewUintField()
-		width := NewUintField()
-		height := NewUintField()
-		stride := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId, offset, width, height, stride); err != nil {
+		utils.Debug(int(wsc.id), "shm_pool", fmt.Sprintf("create_buffer#%d %d %d %d %d ", req.Id, req.Offset, req.Width, req.Height, req.Stride))
+		format := model.Format(req.Format)
+		if !format.Supported() {
+			return NewProtocolError(u.id, WlShmErrorInvalidFormat, "unsupported format 0x%x", uint32(req.Format))
+		}
+		if req.Offset < 0 || req.Width <= 0 || req.Height <= 0 || int64(req.Stride) < int64(req.Width)*int64(format.BytesPerPixel()) ||
+			int64(req.Offset)+int64(req.Stride)*int64(req.Height) > int64(u.size) {
+			return NewProtocolError(u.id, WlShmErrorInvalidStride, "invalid width, height or stride (%dx%d, %d)", req.Width, req.Height, req.Stride)
+		}
+		if err := wsc.registry.New(req.Id,
+			&Buffer{id: req.Id, wsc: wsc, backingPool: u, offset: uint32(req.Offset), stride: uint32(req.Stride), width: uint32(req.Width), height: uint32(req.Height), format: format},
+			wsc.registry.Version(packet.Address)); err != nil {
 			return err
 		}
-
-		utils.Debug(int(wsc.id), "shm_pool", fmt.Sprintf("create_buffer#%d %d %d %d %d ", *newId, *offset, *width, *height, *stride))
-		wsc.registry.New(uint32(*newId),
-			&Buffer{id: uint32(*newId), wsc: wsc, backingPool: u, offset: uint32(*offset), stride: uint32(*stride), width: uint32(*width), height: uint32(*height)})
+		u.ref()
 		return nil
-	case 1:
+	case WlShmPoolRequestDestroy:
 		// destroy
 		wsc.registry.Destroy(u.id)
-		// wsc.SendMessage(
-		// 	NewPacketBuilder(0x1, 0x01).WithUint(u.id).
-		// 		Build())
-
 		return nil
-	case 2:
+	case WlShmPoolRequestResize:
 		// resize
-		newsize := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newsize); err != nil {
+		req, err := ParseWlShmPoolResizeRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		if req.Size < int32(u.size) {
+			return NewProtocolError(u.id, WlShmErrorInvalidStride, "shrinking pool invalid")
+		}
+		if err := wsc.reserveSHM(int64(req.Size) - int64(u.size)); err != nil {
 			return err
 		}
-		u.size = uint32(*newsize)
-		utils.Debug(int(wsc.id), "shm_pool", fmt.Sprintf("resize, %d ", *newsize))
+		utils.Debug(int(wsc.id), "shm_pool", fmt.Sprintf("resize, %d ", req.Size))
 
-		data, err := unix.Mremap(u.mappedData, int(u.size), unix.MREMAP_MAYMOVE)
+		data, err := unix.Mremap(u.mappedData, int(req.Size), unix.MREMAP_MAYMOVE)
 		if data == nil || err != nil {
-			return fmt.Errorf("could not remap data: %v", err)
+			wsc.releaseSHM(int64(req.Size) - int64(u.size))
+			return NewProtocolError(u.id, WlShmErrorInvalidFd, "could not remap data: %v", err)
 		}
+		u.size = uint32(req.Size)
 		u.mappedData = data
 
 		return nil
 
 	default:
-		return fmt.Errorf("unknown opcode called on shmpool: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlShmPoolInterface)
 	}
 }
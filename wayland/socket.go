@@ -0,0 +1,121 @@
+package wayland
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nyctal/model"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxDisplays is the number of wayland-N sockets tried by NewAutoServer (the same as libwayland)
+const maxDisplays = 32
+
+// errInUse is returned by lockSocket when another compositor holds the lock
+var errInUse = errors.New("in use by another compositor")
+
+// runtimeDir returns $XDG_RUNTIME_DIR, which is where clients look for the display socket
+func runtimeDir() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return "", fmt.Errorf("XDG_RUNTIME_DIR is not set")
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("XDG_RUNTIME_DIR is not an absolute path: %s", dir)
+	}
+	return dir, nil
+}
+
+// lockSocket takes an exclusive lock on <socket>.lock, returning the fd holding the lock. If another
+// process holds the lock the socket is in use. Once the lock is held any existing socket must be
+// stale (left behind by a compositor that exited without cleaning up) and is removed.
+func lockSocket(socket string) (int, error) {
+	lockFd, err := unix.Open(socket+".lock", unix.O_CREAT|unix.O_RDWR|unix.O_CLOEXEC, 0660)
+	if err != nil {
+		return -1, fmt.Errorf("could not open lock file %s.lock: %v", socket, err)
+	}
+	if err := unix.Flock(lockFd, unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		unix.Close(lockFd)
+		return -1, fmt.Errorf("%s is %w", socket, errInUse)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		unix.Close(lockFd)
+		return -1, fmt.Errorf("could not remove stale socket %s: %v", socket, err)
+	}
+	return lockFd, nil
+}
+
+// NewNamedServer creates a server listening on $XDG_RUNTIME_DIR/<name> (i.e. WAYLAND_DISPLAY=<name>),
+// guarded by a lock file so that running compositors are never disturbed
+func NewNamedServer(name string, workspace model.Workspace) (*WaylandServer, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return nil, err
+	}
+	socket := filepath.Join(dir, name)
+	lockFd, err := lockSocket(socket)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := NewServer(socket, workspace)
+	if err != nil {
+		unix.Close(lockFd)
+		return nil, err
+	}
+	ws.display = name
+	ws.lockFd = lockFd
+	return ws, nil
+}
+
+// NewAutoServer creates a server listening on the first free $XDG_RUNTIME_DIR/wayland-N, the
+// chosen name is available from Display
+func NewAutoServer(workspace model.Workspace) (*WaylandServer, error) {
+	if _, err := runtimeDir(); err != nil {
+		return nil, err
+	}
+	// sockets held by other compositors are expected, anything else is reported
+	var last error
+	for i := 0; i < maxDisplays; i++ {
+		ws, err := NewNamedServer(fmt.Sprintf("wayland-%d", i), workspace)
+		if err == nil {
+			return ws, nil
+		}
+		if !errors.Is(err, errInUse) {
+			last = err
+		}
+	}
+	if last != nil {
+		return nil, fmt.Errorf("no free wayland-N socket in XDG_RUNTIME_DIR: %w", last)
+	}
+	return nil, fmt.Errorf("no free wayland-N socket in XDG_RUNTIME_DIR")
+}
+
+// Display returns the value clients should use for WAYLAND_DISPLAY
+func (ws *WaylandServer) Display() string {
+	if ws.display != "" {
+		return ws.display
+	}
+	return ws.socket
+}
+
+// Close stops accepting clients, removing the socket and its lock file. Clients that are
+// already connected are left alone, see Shutdown.
+func (ws *WaylandServer) Close() error {
+	ws.closeOnce.Do(func() {
+		ws.closing.Store(true)
+		// closing the listener also removes the socket
+		ws.closeErr = ws.l.Close()
+		if ws.lockFd >= 0 {
+			os.Remove(ws.socket + ".lock")
+			unix.Close(ws.lockFd)
+			ws.lockFd = -1
+		}
+		if el := ws.loop.Load(); el != nil {
+			el.wake()
+		}
+	})
+	return ws.closeErr
+}
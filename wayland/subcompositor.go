@@ -14,26 +14,32 @@ type SubCompositor struct {
 func (u *SubCompositor) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlSubcompositorRequestDestroy:
 		// destroy
 		return nil
-	case 1:
-		newId := NewUintField()
-		surface := NewUintField()
-		parent := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId, surface, parent); err != nil {
+	case WlSubcompositorRequestGetSubsurface:
+		req, err := ParseWlSubcompositorGetSubsurfaceRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), "compositor", fmt.Sprintf("create_subsurface#%d", newId))
+		utils.Debug(int(wsc.id), "compositor", fmt.Sprintf("create_subsurface#%d", req.Id))
 
-		if surface, err := wsc.registry.Get(uint32(*surface)); err == nil {
+		if surface, err := wsc.registry.Get(req.Surface); err == nil {
 			if surfaceObj, ok := surface.(*Surface); ok {
 
-				if parentsurface, err := wsc.registry.Get(uint32(*parent)); err == nil {
+				if parentsurface, err := wsc.registry.Get(req.Parent); err == nil {
 					if parentsurfaceObj, ok := parentsurface.(*Surface); ok {
-
-						subSurface := &SubSurface{server: u.server, id: uint32(*newId), surface: surfaceObj, parent: parentsurfaceObj}
-						wsc.registry.New(uint32(*newId), subSurface)
+						if surfaceObj == parentsurfaceObj {
+							return NewProtocolError(packet.Address, WlSubcompositorErrorBadParent, "wl_surface#%d cannot be its own parent", req.Surface)
+						}
+						if !surfaceObj.SetRole(WlSubsurfaceInterface) {
+							return NewProtocolError(packet.Address, WlSubcompositorErrorBadSurface, "wl_surface#%d already has the %s role", req.Surface, surfaceObj.role)
+						}
+
+						subSurface := &SubSurface{server: u.server, id: req.Id, surface: surfaceObj, parent: parentsurfaceObj}
+						if err := wsc.registry.New(req.Id, subSurface, wsc.registry.Version(packet.Address)); err != nil {
+							return err
+						}
 						parentsurfaceObj.AddSubSurface(subSurface)
 						return nil
 					}
@@ -41,8 +47,8 @@ func (u *SubCompositor) HandleMessage(wsc *WaylandServerConn, packet *WaylandMes
 			}
 		}
 
-		return fmt.Errorf("could not set up subsurface")
+		return InvalidObject(packet, req.Surface, WlSurfaceInterface)
 	default:
-		return fmt.Errorf("unknown opcode called on unbound object: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlSubcompositorInterface)
 	}
 }
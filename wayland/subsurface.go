@@ -20,44 +20,41 @@ type SubSurface struct {
 func (u *SubSurface) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case WlSubsurfaceRequestDestroy:
 		// destroy
 		return nil
-	case 1:
-		x := NewUintField()
-		y := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x, y); err != nil {
+	case WlSubsurfaceRequestSetPosition:
+		req, err := ParseWlSubsurfaceSetPositionRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 		utils.Debug(int(wsc.id), fmt.Sprintf("subsurface#%d", u.id), fmt.Sprintf("set_position#%d", u.id))
 
-		u.position = image.Pt(int(*x), int(*y))
+		u.position = image.Pt(int(req.X), int(req.Y))
 		return nil
-	case 2:
+	case WlSubsurfaceRequestPlaceAbove:
 		// place above
-		ref := NewUintField()
-		if err := ParsePacketStructure(packet.Data, ref); err != nil {
+		if _, err := ParseWlSubsurfacePlaceAboveRequest(wsc, packet); err != nil {
 			return err
 		}
 		utils.Debug(int(wsc.id), fmt.Sprintf("subsurface#%d", u.id), fmt.Sprintf("place_above#%d", u.id))
 		return nil
-	case 3:
+	case WlSubsurfaceRequestPlaceBelow:
 		// place below
-		ref := NewUintField()
-		if err := ParsePacketStructure(packet.Data, ref); err != nil {
+		if _, err := ParseWlSubsurfacePlaceBelowRequest(wsc, packet); err != nil {
 			return err
 		}
 		utils.Debug(int(wsc.id), fmt.Sprintf("subsurface#%d", u.id), fmt.Sprintf("place_below#%d", u.id))
 		return nil
-	case 4:
+	case WlSubsurfaceRequestSetSync:
 		u.synced = true
 		utils.Debug(int(wsc.id), fmt.Sprintf("subsurface#%d", u.id), fmt.Sprintf("set_synced#%d", u.id))
 		return nil
-	case 5:
+	case WlSubsurfaceRequestSetDesync:
 		u.synced = false
 		utils.Debug(int(wsc.id), fmt.Sprintf("subsurface#%d", u.id), fmt.Sprintf("set_desynced#%d", u.id))
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on unbound object: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlSubsurfaceInterface)
 	}
 }
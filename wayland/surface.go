@@ -3,6 +3,7 @@ package wayland
 import (
 	"fmt"
 	"image"
+	"sync"
 	"time"
 
 	"nyctal/model"
@@ -11,19 +12,77 @@ import (
 
 type Surface struct {
 	BaseObject
-	id            uint32
-	frameCallback utils.Queue[uint32]
+	id  uint32
+	wsc *WaylandServerConn
 
-	attached bool
-	pending  *Buffer
-
-	commitedInputRegion *Region
-	pendingInputRegion  *Region
+	// pending is set by requests and applied by commit, which replaces current with a new snapshot
+	pending surfaceState
+	lock    sync.Mutex
+	current *surfaceSnapshot
+	// frameCallback holds the callbacks of committed frames, which are done once the surface is rendered
+	frameCallback utils.Queue[*Callback]
 
 	children []*SubSurface
-	cached   *model.BGRA
-	damage   []image.Rectangle
-	first    bool
+	role     string
+	// outputs the surface has been sent enter for, guarded by lock
+	outputs map[*Output]bool
+	// unmapped is set once the surface has left its outputs, until it is committed with content again
+	unmapped bool
+}
+
+// surfaceState is the double-buffered state of a surface. The attached buffer, offset, damage and frame
+// callbacks only apply to the next commit, everything else carries over until it is set again.
+type surfaceState struct {
+	attached       bool // attach was sent, with buffer nil removing the content
+	buffer         *Buffer
+	offset         image.Point
+	damage         []image.Rectangle // surface coordinates
+	bufferDamage   []image.Rectangle // buffer coordinates
+	inputRegion    *Region
+	opaqueRegion   *Region
+	scale          int32
+	transform      model.Transform
+	frameCallbacks []*Callback
+}
+
+// surfaceSnapshot is the state of a surface as of its last commit. It is never modified once made, so the
+// renderer can use it without holding the surface lock.
+type surfaceSnapshot struct {
+	buffer       *model.BGRA       // the committed buffer contents, nil if the surface has no content
+	image        *model.BGRA       // buffer after the transform and scale are undone, at the surface size
+	opaque       bool              // the buffer format has no alpha, so all of image is opaque
+	offset       image.Point       // position of the image relative to the surface origin
+	damage       []image.Rectangle // surface coordinates, the area changed by the commit
+	inputRegion  *Region           // nil accepts input everywhere
+	opaqueRegion *Region           // nil if the surface is not known to be opaque anywhere
+	scale        int32
+	transform    model.Transform
+}
+
+// size is the memory held by the copies in the snapshot, which is charged to the client's shm limit
+func (s *surfaceSnapshot) size() int64 {
+	var size int64
+	if s.buffer != nil {
+		size += int64(len(s.buffer.Pix))
+	}
+	if s.image != nil && s.image != s.buffer {
+		size += int64(len(s.image.Pix))
+	}
+	return size
+}
+
+func NewSurface(id uint32, wsc *WaylandServerConn) *Surface {
+	return &Surface{id: id, wsc: wsc, pending: surfaceState{scale: 1}, current: &surfaceSnapshot{scale: 1}}
+}
+
+// SetRole assigns a role (e.g. xdg_toplevel, wl_subsurface) to the surface. A surface can only
+// ever have one role, setting the same role again is permitted.
+func (u *Surface) SetRole(role string) bool {
+	if u.role != "" && u.role != role {
+		return false
+	}
+	u.role = role
+	return true
 }
 
 func (u *Surface) AddSubSurface(child_surface *SubSurface) {
@@ -31,87 +90,256 @@ func (u *Surface) AddSubSurface(child_surface *SubSurface) {
 }
 
 func (u *Surface) Destroy() {
-	u.cached = nil
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.wsc.releaseSHM(u.current.size())
+	u.current = &surfaceSnapshot{scale: 1}
+	u.outputs = nil
+	u.unmapped = true
 }
 
-func (u *Surface) RenderBuffer() {
-	// Committing a pending wl_buffer allows the compositor to read the
-	// pixels in the wl_buffer. The compositor may access the pixels at
-	// any time after the wl_surface.commit request. When the compositor
-	// will not access the pixels anymore, it will send the
-	// wl_buffer.release event. Only after receiving wl_buffer.release,
-	// the client may reuse the wl_buffer. A wl_buffer that has been
-	// attached and then replaced by another attach instead of committed
-	// will not receive a release event, and is not used by the
-	// compositor.
-	if u.pending != nil {
-		u.read_buffer()
-		u.pending.Destroy()
-		u.pending = nil
-	}
+// Snapshot returns the state of the last commit
+func (u *Surface) Snapshot() *surfaceSnapshot {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.current
 }
 
-func (u *Surface) read_buffer() *model.BGRA {
+// commit applies the pending state, replacing the current snapshot
+func (u *Surface) commit(wsc *WaylandServerConn) error {
+	previous := u.Snapshot()
+	next := &surfaceSnapshot{
+		buffer:       previous.buffer,
+		image:        previous.image,
+		opaque:       previous.opaque,
+		offset:       previous.offset.Add(u.pending.offset),
+		inputRegion:  u.pending.inputRegion,
+		opaqueRegion: u.pending.opaqueRegion,
+		scale:        u.pending.scale,
+		transform:    u.pending.transform,
+	}
 
-	if u.pending != nil && u.pending.format == model.FormatARGB {
+	buffer := u.pending.buffer
+	if buffer != nil && buffer.isDestroyed() {
+		buffer = nil
+	}
+	// the size of the buffer the surface will show, which must be a multiple of the scale
+	bufferSize := image.Rectangle{}
+	if u.pending.attached && buffer != nil {
+		bufferSize = image.Rect(0, 0, int(buffer.width), int(buffer.height))
+	} else if !u.pending.attached && previous.buffer != nil {
+		bufferSize = previous.buffer.Bounds()
+	}
+	scale := int(next.scale)
+	if bufferSize.Dx()%scale != 0 || bufferSize.Dy()%scale != 0 {
+		return NewProtocolError(u.id, WlSurfaceErrorInvalidSize, "buffer size %dx%d is not a multiple of scale %d", bufferSize.Dx(), bufferSize.Dy(), scale)
+	}
 
-		wl_pool := u.pending.backingPool
-		if wl_pool != nil && wl_pool.mappedData != nil {
+	// damage can be given in either coordinate space, the buffer is updated in buffer coordinates and the
+	// renderer is told about surface coordinates
+	next.damage = append(next.damage, u.pending.damage...)
+	for _, damage := range u.pending.bufferDamage {
+		next.damage = append(next.damage, bufferToSurface(damage, bufferSize, next.transform, scale))
+	}
 
-			bounds := image.Rect(0, 0, int(u.pending.width), int(u.pending.height))
-			if u.cached == nil || len(u.damage) == 0 || bounds != u.cached.Bounds() {
-				if bounds.Dy()*int(u.pending.stride) > 2048*2048*16 {
-					return nil
-				}
+	if u.pending.attached {
+		if buffer != nil {
+			// Committing a pending wl_buffer allows the compositor to read the
+			// pixels in the wl_buffer. The compositor may access the pixels at
+			// any time after the wl_surface.commit request. When the compositor
+			// will not access the pixels anymore, it will send the
+			// wl_buffer.release event. Only after receiving wl_buffer.release,
+			// the client may reuse the wl_buffer.
+			if wsc.capturing() {
+				wsc.captureBuffer(buffer)
+			}
+			damage := append([]image.Rectangle{}, u.pending.bufferDamage...)
+			for _, d := range u.pending.damage {
+				damage = append(damage, surfaceToBuffer(d, bufferSize, next.transform, scale))
+			}
+			// the contents are copied, so the client can reuse the buffer straight away
+			img, err := u.read_buffer(wsc, buffer, previous.buffer, damage)
+			// released even if it could not be read, the client is disconnected with the error
+			buffer.release()
+			if err != nil {
+				return err
+			}
+			next.buffer = img
+			next.opaque = buffer.format.Opaque()
+		} else {
+			// If wl_surface.attach is sent with a NULL wl_buffer, the
+			// following wl_surface.commit will remove the surface content.
+			next.buffer = nil
+			next.opaque = false
+		}
+	}
+	if next.buffer == nil {
+		next.image = nil
+	} else if next.scale != previous.scale || next.transform != previous.transform {
+		next.image = next.buffer.Transformed(next.transform, scale)
+	} else if next.buffer != previous.buffer {
+		next.image = u.transform_buffer(next, previous, scale)
+	}
+	// the copies replace those of the previous commit
+	if err := wsc.reserveSHM(next.size() - previous.size()); err != nil {
+		return err
+	}
 
-				img := model.NewBGRA(wl_pool.mappedData[u.pending.offset:], bounds, int(u.pending.stride))
-				u.cached = img
+	u.lock.Lock()
+	u.current = next
+	for _, cb := range u.pending.frameCallbacks {
+		u.frameCallback.Push(cb)
+	}
+	if next.image != nil {
+		u.unmapped = false
+	}
+	u.lock.Unlock()
+	if next.image == nil && previous.image != nil {
+		u.unmap(wsc)
+	}
 
-			} else {
+	// After commit, there is no pending buffer until the next attach.
+	u.pending.attached = false
+	u.pending.buffer = nil
+	u.pending.offset = image.Point{}
+	u.pending.damage = nil
+	u.pending.bufferDamage = nil
+	u.pending.frameCallbacks = nil
+	return nil
+}
 
-				for _, damage := range u.damage {
-					if damage.Min.Y < 0 {
-						damage.Min.Y = 0
-					}
+// surfaceToBuffer converts damage in surface coordinates to the coordinates of a buffer of the given size
+func surfaceToBuffer(damage image.Rectangle, buffer image.Rectangle, transform model.Transform, scale int) image.Rectangle {
+	width, height := transform.Invert().Size(buffer.Dx()/scale, buffer.Dy()/scale)
+	damage = damage.Intersect(image.Rect(0, 0, width, height))
+	if damage.Empty() {
+		return image.Rectangle{}
+	}
+	r := transform.Rect(damage, width, height)
+	return image.Rect(r.Min.X*scale, r.Min.Y*scale, r.Max.X*scale, r.Max.Y*scale)
+}
 
-					if damage.Max.Y > int(u.pending.height) {
-						damage.Max.Y = int(u.pending.height)
-					}
+// bufferToSurface converts damage in the coordinates of a buffer of the given size to surface coordinates,
+// rounding outwards where the damage does not line up with the scale
+func bufferToSurface(damage image.Rectangle, buffer image.Rectangle, transform model.Transform, scale int) image.Rectangle {
+	damage = damage.Intersect(buffer)
+	if damage.Empty() {
+		return image.Rectangle{}
+	}
+	r := image.Rect(damage.Min.X/scale, damage.Min.Y/scale, (damage.Max.X+scale-1)/scale, (damage.Max.Y+scale-1)/scale)
+	return transform.Invert().Rect(r, buffer.Dx()/scale, buffer.Dy()/scale)
+}
 
-					if damage.Min.X < 0 {
-						damage.Min.X = 0
-					}
+// read_buffer copies the contents of buffer into a new image, only updating the damaged areas of
+// previous if it is the same size. The copy is charged to the client's shm limit by commit, a buffer
+// larger than the whole limit is refused before it is copied.
+func (u *Surface) read_buffer(wsc *WaylandServerConn, buffer *Buffer, previous *model.BGRA, damage []image.Rectangle) (*model.BGRA, error) {
 
-					if damage.Max.X > int(u.pending.width) {
-						damage.Max.X = int(u.pending.width)
-					}
-					u.cached.Update(wl_pool.mappedData[u.pending.offset:], damage, int(u.pending.stride))
-				}
-			}
-			return u.cached
+	wl_pool := buffer.backingPool
+	if wl_pool == nil || wl_pool.mappedData == nil {
+		return nil, NewProtocolError(1, WlDisplayErrorImplementation, "wl_buffer#%d has no pool to read from", buffer.id)
+	}
+
+	bounds := image.Rect(0, 0, int(buffer.width), int(buffer.height))
+	if limit := wsc.limits.MaxSHMBytes; limit > 0 && int64(bounds.Dy())*int64(buffer.stride) > limit {
+		return nil, NewProtocolError(1, WlDisplayErrorNoMemory, "wl_buffer#%d is too large to copy (limit %d bytes)", buffer.id, limit)
+	}
+	if previous == nil || len(damage) == 0 || bounds != previous.Bounds() {
+		return model.NewBGRAFormat(wl_pool.mappedData[buffer.offset:], bounds, int(buffer.stride), buffer.format), nil
+	}
+
+	// the previous image may still be being drawn, so the damage is applied to a copy
+	img := previous.Clone()
+	for _, damage := range damage {
+		img.UpdateFormat(wl_pool.mappedData[buffer.offset:], damage, int(buffer.stride), buffer.format)
+	}
+	return img, nil
+}
+
+// transform_buffer returns the image of next, only redrawing the damaged areas of the image of previous
+// if the buffer was updated in place (see read_buffer)
+func (u *Surface) transform_buffer(next, previous *surfaceSnapshot, scale int) *model.BGRA {
+	if previous.buffer == nil || previous.image == nil || previous.image == previous.buffer ||
+		len(next.damage) == 0 || next.buffer.Bounds() != previous.buffer.Bounds() {
+		return next.buffer.Transformed(next.transform, scale)
+	}
+	// the previous image may still be being drawn, so the damage is applied to a copy
+	img := previous.image.Clone()
+	for _, damage := range next.damage {
+		next.buffer.UpdateTransformed(img, next.transform, scale, damage)
+	}
+	return img
+}
+
+// updateOutputs sends enter and leave as the area the surface is drawn at (in workspace coordinates) moves
+// onto and off outputs, it is called whenever the surface is rendered
+func (u *Surface) updateOutputs(wsc *WaylandServerConn, area image.Rectangle) {
+	bound := wsc.registry.Outputs()
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.unmapped {
+		return
+	}
+	visible := make(map[*Output]bool)
+	for _, output := range bound {
+		if output.head.Overlaps(area) {
+			visible[output] = true
 		}
 	}
-	return nil
+	for _, output := range bound {
+		if u.outputs[output] && !visible[output] {
+			utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("leave output#%d", output.id))
+			SendWlSurfaceLeave(wsc, u.id, output.id)
+		}
+	}
+	for output := range visible {
+		if !u.outputs[output] {
+			utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("enter output#%d", output.id))
+			SendWlSurfaceEnter(wsc, u.id, output.id)
+		}
+	}
+	// outputs that have been released are forgotten without a leave
+	u.outputs = visible
 }
 
-func (u *Surface) RenderFrame(wsc *WaylandServerConn, serial []byte) []byte {
+// leaveOutputs sends leave for the outputs the surface is on that match, outputs the client has since
+// released are forgotten without a leave
+func (u *Surface) leaveOutputs(wsc *WaylandServerConn, match func(*Output) bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	for output := range u.outputs {
+		if !match(output) {
+			continue
+		}
+		delete(u.outputs, output)
+		if obj, err := wsc.registry.Get(output.id); err != nil || obj != output {
+			continue
+		}
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("leave output#%d", output.id))
+		SendWlSurfaceLeave(wsc, u.id, output.id)
+	}
+}
 
-	for !u.frameCallback.Empty() {
-		nullserial := uint32(time.Now().UnixMilli())
+// unmap leaves every output, the surface is not entered again until it is committed with content
+func (u *Surface) unmap(wsc *WaylandServerConn) {
+	u.leaveOutputs(wsc, func(*Output) bool { return true })
+	u.lock.Lock()
+	u.unmapped = true
+	u.lock.Unlock()
+}
 
-		cb, _ := u.frameCallback.Pop()
-		wsc.SendMessage(
-			NewPacketBuilder(cb, 0x00).
-				WithUint(nullserial).
-				Build())
+func (u *Surface) RenderFrame(wsc *WaylandServerConn, serial []byte) []byte {
 
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("callback frame#%d", u.frameCallback))
+	u.lock.Lock()
+	callbacks := u.frameCallback.Inner()
+	u.frameCallback = utils.Queue[*Callback]{}
+	u.lock.Unlock()
 
-		wsc.SendMessage(
-			NewPacketBuilder(0x01, 0x01).
-				WithUint(cb).
-				Build())
+	for _, cb := range callbacks {
+		nullserial := uint32(time.Now().UnixMilli())
+		cb.Done(wsc, nullserial)
 
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("callback frame#%d", cb.id))
 	}
 
 	return serial
@@ -120,123 +348,151 @@ func (u *Surface) RenderFrame(wsc *WaylandServerConn, serial []byte) []byte {
 func (u *Surface) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-		// destroy
+	case WlSurfaceRequestDestroy:
+		// destroy, along with any frame callbacks that will now never be done
+		u.lock.Lock()
+		callbacks := append(u.frameCallback.Inner(), u.pending.frameCallbacks...)
+		u.frameCallback = utils.Queue[*Callback]{}
+		u.lock.Unlock()
+		u.pending.frameCallbacks = nil
+		for _, cb := range callbacks {
+			wsc.registry.Destroy(cb.id)
+		}
 		wsc.registry.Destroy(u.id)
 		return nil
-	case 1:
+	case WlSurfaceRequestAttach:
 		// Set a buffer as the content of this surface.
 		// Surface contents are double-buffered state, see wl_surface.commit.
-		bufferId := NewUintField()
-		x := NewIntField()
-		y := NewIntField()
-		if err := ParsePacketStructure(packet.Data, bufferId, x, y); err != nil {
+		req, err := ParseWlSurfaceAttachRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		if uint32(*bufferId) == 0 {
+		// since version 5 the offset is set with wl_surface.offset instead
+		if (req.X != 0 || req.Y != 0) && wsc.registry.Version(u.id) >= 5 {
+			return NewProtocolError(u.id, WlSurfaceErrorInvalidOffset, "attach with non-zero offset %d,%d", req.X, req.Y)
+		}
+
+		if req.Buffer == 0 {
 			// If wl_surface.attach is sent with a NULL wl_buffer, the
 			// following wl_surface.commit will remove the surface content.
-			u.pending = nil
-			u.attached = true
+			u.pending.buffer = nil
+			u.pending.attached = true
 			return nil
 		}
 
-		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("attach_buffer#%d %d %d", *bufferId, *x, *y))
-		if obj, err := wsc.registry.Get(uint32(*bufferId)); err == nil {
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("attach_buffer#%d %d %d", req.Buffer, req.X, req.Y))
+		if obj, err := wsc.registry.Get(req.Buffer); err == nil {
 			if buffer, ok := obj.(*Buffer); ok {
-				u.pending = buffer
-				u.attached = true
+				u.pending.buffer = buffer
+				u.pending.attached = true
+				u.pending.offset = image.Pt(int(req.X), int(req.Y))
 				return nil
 			}
 		}
 
-		return fmt.Errorf("failed to attach buffer: unknown buffer")
-	case 2:
-		x := NewIntField()
-		y := NewIntField()
-		w := NewIntField()
-		h := NewIntField()
-		if err := ParsePacketStructure(packet.Data, x, y, w, h); err != nil {
+		return InvalidObject(packet, req.Buffer, WlBufferInterface)
+	case WlSurfaceRequestDamage:
+		req, err := ParseWlSurfaceDamageRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("damage %d %d %d %d", *x, *y, *w, *h))
-		u.damage = append(u.damage, image.Rect(int(*x), int(*y), int(*x)+int(*w), int(*y)+int(*h)))
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("damage %d %d %d %d", req.X, req.Y, req.Width, req.Height))
+		u.pending.damage = append(u.pending.damage, image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height)))
 		return nil
-	case 3:
-		newId := NewUintField()
-		if err := ParsePacketStructure(packet.Data, newId); err != nil {
+	case WlSurfaceRequestFrame:
+		req, err := ParseWlSurfaceFrameRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("frame_callback#%d", req.Callback))
+		callback := &Callback{id: req.Callback}
+		if err := wsc.registry.New(req.Callback, callback, 1); err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("frame_callback#%d", *newId))
-		u.frameCallback.Push(uint32(*newId))
+		u.pending.frameCallbacks = append(u.pending.frameCallbacks, callback)
 		return nil
-	case 4:
-		regionId := NewUintField()
-		if err := ParsePacketStructure(packet.Data, regionId); err != nil {
+	case WlSurfaceRequestSetInputRegion:
+		req, err := ParseWlSurfaceSetInputRegionRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("set_input_region#%d", *regionId))
-		rid := uint32(*regionId)
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("set_input_region#%d", req.Region))
+		rid := req.Region
 		if rid == 0 {
-			u.pendingInputRegion = nil
+			u.pending.inputRegion = nil
 			return nil
 		} else {
 			if obj, err := wsc.registry.Get(rid); err == nil {
 				if region, ok := obj.(*Region); ok {
-					u.pendingInputRegion = region
+					u.pending.inputRegion = region.Copy()
 					return nil
 				}
 			}
 		}
-		return fmt.Errorf("unknown region reference")
-	case 5:
-		// we ignore all opauqe region hints...
-		return nil
-	case 7:
-		// set buffer something...
+		return InvalidObject(packet, rid, WlRegionInterface)
+	case WlSurfaceRequestSetOpaqueRegion:
+		req, err := ParseWlSurfaceSetOpaqueRegionRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("set_opaque_region#%d", req.Region))
+		if req.Region == 0 {
+			u.pending.opaqueRegion = nil
+			return nil
+		}
+		if obj, err := wsc.registry.Get(req.Region); err == nil {
+			if region, ok := obj.(*Region); ok {
+				u.pending.opaqueRegion = region.Copy()
+				return nil
+			}
+		}
+		return InvalidObject(packet, req.Region, WlRegionInterface)
+	case WlSurfaceRequestSetBufferTransform:
+		req, err := ParseWlSurfaceSetBufferTransformRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		transform := model.Transform(req.Transform)
+		if !transform.Valid() {
+			return NewProtocolError(u.id, WlSurfaceErrorInvalidTransform, "invalid buffer transform %d", req.Transform)
+		}
+		u.pending.transform = transform
 		return nil
-	case 8:
+	case WlSurfaceRequestSetBufferScale:
+		req, err := ParseWlSurfaceSetBufferScaleRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		if req.Scale < 1 {
+			return NewProtocolError(u.id, WlSurfaceErrorInvalidScale, "invalid buffer scale %d", req.Scale)
+		}
+		u.pending.scale = req.Scale
 		return nil
-	case 6:
-		u.commitedInputRegion = u.pendingInputRegion
-
-		// received create pool message...
-		if u.pending != nil {
-
-		} else if u.attached {
-			// If wl_surface.attach is sent with a NULL wl_buffer, the
-			// following wl_surface.commit will remove the surface content.
-			u.cached = nil
+	case WlSurfaceRequestCommit:
+		if err := u.commit(wsc); err != nil {
+			return err
 		}
 
-		// After commit, there is no pending buffer until the next attach.
-		u.attached = false
-
 		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), "commit")
 
-		// // Pretend we are entering a surface....
-		// if !u.first {
-		// 	u.first = true
-		// 	output := wsc.registry.FindOutput()
-		// 	if output != nil && !u.first {
-		// 		u.first = true
-		// 		wsc.SendMessage(
-		// 			NewPacketBuilder(u.id, 0x00).WithUint(output.id).
-		// 				Build())
-		// 	}
-		// 	dd := wsc.registry.FindDataDevice()
-		// 	if dd != nil {
-		// 		dd.Selection(wsc)
-		// 	}
-		// }
-
 		return nil
-	case 9:
+	case WlSurfaceRequestDamageBuffer:
+		req, err := ParseWlSurfaceDamageBufferRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		utils.Debug(int(wsc.id), fmt.Sprintf("surface#%d", u.id), fmt.Sprintf("damage_buffer %d %d %d %d", req.X, req.Y, req.Width, req.Height))
+		u.pending.bufferDamage = append(u.pending.bufferDamage, image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height)))
 		return nil
-	case 10:
+	case WlSurfaceRequestOffset:
+		req, err := ParseWlSurfaceOffsetRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		u.pending.offset = image.Pt(int(req.X), int(req.Y))
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on surface: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlSurfaceInterface)
 	}
 }
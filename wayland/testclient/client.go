@@ -0,0 +1,442 @@
+// Package testclient is a minimal wayland client for exercising the compositor from go tests. Requests
+// and events are encoded using the interface specs generated for package wayland, so any request can be
+// sent by name and every event received is recorded for assertions.
+//
+// As testclient imports wayland, tests using it from within package wayland must be external tests
+// (package wayland_test).
+package testclient
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"nyctal/utils"
+	"nyctal/wayland"
+
+	"golang.org/x/sys/unix"
+)
+
+// Event is an event received from the compositor, Args holds one value per argument of the event:
+// int32 (int), uint32 (uint, object, new_id), float32 (fixed), string or nil (string), []byte (array)
+// and int (fd)
+type Event struct {
+	Object    uint32
+	Interface string
+	Name      string
+	Args      []any
+}
+
+// Global is a global announced by wl_registry
+type Global struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+}
+
+// Fd marks a request argument as a file descriptor to send
+type Fd int
+
+type Client struct {
+	file *os.File
+	fd   int
+
+	nextId  uint32
+	objects map[uint32]string
+	bound   map[string]uint32
+
+	in      []byte
+	fds     *utils.Queue[int]
+	oob     []byte
+	events  []Event
+	pending []Event
+	err     error
+
+	// Registry is the wl_registry object, Globals are the globals it has announced
+	Registry uint32
+	Globals  []Global
+}
+
+// Connect connects a new in-process client to the compositor, it fetches the registry so that Globals is populated
+func Connect(ws *wayland.WaylandServer) (*Client, error) {
+	file, _, err := ws.ConnectClient()
+	if err != nil {
+		return nil, err
+	}
+	c, err := New(file, 2*time.Second)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := c.GetRegistry(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// New creates a client on an already connected socket (e.g. from WAYLAND_SOCKET). Waiting for events
+// fails if nothing is received within timeout.
+func New(file *os.File, timeout time.Duration) (*Client, error) {
+	fd := int(file.Fd())
+	tv := unix.NsecToTimeval(timeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return nil, fmt.Errorf("could not set timeout: %v", err)
+	}
+	return &Client{
+		file:    file,
+		fd:      fd,
+		nextId:  2,
+		objects: map[uint32]string{1: wayland.WlDisplayInterface},
+		bound:   make(map[string]uint32),
+		fds:     utils.NewQueue[int](),
+		oob:     make([]byte, unix.CmsgSpace(28*4)),
+	}, nil
+}
+
+func (c *Client) Close() error {
+	for !c.fds.Empty() {
+		fd, _ := c.fds.Pop()
+		unix.Close(fd)
+	}
+	return c.file.Close()
+}
+
+// NewObject allocates an id for a new object of the given interface, to be passed as a new_id argument
+func (c *Client) NewObject(iface string) uint32 {
+	id := c.nextId
+	c.nextId += 1
+	c.objects[id] = iface
+	return id
+}
+
+// Interface returns the interface of a live object
+func (c *Client) Interface(id uint32) string {
+	return c.objects[id]
+}
+
+// Request sends the named request to an object. Arguments are given in protocol order, with an untyped new_id
+// (e.g. wl_registry.bind) taking three arguments: the interface, the version and the id.
+func (c *Client) Request(id uint32, name string, args ...any) error {
+	iface, ok := c.objects[id]
+	if !ok {
+		return fmt.Errorf("unknown object %d", id)
+	}
+	spec, ok := wayland.LookupInterface(iface)
+	if !ok {
+		return fmt.Errorf("unknown interface %s", iface)
+	}
+	opcode := -1
+	for i, msg := range spec.Requests {
+		if msg.Name == name {
+			opcode = i
+		}
+	}
+	if opcode < 0 {
+		return fmt.Errorf("%s has no request %s", iface, name)
+	}
+
+	pb := wayland.NewPacketBuilder(id, uint16(opcode))
+	next := func() (any, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("not enough arguments for %s.%s", iface, name)
+		}
+		arg := args[0]
+		args = args[1:]
+		return arg, nil
+	}
+	for _, spec := range spec.Requests[opcode].Args {
+		arg, err := next()
+		if err != nil {
+			return err
+		}
+		switch spec.Type {
+		case "int":
+			v, err := toUint32(arg)
+			if err != nil {
+				return fmt.Errorf("%s: %v", spec.Name, err)
+			}
+			pb.WithInt(int32(v))
+		case "uint", "object":
+			v, err := toUint32(arg)
+			if err != nil {
+				return fmt.Errorf("%s: %v", spec.Name, err)
+			}
+			pb.WithUint(v)
+		case "new_id":
+			if spec.Interface != "" {
+				v, err := toUint32(arg)
+				if err != nil {
+					return fmt.Errorf("%s: %v", spec.Name, err)
+				}
+				pb.WithUint(v)
+				break
+			}
+			newIface, _ := arg.(string)
+			version, err := next()
+			if err != nil {
+				return err
+			}
+			newId, err := next()
+			if err != nil {
+				return err
+			}
+			v, err1 := toUint32(version)
+			i, err2 := toUint32(newId)
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("%s: invalid version or id", spec.Name)
+			}
+			pb.WithNewId(newIface, v, i)
+		case "fixed":
+			v := reflect.ValueOf(arg)
+			if !v.CanFloat() && !v.CanInt() {
+				return fmt.Errorf("%s: %T is not a number", spec.Name, arg)
+			}
+			if v.CanInt() {
+				pb.WithFixed(float32(v.Int()))
+			} else {
+				pb.WithFixed(float32(v.Float()))
+			}
+		case "string":
+			if arg == nil {
+				pb.WithNullableString(nil)
+			} else if str, ok := arg.(string); ok {
+				pb.WithString(str)
+			} else {
+				return fmt.Errorf("%s: %T is not a string", spec.Name, arg)
+			}
+		case "array":
+			arr, ok := arg.([]byte)
+			if !ok {
+				return fmt.Errorf("%s: %T is not a []byte", spec.Name, arg)
+			}
+			pb.WithArray(arr)
+		case "fd":
+			fd, ok := arg.(Fd)
+			if !ok {
+				return fmt.Errorf("%s: %T is not a Fd", spec.Name, arg)
+			}
+			pb.WithFd(int(fd))
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments for %s.%s", iface, name)
+	}
+
+	var oob []byte
+	if fds := pb.Fds(); len(fds) > 0 {
+		oob = unix.UnixRights(fds...)
+	}
+	if err := unix.Sendmsg(c.fd, pb.Build(), oob, nil, unix.MSG_NOSIGNAL); err != nil {
+		// the compositor disconnects clients after sending them an error, which is more useful to report
+		for c.err == nil && c.Dispatch() == nil {
+		}
+		if c.err != nil {
+			return c.err
+		}
+		return fmt.Errorf("could not send %s.%s: %v", iface, name, err)
+	}
+	if (name == "destroy" || name == "release") && c.bound[iface] == id {
+		delete(c.bound, iface)
+	}
+	return nil
+}
+
+func toUint32(arg any) (uint32, error) {
+	v := reflect.ValueOf(arg)
+	switch {
+	case v.CanUint():
+		return uint32(v.Uint()), nil
+	case v.CanInt():
+		return uint32(v.Int()), nil
+	}
+	return 0, fmt.Errorf("%T is not an integer", arg)
+}
+
+// Dispatch blocks until at least one event has been received (or the timeout expires), returning the
+// protocol error if the compositor has sent one
+func (c *Client) Dispatch() error {
+	if c.err != nil {
+		return c.err
+	}
+	buf := make([]byte, 4096)
+	n, oobn, _, _, err := unix.Recvmsg(c.fd, buf, c.oob, unix.MSG_CMSG_CLOEXEC)
+	if err == unix.EAGAIN {
+		return fmt.Errorf("timed out waiting for events")
+	}
+	if err != nil {
+		return fmt.Errorf("recvmsg: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("compositor closed the connection")
+	}
+	if oobn > 0 {
+		msgs, err := unix.ParseSocketControlMessage(c.oob[:oobn])
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			fds, err := unix.ParseUnixRights(&msg)
+			if err == nil {
+				for _, fd := range fds {
+					c.fds.Push(fd)
+				}
+			}
+		}
+	}
+	c.in = append(c.in, buf[:n]...)
+
+	for len(c.in) >= 8 {
+		size := int(binary.LittleEndian.Uint16(c.in[6:8]))
+		if size < 8 || len(c.in) < size {
+			break
+		}
+		ev, err := c.decode(binary.LittleEndian.Uint32(c.in), binary.LittleEndian.Uint16(c.in[4:6]), c.in[8:size])
+		c.in = c.in[size:]
+		if err != nil {
+			return err
+		}
+		c.events = append(c.events, ev)
+		c.pending = append(c.pending, ev)
+		if err := c.handle(ev); err != nil {
+			return err
+		}
+	}
+	return c.err
+}
+
+func (c *Client) decode(id uint32, opcode uint16, data []byte) (Event, error) {
+	ev := Event{Object: id, Interface: c.objects[id]}
+	spec, ok := wayland.LookupInterface(ev.Interface)
+	if !ok || int(opcode) >= len(spec.Events) {
+		return ev, fmt.Errorf("unknown event %d on %s#%d", opcode, ev.Interface, id)
+	}
+	msg := spec.Events[opcode]
+	ev.Name = msg.Name
+
+	fields := make([]wayland.Field, len(msg.Args))
+	for i, arg := range msg.Args {
+		switch arg.Type {
+		case "int":
+			fields[i] = wayland.NewIntField()
+		case "uint", "new_id":
+			fields[i] = wayland.NewUintField()
+		case "object":
+			fields[i] = wayland.NewNullableObjectField()
+		case "fixed":
+			fields[i] = wayland.NewFixedField()
+		case "string":
+			fields[i] = wayland.NewNullableStringField()
+		case "array":
+			fields[i] = wayland.NewArrayField()
+		case "fd":
+			fields[i] = wayland.NewFdField()
+		}
+	}
+	if err := wayland.ParsePacketStructureWithFds(data, c.fds, fields...); err != nil {
+		return ev, fmt.Errorf("could not parse %s.%s: %v", ev.Interface, ev.Name, err)
+	}
+	for i, field := range fields {
+		switch f := field.(type) {
+		case *wayland.IntField:
+			ev.Args = append(ev.Args, int32(*f))
+		case *wayland.UintField:
+			ev.Args = append(ev.Args, uint32(*f))
+			if msg.Args[i].Type == "new_id" {
+				c.objects[uint32(*f)] = msg.Args[i].Interface
+			}
+		case *wayland.NullableObjectField:
+			ev.Args = append(ev.Args, uint32(*f))
+		case *wayland.FixedField:
+			ev.Args = append(ev.Args, float32(*f))
+		case *wayland.NullableStringField:
+			if f.Valid {
+				ev.Args = append(ev.Args, f.Value)
+			} else {
+				ev.Args = append(ev.Args, nil)
+			}
+		case *wayland.ArrayField:
+			ev.Args = append(ev.Args, []byte(*f))
+		case *wayland.FdField:
+			ev.Args = append(ev.Args, int(*f))
+		}
+	}
+	return ev, nil
+}
+
+// handle keeps the client's state up to date, as libwayland and toolkits would
+func (c *Client) handle(ev Event) error {
+	switch ev.Interface + "." + ev.Name {
+	case "wl_display.error":
+		msg, _ := ev.Args[2].(string)
+		c.err = &wayland.ProtocolError{ObjectId: ev.Args[0].(uint32), Code: ev.Args[1].(uint32), Message: msg}
+	case "wl_display.delete_id":
+		delete(c.objects, ev.Args[0].(uint32))
+	case "wl_registry.global":
+		iface, _ := ev.Args[1].(string)
+		c.Globals = append(c.Globals, Global{Name: ev.Args[0].(uint32), Interface: iface, Version: ev.Args[2].(uint32)})
+	case "wl_registry.global_remove":
+		for i, global := range c.Globals {
+			if global.Name == ev.Args[0].(uint32) {
+				c.Globals = append(c.Globals[:i], c.Globals[i+1:]...)
+				break
+			}
+		}
+	case "xdg_wm_base.ping":
+		return c.Request(ev.Object, "pong", ev.Args[0])
+	}
+	return nil
+}
+
+// Events returns every event received so far
+func (c *Client) Events() []Event {
+	return c.events
+}
+
+// Take removes and returns the events received on object (or any object if 0) with the given name
+// that have not already been returned by Take or WaitFor
+func (c *Client) Take(object uint32, name string) []Event {
+	var taken []Event
+	pending := c.pending[:0]
+	for _, ev := range c.pending {
+		if (object == 0 || ev.Object == object) && ev.Name == name {
+			taken = append(taken, ev)
+		} else {
+			pending = append(pending, ev)
+		}
+	}
+	c.pending = pending
+	return taken
+}
+
+// WaitFor dispatches until an event with the given name is received on object (or any object if 0)
+func (c *Client) WaitFor(object uint32, name string) (Event, error) {
+	for {
+		for i, ev := range c.pending {
+			if (object == 0 || ev.Object == object) && ev.Name == name {
+				c.pending = append(c.pending[:i], c.pending[i+1:]...)
+				return ev, nil
+			}
+		}
+		if err := c.Dispatch(); err != nil {
+			return Event{}, fmt.Errorf("waiting for %s#%d.%s: %w", c.objects[object], object, name, err)
+		}
+	}
+}
+
+// Roundtrip waits until the compositor has handled every request sent so far
+func (c *Client) Roundtrip() error {
+	callback := c.NewObject(wayland.WlCallbackInterface)
+	if err := c.Request(1, "sync", callback); err != nil {
+		return err
+	}
+	_, err := c.WaitFor(callback, "done")
+	return err
+}
+
+// Err returns the protocol error sent by the compositor, if any
+func (c *Client) Err() error {
+	return c.err
+}
@@ -0,0 +1,228 @@
+package testclient
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+
+	"nyctal/model"
+	"nyctal/wayland"
+	"nyctal/workspace"
+)
+
+// newServer starts a compositor with a workspace that render draws a frame of
+func newServer(t *testing.T) (*wayland.WaylandServer, func()) {
+	t.Helper()
+	wspace := workspace.NewDragOverlay()
+	ws, err := wayland.NewServer(filepath.Join(t.TempDir(), "wayland-test"), wspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	render := func() {
+		wspace.Buffer(model.EmptyBGRA(image.Rect(0, 0, 200, 200)), 200, 200)
+	}
+	return ws, render
+}
+
+func connect(t *testing.T, ws *wayland.WaylandServer) *Client {
+	t.Helper()
+	c, err := Connect(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestGlobals(t *testing.T) {
+	ws, _ := newServer(t)
+	c := connect(t, ws)
+
+	for _, iface := range []string{wayland.WlCompositorInterface, wayland.WlShmInterface, wayland.WlSeatInterface, wayland.XdgWmBaseInterface, wayland.WlOutputInterface} {
+		if _, err := c.Global(iface); err != nil {
+			t.Errorf("binding %s: %v", iface, err)
+		}
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	shm, _ := c.Global(wayland.WlShmInterface)
+	if formats := c.Take(shm, "format"); len(formats) < 2 {
+		t.Errorf("expected argb8888 and xrgb8888 to be advertised, got %v", formats)
+	}
+	if c.Err() != nil {
+		t.Fatal(c.Err())
+	}
+}
+
+func TestToplevel(t *testing.T) {
+	ws, render := newServer(t)
+	c := connect(t, ws)
+
+	output, err := c.Global(wayland.WlOutputInterface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, err := c.CreateToplevel("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	// the toplevel is configured once the workspace lays it out
+	render()
+	if _, err := top.Configure(); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer, err := c.CreateBuffer(50, 50, 0xffff0000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := top.Attach(buffer, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := top.Damage(0, 0, 50, 50); err != nil {
+		t.Fatal(err)
+	}
+	frame, err := top.Frame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := top.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	// the contents are copied on commit, so the buffer is released straight away
+	if released := c.Take(buffer, "release"); len(released) != 1 {
+		t.Errorf("expected the buffer to be released once, got %v", released)
+	}
+	if done := c.Take(frame, "done"); len(done) != 0 {
+		t.Errorf("frame done before the surface was rendered: %v", done)
+	}
+
+	render()
+	if _, err := c.WaitFor(frame, "done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	enter := c.Take(top.Id, "enter")
+	if len(enter) != 1 || enter[0].Args[0] != output {
+		t.Errorf("expected the surface to enter wl_output#%d, got %v", output, enter)
+	}
+	if c.Err() != nil {
+		t.Fatal(c.Err())
+	}
+}
+
+// mapToplevel creates a toplevel showing a 50x50 buffer and renders it onto the output
+func mapToplevel(t *testing.T, c *Client, render func()) *XdgSurface {
+	t.Helper()
+	top, err := c.CreateToplevel("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	render()
+	if _, err := top.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	buffer, err := c.CreateBuffer(50, 50, 0xffff0000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := top.Attach(buffer, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := top.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Roundtrip(); err != nil {
+		t.Fatal(err)
+	}
+	render()
+	if _, err := c.WaitFor(top.Id, "enter"); err != nil {
+		t.Fatal(err)
+	}
+	return top
+}
+
+func TestLeave(t *testing.T) {
+	t.Run("unmap", func(t *testing.T) {
+		ws, render := newServer(t)
+		c := connect(t, ws)
+		output, err := c.Global(wayland.WlOutputInterface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		top := mapToplevel(t, c, render)
+		if err := top.Attach(0, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := top.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Roundtrip(); err != nil {
+			t.Fatal(err)
+		}
+		leave := c.Take(top.Id, "leave")
+		if len(leave) != 1 || leave[0].Args[0] != output {
+			t.Errorf("expected the surface to leave wl_output#%d, got %v", output, leave)
+		}
+	})
+	t.Run("destroy", func(t *testing.T) {
+		ws, render := newServer(t)
+		c := connect(t, ws)
+		if _, err := c.Global(wayland.WlOutputInterface); err != nil {
+			t.Fatal(err)
+		}
+		top := mapToplevel(t, c, render)
+		if err := c.Request(top.RoleId, "destroy"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Request(top.XdgId, "destroy"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Roundtrip(); err != nil {
+			t.Fatal(err)
+		}
+		render()
+		if err := c.Roundtrip(); err != nil {
+			t.Fatal(err)
+		}
+		if leave := c.Take(top.Id, "leave"); len(leave) != 1 {
+			t.Errorf("expected the surface to leave its output once, got %v", leave)
+		}
+		if enter := c.Take(top.Id, "enter"); len(enter) != 0 {
+			t.Errorf("the destroyed toplevel entered an output: %v", enter)
+		}
+	})
+	t.Run("remove output", func(t *testing.T) {
+		ws, render := newServer(t)
+		c := connect(t, ws)
+		if _, err := c.Global(wayland.WlOutputInterface); err != nil {
+			t.Fatal(err)
+		}
+		top := mapToplevel(t, c, render)
+		if err := ws.RemoveOutput(ws.Outputs()[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Roundtrip(); err != nil {
+			t.Fatal(err)
+		}
+		if leave := c.Take(top.Id, "leave"); len(leave) != 1 {
+			t.Errorf("expected the surface to leave the removed output, got %v", leave)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package testclient
+
+import (
+	"fmt"
+
+	"nyctal/wayland"
+
+	"golang.org/x/sys/unix"
+)
+
+// GetRegistry creates the registry and waits for the initial globals to be announced
+func (c *Client) GetRegistry() error {
+	c.Registry = c.NewObject(wayland.WlRegistryInterface)
+	if err := c.Request(1, "get_registry", c.Registry); err != nil {
+		return err
+	}
+	return c.Roundtrip()
+}
+
+// Bind binds the first global implementing iface, version 0 binds the version advertised by the compositor
+func (c *Client) Bind(iface string, version uint32) (uint32, error) {
+	for _, global := range c.Globals {
+		if global.Interface != iface {
+			continue
+		}
+		if version == 0 {
+			version = global.Version
+		}
+		id := c.NewObject(iface)
+		if err := c.Request(c.Registry, "bind", global.Name, iface, version, id); err != nil {
+			return 0, err
+		}
+		return id, nil
+	}
+	return 0, fmt.Errorf("no global implements %s", iface)
+}
+
+// Global returns the id of a bound global, binding it the first time it is used
+func (c *Client) Global(iface string) (uint32, error) {
+	if id, ok := c.bound[iface]; ok {
+		return id, nil
+	}
+	id, err := c.Bind(iface, 0)
+	if err != nil {
+		return 0, err
+	}
+	c.bound[iface] = id
+	return id, nil
+}
+
+// Pool is a wl_shm_pool backed by a memfd, its Data can be written to directly
+type Pool struct {
+	c    *Client
+	Id   uint32
+	Data []byte
+}
+
+// CreatePool creates a shm pool of size bytes
+func (c *Client) CreatePool(size int) (*Pool, error) {
+	shm, err := c.Global(wayland.WlShmInterface)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := unix.MemfdCreate("testclient-pool", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("memfd_create: %v", err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Ftruncate(fd, int64(size)); err != nil {
+		return nil, fmt.Errorf("ftruncate: %v", err)
+	}
+	data, err := unix.Mmap(fd, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap: %v", err)
+	}
+	pool := &Pool{c: c, Id: c.NewObject(wayland.WlShmPoolInterface), Data: data}
+	if err := c.Request(shm, "create_pool", pool.Id, Fd(fd), int32(size)); err != nil {
+		unix.Munmap(data)
+		return nil, err
+	}
+	return pool, nil
+}
+
+// CreateBuffer creates a wl_buffer of the pool
+func (p *Pool) CreateBuffer(offset, width, height, stride int32, format wayland.WlShmFormat) (uint32, error) {
+	id := p.c.NewObject(wayland.WlBufferInterface)
+	return id, p.c.Request(p.Id, "create_buffer", id, offset, width, height, stride, format)
+}
+
+// Destroy destroys the pool (buffers created from it remain valid) and unmaps it
+func (p *Pool) Destroy() error {
+	err := p.c.Request(p.Id, "destroy")
+	unix.Munmap(p.Data)
+	p.Data = nil
+	return err
+}
+
+// CreateBuffer creates an argb8888 buffer filled with a single color, in a pool of its own
+func (c *Client) CreateBuffer(width, height int32, argb uint32) (uint32, error) {
+	pool, err := c.CreatePool(int(width * height * 4))
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(pool.Data); i += 4 {
+		pool.Data[i] = byte(argb)
+		pool.Data[i+1] = byte(argb >> 8)
+		pool.Data[i+2] = byte(argb >> 16)
+		pool.Data[i+3] = byte(argb >> 24)
+	}
+	buffer, err := pool.CreateBuffer(0, width, height, width*4, wayland.WlShmFormatArgb8888)
+	if err != nil {
+		return 0, err
+	}
+	return buffer, pool.Destroy()
+}
+
+type Surface struct {
+	c  *Client
+	Id uint32
+}
+
+func (c *Client) CreateSurface() (*Surface, error) {
+	compositor, err := c.Global(wayland.WlCompositorInterface)
+	if err != nil {
+		return nil, err
+	}
+	s := &Surface{c: c, Id: c.NewObject(wayland.WlSurfaceInterface)}
+	return s, c.Request(compositor, "create_surface", s.Id)
+}
+
+func (s *Surface) Attach(buffer uint32, x, y int32) error {
+	return s.c.Request(s.Id, "attach", buffer, x, y)
+}
+
+func (s *Surface) Damage(x, y, width, height int32) error {
+	return s.c.Request(s.Id, "damage", x, y, width, height)
+}
+
+func (s *Surface) Commit() error {
+	return s.c.Request(s.Id, "commit")
+}
+
+// Frame requests a frame callback, returning the wl_callback to wait for done on
+func (s *Surface) Frame() (uint32, error) {
+	callback := s.c.NewObject(wayland.WlCallbackInterface)
+	return callback, s.c.Request(s.Id, "frame", callback)
+}
+
+// XdgSurface is an xdg_surface with its role object (xdg_toplevel or xdg_popup)
+type XdgSurface struct {
+	*Surface
+	XdgId  uint32
+	RoleId uint32
+}
+
+func (c *Client) getXdgSurface() (*XdgSurface, error) {
+	wmBase, err := c.Global(wayland.XdgWmBaseInterface)
+	if err != nil {
+		return nil, err
+	}
+	surface, err := c.CreateSurface()
+	if err != nil {
+		return nil, err
+	}
+	xs := &XdgSurface{Surface: surface, XdgId: c.NewObject(wayland.XdgSurfaceInterface)}
+	return xs, c.Request(wmBase, "get_xdg_surface", xs.XdgId, surface.Id)
+}
+
+// CreateToplevel creates a new surface with the xdg_toplevel role and performs the initial commit
+func (c *Client) CreateToplevel(title string) (*XdgSurface, error) {
+	// toplevels receive input from the client's seat, which toolkits always bind first
+	if _, err := c.Global(wayland.WlSeatInterface); err != nil {
+		return nil, err
+	}
+	xs, err := c.getXdgSurface()
+	if err != nil {
+		return nil, err
+	}
+	xs.RoleId = c.NewObject(wayland.XdgToplevelInterface)
+	if err := c.Request(xs.XdgId, "get_toplevel", xs.RoleId); err != nil {
+		return nil, err
+	}
+	if err := c.Request(xs.RoleId, "set_title", title); err != nil {
+		return nil, err
+	}
+	return xs, xs.Commit()
+}
+
+// CreatePopup creates a new surface with the xdg_popup role, positioned relative to rect of parent,
+// and performs the initial commit
+func (c *Client) CreatePopup(parent *XdgSurface, x, y, width, height int32) (*XdgSurface, error) {
+	wmBase, err := c.Global(wayland.XdgWmBaseInterface)
+	if err != nil {
+		return nil, err
+	}
+	positioner := c.NewObject(wayland.XdgPositionerInterface)
+	if err := c.Request(wmBase, "create_positioner", positioner); err != nil {
+		return nil, err
+	}
+	if err := c.Request(positioner, "set_size", width, height); err != nil {
+		return nil, err
+	}
+	if err := c.Request(positioner, "set_anchor_rect", x, y, 1, 1); err != nil {
+		return nil, err
+	}
+
+	xs, err := c.getXdgSurface()
+	if err != nil {
+		return nil, err
+	}
+	xs.RoleId = c.NewObject(wayland.XdgPopupInterface)
+	if err := c.Request(xs.XdgId, "get_popup", xs.RoleId, parent.XdgId, positioner); err != nil {
+		return nil, err
+	}
+	if err := c.Request(positioner, "destroy"); err != nil {
+		return nil, err
+	}
+	return xs, xs.Commit()
+}
+
+// Configure waits for the next configure sequence and acknowledges it, returning the role's configure
+// event (xdg_toplevel.configure or xdg_popup.configure). Toplevels are configured when the workspace
+// lays out windows, so the compositor has to render a frame (e.g. call Buffer on the workspace) after
+// a Roundtrip for this to return.
+func (xs *XdgSurface) Configure() (Event, error) {
+	configure, err := xs.c.WaitFor(xs.XdgId, "configure")
+	if err != nil {
+		return Event{}, err
+	}
+	var role Event
+	if roleConfigure := xs.c.Take(xs.RoleId, "configure"); len(roleConfigure) > 0 {
+		role = roleConfigure[len(roleConfigure)-1]
+	}
+	return role, xs.c.Request(xs.XdgId, "ack_configure", configure.Args[0])
+}
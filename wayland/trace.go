@@ -0,0 +1,209 @@
+package wayland
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InterfaceSpec describes the messages of a protocol interface, they are generated from the protocol xml
+// by wayland/scanner and allow messages to be decoded without knowing their types ahead of time
+type InterfaceSpec struct {
+	Name     string
+	Version  int
+	Requests []MessageSpec
+	Events   []MessageSpec
+}
+
+type MessageSpec struct {
+	Name string
+	Args []ArgSpec
+}
+
+type ArgSpec struct {
+	Name      string
+	Type      string
+	Interface string
+	AllowNull bool
+}
+
+var interfaceSpecs = make(map[string]*InterfaceSpec)
+
+func registerInterfaces(specs ...*InterfaceSpec) {
+	for _, spec := range specs {
+		interfaceSpecs[spec.Name] = spec
+	}
+}
+
+// LookupInterface returns the spec of a generated interface
+func LookupInterface(name string) (*InterfaceSpec, bool) {
+	spec, ok := interfaceSpecs[name]
+	return spec, ok
+}
+
+// traceAll and traceClients are set from WAYLAND_DEBUG, which is either 1 (or server) to trace
+// every client or a comma separated list of client numbers e.g. WAYLAND_DEBUG=2,5
+var traceAll, traceClients = parseTraceEnv(os.Getenv("WAYLAND_DEBUG"))
+
+func parseTraceEnv(env string) (bool, map[int]bool) {
+	if env == "1" || env == "server" || env == "all" {
+		return true, nil
+	}
+	clients := make(map[int]bool)
+	for _, part := range strings.Split(env, ",") {
+		if client, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			clients[client] = true
+		}
+	}
+	return false, clients
+}
+
+func traceEnabled(client int) bool {
+	return traceAll || traceClients[client]
+}
+
+// SetTrace turns protocol tracing on or off for the connection. Objects created while tracing
+// was off are printed as unknown#id as their interface was not recorded.
+func (c *WaylandServerConn) SetTrace(enabled bool) {
+	c.traceLock.Lock()
+	defer c.traceLock.Unlock()
+	if enabled && c.traceIfaces == nil {
+		c.traceIfaces = interfaceMap{1: WlDisplayInterface}
+	}
+	c.trace.Store(enabled)
+}
+
+// traceRequest prints a request received from the client
+func (c *WaylandServerConn) traceRequest(packet *WaylandMessage) {
+	c.traceMessage("->", packet.Address, packet.Opcode, packet.Data, true)
+}
+
+// traceEvent prints an event (including its header) sent to the client
+func (c *WaylandServerConn) traceEvent(data []byte) {
+	if len(data) < 8 {
+		return
+	}
+	c.traceMessage("<-", binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint16(data[4:6]), data[8:], false)
+}
+
+// traceMessage prints a message in the style of libwayland's WAYLAND_DEBUG e.g.
+// [15:04:05.000000] -> wl_surface#12.attach(wl_buffer#30, 0, 0)
+func (c *WaylandServerConn) traceMessage(arrow string, id uint32, opcode uint16, body []byte, request bool) {
+	c.traceLock.Lock()
+	defer c.traceLock.Unlock()
+
+	iface, msg, args := c.traceIfaces.describe(id, opcode, body, request)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] [client#%d] %s %s#%d.", time.Now().Format("15:04:05.000000"), c.id, arrow, iface, id)
+	if msg == nil {
+		fmt.Fprintf(&sb, "[opcode %d](%x)", opcode, body)
+	} else {
+		fmt.Fprintf(&sb, "%s(%s)", msg.Name, strings.Join(args, ", "))
+	}
+	fmt.Fprintln(os.Stderr, sb.String())
+}
+
+// interfaceMap tracks the interface of every object of a connection by following the messages
+// that create and delete them
+type interfaceMap map[uint32]string
+
+// describe decodes a message, returning the interface of the object it was sent to, its spec (nil if
+// unknown) and its formatted arguments
+func (m interfaceMap) describe(id uint32, opcode uint16, body []byte, request bool) (string, *MessageSpec, []string) {
+	iface := m[id]
+	spec, ok := interfaceSpecs[iface]
+	var messages []MessageSpec
+	if ok && request {
+		messages = spec.Requests
+	} else if ok {
+		messages = spec.Events
+	}
+	if iface == "" {
+		iface = "unknown"
+	}
+	if int(opcode) >= len(messages) {
+		return iface, nil, nil
+	}
+	msg := &messages[opcode]
+	args := m.formatArgs(*msg, body)
+	// once an id has been released the client may reuse it for a different interface
+	if iface == WlDisplayInterface && msg.Name == "delete_id" && len(body) >= 4 {
+		delete(m, binary.LittleEndian.Uint32(body))
+	}
+	return iface, msg, args
+}
+
+// formatArgs formats the arguments of a message, recording the interfaces of any new objects
+func (m interfaceMap) formatArgs(msg MessageSpec, body []byte) []string {
+	var args []string
+	buf := body
+	object := func(iface string, id uint32) string {
+		if id == 0 {
+			return "nil"
+		}
+		if iface == "" {
+			iface = m[id]
+		}
+		if iface == "" {
+			iface = "unknown"
+		}
+		return fmt.Sprintf("%s#%d", iface, id)
+	}
+	for _, arg := range msg.Args {
+		var err error
+		var v uint32
+		switch arg.Type {
+		case "int":
+			v, buf, err = readUint32(buf)
+			args = append(args, strconv.Itoa(int(int32(v))))
+		case "uint":
+			v, buf, err = readUint32(buf)
+			args = append(args, strconv.FormatUint(uint64(v), 10))
+		case "fixed":
+			v, buf, err = readUint32(buf)
+			args = append(args, strconv.FormatFloat(float64(int32(v))/256.0, 'f', -1, 64))
+		case "object":
+			v, buf, err = readUint32(buf)
+			args = append(args, object(arg.Interface, v))
+		case "new_id":
+			iface := arg.Interface
+			if iface == "" {
+				var valid bool
+				iface, valid, buf, err = readString(buf)
+				if err == nil && valid {
+					_, buf, err = readUint32(buf)
+				}
+			}
+			if err == nil {
+				v, buf, err = readUint32(buf)
+				m[v] = iface
+				args = append(args, "new id "+object(iface, v))
+			}
+		case "string":
+			var str string
+			var valid bool
+			str, valid, buf, err = readString(buf)
+			if err == nil && valid {
+				args = append(args, strconv.Quote(str))
+			} else if err == nil {
+				args = append(args, "nil")
+			}
+		case "array":
+			var arr []byte
+			arr, buf, err = readBlob(buf)
+			if err == nil {
+				args = append(args, fmt.Sprintf("array[%d]", len(arr)))
+			}
+		case "fd":
+			args = append(args, "fd")
+		}
+		if err != nil {
+			args = append(args, fmt.Sprintf("<malformed %s: %v>", arg.Name, err))
+			break
+		}
+	}
+	return args
+}
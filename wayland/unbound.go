@@ -3,74 +3,61 @@ package wayland
 import (
 	"fmt"
 
-	"nyctal/model"
 	"nyctal/utils"
 )
 
+// UnboundObject is a wl_registry, it binds the globals advertised by the server (see GlobalTable)
 type UnboundObject struct {
-	BaseObject
 	server *WaylandServer
+	wsc    *WaylandServerConn
+	id     uint32
+}
+
+func (u *UnboundObject) Destroy() {
+	u.server.globals.RemoveRegistry(u)
 }
 
 func (u *UnboundObject) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
-
-		inter := NewUintField()
-		iname := NewStringField()
-		version := NewUintField()
-		newId := NewUintField()
+	case WlRegistryRequestBind:
 
-		if err := ParsePacketStructure(packet.Data, inter, iname, version, newId); err != nil {
+		req, err := ParseWlRegistryBindRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		new_id := uint32(*newId)
-		switch *iname {
-		case "wl_compositor":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_compositor#%d", new_id))
-			wsc.registry.New(new_id, &Compositor{})
-		case "wl_subcompositor":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_subcompositor#%d", new_id))
-			wsc.registry.New(new_id, &SubCompositor{})
-		case "wl_shm":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_shm#%d", new_id))
-			// Send Format Message...
-			wsc.SendMessage(NewPacketBuilder(new_id, 0x00).WithUint(uint32(model.FormatARGB)).Build())
-			wsc.SendMessage(NewPacketBuilder(new_id, 0x00).WithUint(uint32(model.FormatXRGB)).Build())
-			wsc.registry.New(new_id, &SHM{id: new_id})
-		case "xdg_wm_base":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("xdg_wm_base#%d", new_id))
-			wmbase := &XDG_Base{server: u.server, wsc: wsc, id: new_id}
-			wsc.pingtarget = wmbase
-			wsc.registry.New(new_id, wmbase)
-		case "wl_seat":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_seat#%d", new_id))
-			if seat := wsc.registry.FindSeat(); seat != nil {
-				seat.id = new_id
-				wsc.registry.New(new_id, seat)
-			} else {
-				wsc.registry.New(new_id, NewSeat(wsc, new_id))
+		global, ok := u.server.globals.Get(u, req.Name)
+		if !ok {
+			return NewProtocolError(packet.Address, WlDisplayErrorInvalidObject, "invalid global %s (%d)", req.Interface, req.Name)
+		}
+		if global.Interface != req.Interface {
+			return NewProtocolError(packet.Address, WlDisplayErrorInvalidObject, "invalid interface for global %d: have %s, wanted %s", req.Name, global.Interface, req.Interface)
+		}
+		if req.Version == 0 || req.Version > global.Version {
+			return NewProtocolError(packet.Address, WlDisplayErrorInvalidObject, "invalid version for global %s (%d): have %d, wanted %d", req.Interface, req.Name, global.Version, req.Version)
+		}
+
+		utils.Debug(int(wsc.id), "bind", fmt.Sprintf("%s#%d v%d", req.Interface, req.Id, req.Version))
+		if global.removed {
+			// the client has not yet seen the global_remove, give it an object that ignores requests
+			if err := wsc.registry.New(req.Id, &InertObject{}, req.Version); err != nil {
+				return err
 			}
-		case "wl_output":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_output#%d", new_id))
-			wsc.registry.New(new_id, NewOutput(new_id, wsc))
-		case "wl_data_device_manager":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wl_data_device_manager#%d", new_id))
-			wsc.registry.New(new_id, &DataDeviceManager{id: new_id})
-		case "wp_viewporter":
-			utils.Debug(int(wsc.id), "bind", fmt.Sprintf("wp_viewporter#%d", new_id))
-			wsc.registry.New(new_id, &WPViewporter{id: new_id})
-		// case "zwp_linux_dmabuf_v1":
-		// 	utils.Debug("bind", fmt.Sprintf("zwp_linux_dmabuf_v1#%d", new_id))
-		// 	wsc.registry.New(new_id, NewLinuxDMABuf(u.server))
-		default:
-			return fmt.Errorf("failed to bind: [%s]", *iname)
+			return nil
 		}
-		return nil
+		return global.Bind(u.server, wsc, req.Id, req.Version)
 	default:
-		return fmt.Errorf("unknown opcode called on unbound object: %v", packet.Opcode)
+		return UnknownOpcode(packet, WlRegistryInterface)
 	}
 
 }
+
+// InertObject is bound in place of globals that have been removed, all requests are ignored
+type InertObject struct {
+	BaseObject
+}
+
+func (io *InertObject) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
+	return nil
+}
@@ -1,14 +1,21 @@
 package wayland
 
+//go:generate go run ./scanner -o protocol_wayland.go ../specs/wayland.xml
+//go:generate go run ./scanner -o protocol_xdg_shell.go ../specs/xdg-shell.xml
+
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"nyctal/model"
 	"nyctal/utils"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
@@ -20,9 +27,32 @@ type WaylandServer struct {
 	l         net.Listener
 	socket    string
 	globalIdx atomic.Uint32
+	clientIdx atomic.Int32
+	serials   Serials
 	workspace model.Workspace
+	globals   *GlobalTable
+	limits    Limits
+	// display and lockFd are set when the socket was created by NewNamedServer or NewAutoServer
+	display string
+	lockFd  int
+
+	closeOnce sync.Once
+	closeErr  error
+	closing   atomic.Bool
+	// conns are all connected clients, handlers counts the goroutines started by Serve
+	connsLock sync.Mutex
+	conns     map[*WaylandServerConn]bool
+	handlers  sync.WaitGroup
+	// loop is set if clients are dispatched by an EventLoop rather than Serve
+	loop atomic.Pointer[EventLoop]
+	// outputs are the displays the workspace is shown on, by global name
+	outputsLock sync.Mutex
+	outputs     map[uint32]*outputHead
 }
 
+// ErrServerClosed is returned by Serve once Shutdown or Close has been called
+var ErrServerClosed = errors.New("wayland: server closed")
+
 func NewServer(display_socket string, workspace model.Workspace) (*WaylandServer, error) {
 	l, err := net.Listen("unix", display_socket)
 	if err != nil {
@@ -31,37 +61,205 @@ func NewServer(display_socket string, workspace model.Workspace) (*WaylandServer
 	ws := &WaylandServer{socket: display_socket,
 		l:         l,
 		workspace: workspace,
+		globals:   NewGlobalTable(),
+		limits:    DefaultLimits(),
+		lockFd:    -1,
+		conns:     make(map[*WaylandServerConn]bool),
+		outputs:   make(map[uint32]*outputHead),
 	}
+	ws.registerDefaultGlobals()
 
 	return ws, nil
 }
 
+// Listen accepts clients until the server is closed, see Serve
 func (ws *WaylandServer) Listen() {
-	clientId := 0
+	if err := ws.Serve(context.Background()); err != nil && !errors.Is(err, ErrServerClosed) {
+		utils.Error(0, "wayland-server", err.Error())
+	}
+}
+
+// Serve accepts clients and handles each of them on their own goroutine until ctx is cancelled
+// or the server is closed, see EventLoop for an alternative that dispatches every client (and
+// rendering) from a single goroutine. Returning does not disconnect clients, use Shutdown for that.
+func (ws *WaylandServer) Serve(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	var backoff time.Duration
 	for {
 		fd, err := ws.l.Accept()
-		clientId += 1
 		if err != nil {
-			println("accept error", err.Error())
-			return
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if ws.closing.Load() || errors.Is(err, net.ErrClosed) {
+				return ErrServerClosed
+			}
+			// e.g. out of fds, wait for some clients to go away
+			if errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE) || errors.Is(err, syscall.ECONNABORTED) {
+				backoff = min(max(backoff*2, 5*time.Millisecond), time.Second)
+				utils.Debug(0, "wayland-server", fmt.Sprintf("accept error: %v, retrying in %v", err, backoff))
+				time.Sleep(backoff)
+				continue
+			}
+			return fmt.Errorf("accept: %w", err)
 		}
+		backoff = 0
+
 		connFd, err := getConnFd(fd.(*net.UnixConn))
 		if err != nil {
-			return
+			utils.Debug(0, "wayland-server", fmt.Sprintf("could not get client fd: %v", err))
+			fd.Close()
+			continue
 		}
+		// reads time out so that unresponsive clients can be pinged (see handle)
+		syscall.SetsockoptTimeval(connFd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &syscall.Timeval{Sec: 2})
 
-		wsc := &WaylandServerConn{
-			socket:   fd,
-			index:    &ws.globalIdx,
-			connFd:   connFd,
-			id:       model.GlobalIdx(clientId),
-			fds:      utils.NewQueue[int](),
-			registry: NewRegistry(),
+		ws.serveConn(ws.newConn(fd, connFd))
+	}
+}
+
+// serveConn handles a client on its own goroutine, which Shutdown waits for
+func (ws *WaylandServer) serveConn(wsc *WaylandServerConn) {
+	ws.handlers.Add(1)
+	go func() {
+		defer ws.handlers.Done()
+		ws.handle(wsc)
+	}()
+}
+
+// Shutdown stops accepting clients, removes the socket and disconnects every client (sending
+// any events already queued for them). It waits for the client goroutines started by Serve
+// (or for EventLoop.Run) to finish, or until ctx is done.
+func (ws *WaylandServer) Shutdown(ctx context.Context) error {
+	if el := ws.loop.Load(); el != nil {
+		// the loop owns its clients, so it is asked to disconnect them itself
+		el.shutdown.Store(true)
+		err := ws.Close()
+		el.wake()
+		select {
+		case <-el.done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		go ws.handle(wsc)
+	}
+
+	ws.closing.Store(true)
+	err := ws.Close()
+
+	ws.connsLock.Lock()
+	for wsc := range ws.conns {
+		wsc.Flush()
+		wsc.disconnect(ErrServerClosed)
+	}
+	ws.connsLock.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		ws.handlers.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newConn sets up the state for a newly connected client, socket may be nil if the
+// connection is only known by its fd
+func (ws *WaylandServer) newConn(socket net.Conn, connFd int) *WaylandServerConn {
+	clientId := int(ws.clientIdx.Add(1))
+	wsc := &WaylandServerConn{
+		socket:  socket,
+		index:   &ws.globalIdx,
+		serials: &ws.serials,
+		connFd:  connFd,
+		id:      model.GlobalIdx(clientId),
+		fds:     utils.NewQueue[int](),
+		in:      utils.NewRingBuffer(inBufferSize),
+		oob:     make([]byte, unix.CmsgSpace(maxFdsPerMsg*4)),
+		limits:  ws.limits,
+	}
+	wsc.registry = NewRegistry(func(id uint32) { SendWlDisplayDeleteId(wsc, 1, id) })
+	if creds, err := unix.GetsockoptUcred(connFd, unix.SOL_SOCKET, unix.SO_PEERCRED); err == nil {
+		wsc.creds = creds
+	}
+	ws.connsLock.Lock()
+	ws.conns[wsc] = true
+	ws.connsLock.Unlock()
+	wsc.SetTrace(traceEnabled(clientId))
+	if captureDir != "" {
+		wsc.captureToDir(captureDir)
+	}
+	wsc.registry.New(1, &Display{lastSync: 5, server: ws}, 1)
+	if wsc.creds != nil {
+		utils.Debug(int(wsc.id), "ws", fmt.Sprintf("new client#%d pid=%d uid=%d gid=%d", wsc.id, wsc.creds.Pid, wsc.creds.Uid, wsc.creds.Gid))
+	} else {
+		utils.Debug(int(wsc.id), "ws", fmt.Sprintf("new client#%d (unknown credentials)", wsc.id))
+	}
+	return wsc
+}
+
+// removeConn destroys all of the state associated with a client and closes its connection
+func (ws *WaylandServer) removeConn(wsc *WaylandServerConn) {
+	utils.Debug(int(wsc.id), "wayland-server", fmt.Sprintf("client#%d removed", wsc.id))
+	ws.connsLock.Lock()
+	delete(ws.conns, wsc)
+	ws.connsLock.Unlock()
+	ws.workspace.RemoveAllWithParent(wsc.id)
+	wsc.registry.Close()
+	wsc.SetCapture(nil)
+
+	wsc.disconnect(fmt.Errorf("connection terminated"))
+	if wsc.socket != nil {
+		wsc.socket.Close()
+	} else {
+		unix.Close(wsc.connFd)
+	}
+	for !wsc.fds.Empty() {
+		fd, _ := wsc.fds.Pop()
+		unix.Close(fd)
 	}
 }
 
+// dispatch handles a single request from a client. If the client has to be disconnected the error
+// is returned, after it has been sent to the client.
+func (ws *WaylandServer) dispatch(wsc *WaylandServerConn, packet *WaylandMessage) error {
+	if wsc.trace.Load() {
+		wsc.traceRequest(packet)
+	}
+	if wsc.capturing() {
+		wsc.captureMessage(CaptureRequest, packet)
+	}
+
+	obj, err := wsc.registry.Get(uint32(packet.Address))
+	if err != nil {
+		err = NewProtocolError(1, WlDisplayErrorInvalidObject, "invalid object %d", packet.Address)
+	} else {
+		err = obj.HandleMessage(wsc, packet)
+	}
+	if err == nil {
+		err = wsc.checkLimits()
+	}
+	if err != nil {
+		utils.Debug(int(wsc.id), "client", err.Error())
+		wsc.PostError(err)
+		return err
+	}
+	return nil
+}
+
 func (ws *WaylandServer) handle(wsc *WaylandServerConn) {
 
 	// Ok..we need to do this because its the only way of
@@ -72,25 +270,13 @@ func (ws *WaylandServer) handle(wsc *WaylandServerConn) {
 	// (see below...)
 	debug.SetPanicOnFault(true)
 
-	wsc.registry.New(0, &NullObject{})
-	wsc.registry.New(1, &Display{lastSync: 5, server: ws})
-	utils.Debug(int(wsc.id), "ws", fmt.Sprintf("new client#%d", wsc.id))
-
 	defer func() {
 
 		if r := recover(); r != nil {
 			debug.PrintStack()
 			utils.Debug(int(wsc.id), "wayland-server", fmt.Sprintf("recovered panic in client thread %v", r))
 		}
-		utils.Debug(int(wsc.id), "wayland-server", fmt.Sprintf("client#%d removed", wsc.id))
-		ws.workspace.RemoveAllWithParent(wsc.id)
-		wsc.registry.Close()
-
-		wsc.socket.Close()
-		for !wsc.fds.Empty() {
-			fd, _ := wsc.fds.Pop()
-			unix.Close(fd)
-		}
+		ws.removeConn(wsc)
 
 	}()
 
@@ -99,9 +285,14 @@ func (ws *WaylandServer) handle(wsc *WaylandServerConn) {
 		packet, err := wsc.ReadPacket()
 		if err != nil {
 			utils.Debug(int(wsc.id), "wayland-server", err.Error())
+			if _, ok := err.(*ProtocolError); ok {
+				wsc.PostError(err)
+				break
+			}
 			if strings.Contains(err.Error(), "resource temporarily unavailable") && wsc.errors < 10 {
 				if wsc.pingtarget != nil {
 					wsc.pingtarget.Ping()
+					wsc.Flush()
 					wsc.errors += 1
 					continue
 				}
@@ -109,25 +300,26 @@ func (ws *WaylandServer) handle(wsc *WaylandServerConn) {
 			if strings.Contains(err.Error(), "interrupted system call") && wsc.errors < 10 {
 				if wsc.pingtarget != nil {
 					wsc.pingtarget.Ping()
+					wsc.Flush()
 					wsc.errors += 1
 					continue
 				}
 			}
 			break
-		} else {
-			utils.Debug(int(wsc.id), "wayland-message", fmt.Sprintf("%d %v", wsc.id, packet))
 		}
 
 		// sucessfully received a message, so reset error tracking...
 		wsc.errors = 0
-		if obj, err := wsc.registry.Get(uint32(packet.Address)); err == nil {
-			if err := obj.HandleMessage(wsc, packet); err != nil {
+		if err := ws.dispatch(wsc, packet); err != nil {
+			break
+		}
+
+		// flush once the batch of requests the client sent has been dispatched
+		if !wsc.hasBufferedMessage() {
+			if err := wsc.Flush(); err != nil {
 				utils.Debug(int(wsc.id), "client", err.Error())
 				break
 			}
-		} else {
-			utils.Debug(int(wsc.id), "client", err.Error())
-			break
 		}
 
 	}
@@ -135,6 +327,19 @@ func (ws *WaylandServer) handle(wsc *WaylandServerConn) {
 
 }
 
+// getRawFd returns the fd of a socket owned by the go runtime
+func getRawFd(conn syscall.Conn) (fd int, err error) {
+	var rawConn syscall.RawConn
+	rawConn, err = conn.SyscallConn()
+	if rawConn == nil || err != nil {
+		return
+	}
+	err = rawConn.Control(func(rawFd uintptr) {
+		fd = int(rawFd)
+	})
+	return
+}
+
 func getConnFd(conn syscall.Conn) (connFd int, err error) {
 	var rawConn syscall.RawConn
 	rawConn, err = conn.SyscallConn()
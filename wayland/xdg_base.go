@@ -15,53 +15,59 @@ type XDG_Base struct {
 }
 
 func (u *XDG_Base) Ping() {
-	u.wsc.SendMessage(NewPacketBuilder(u.id, 0x00).WithUint(uint32(time.Now().UnixMilli())).Build())
+	SendXdgWmBasePing(u.wsc, u.id, uint32(time.Now().UnixMilli()))
 }
 
 func (u *XDG_Base) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case XdgWmBaseRequestDestroy:
 		wsc.registry.Destroy(u.id)
 		return nil
-	case 1:
+	case XdgWmBaseRequestCreatePositioner:
 		//create positioner
-		new_id := NewUintField()
-		if err := ParsePacketStructure(packet.Data, new_id); err != nil {
+		req, err := ParseXdgWmBaseCreatePositionerRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		utils.Debug(int(wsc.id), "xdg_wm_base", fmt.Sprintf("create_positioner %d", req.Id))
+		xdg_positioner := &XDG_Positioner{id: req.Id}
+		if err := wsc.registry.New(req.Id, xdg_positioner, wsc.registry.Version(packet.Address)); err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), "xdg_wm_base", fmt.Sprintf("create_positioner %d", *new_id))
-		xdg_positioner := &XDG_Positioner{id: uint32(*new_id)}
-		wsc.registry.New(uint32(*new_id), xdg_positioner)
 		return nil
-	case 2:
-		new_id := NewUintField()
-		surface_id := NewUintField()
-		if err := ParsePacketStructure(packet.Data, new_id, surface_id); err != nil {
+	case XdgWmBaseRequestGetXdgSurface:
+		req, err := ParseXdgWmBaseGetXdgSurfaceRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
 		// received create pool message...
-		utils.Debug(int(wsc.id), "xdg_wm_base", fmt.Sprintf("get_xdg_surface %d %d", new_id, surface_id))
+		utils.Debug(int(wsc.id), "xdg_wm_base", fmt.Sprintf("get_xdg_surface %d %d", req.Id, req.Surface))
 
-		if surface, err := wsc.registry.Get(uint32(*surface_id)); err == nil {
+		if surface, err := wsc.registry.Get(req.Surface); err == nil {
 			if surfaceObj, ok := surface.(*Surface); ok {
-				xdgsurface := &XDG_Surface{server: u.server, surface: surfaceObj, id: uint32(*new_id)}
-				wsc.registry.New(uint32(*new_id), xdgsurface)
+				if surfaceObj.role != "" && surfaceObj.role != XdgToplevelInterface && surfaceObj.role != XdgPopupInterface {
+					return NewProtocolError(u.id, XdgWmBaseErrorRole, "wl_surface#%d already has the %s role", req.Surface, surfaceObj.role)
+				}
+				xdgsurface := &XDG_Surface{server: u.server, surface: surfaceObj, id: req.Id}
+				if err := wsc.registry.New(req.Id, xdgsurface, wsc.registry.Version(packet.Address)); err != nil {
+					return err
+				}
 			} else {
-				return fmt.Errorf("object is not a surface")
+				return InvalidObject(packet, req.Surface, WlSurfaceInterface)
 			}
 		} else {
-			return err
+			return InvalidObject(packet, req.Surface, WlSurfaceInterface)
 		}
 
 		return nil
-	case 3:
+	case XdgWmBaseRequestPong:
 		// this is a pong...
 		return nil
 
 	default:
-		return fmt.Errorf("unknown opcode called on xdg: %v", packet.Opcode)
+		return UnknownOpcode(packet, XdgWmBaseInterface)
 	}
 
 }
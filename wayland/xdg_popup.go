@@ -19,12 +19,11 @@ type XDGPopup struct {
 
 func (xp *XDGPopup) Configure(wsc *WaylandServerConn) {
 
-	wsc.SendMessage(NewPacketBuilder(xp.id, 0x00).
-		WithUint(uint32(xp.positioner.anchorRect.Min.X)).
-		WithUint(uint32(xp.positioner.anchorRect.Min.Y)).
-		WithUint(uint32(xp.positioner.size.Dx())).
-		WithUint(uint32(xp.positioner.size.Dy())).
-		Build())
+	SendXdgPopupConfigure(wsc, xp.id,
+		int32(xp.positioner.anchorRect.Min.X),
+		int32(xp.positioner.anchorRect.Min.Y),
+		int32(xp.positioner.size.Dx()),
+		int32(xp.positioner.size.Dy()))
 	xp.configured = true
 	utils.Debug(int(wsc.id), fmt.Sprintf("xdg_popup#%d", xp.id), "configure")
 
@@ -36,7 +35,7 @@ func (xp *XDGPopup) Configure(wsc *WaylandServerConn) {
 func (u *XDGPopup) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case XdgPopupRequestDestroy:
 		// destroy
 		wsc.registry.Destroy(u.id)
 		window := u.server.workspace.GetTopLevel(u.parent.uniq)
@@ -44,26 +43,33 @@ func (u *XDGPopup) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage)
 			wayland.PopPopup()
 			u.parent.popup = nil // prevent new surface intersections TODO: improve this interface
 		}
+		u.surface.surface.unmap(wsc)
 		return nil
-	case 1:
-		x := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x); err != nil {
+	case XdgPopupRequestGrab:
+		req, err := ParseXdgPopupGrabRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_popup#%d", u.id), fmt.Sprintf("grab seat#%d", uint32(*x)))
-		gseat := uint32(*x)
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_popup#%d", u.id), fmt.Sprintf("grab seat#%d", req.Seat))
+		gseat := req.Seat
 		if seat := wsc.registry.FindSeat(); seat != nil && seat.id == gseat {
+			// grabs must be in response to a press, otherwise the popup is dismissed straight away
+			if !wsc.validInputSerial(req.Serial) {
+				utils.Debug(int(wsc.id), fmt.Sprintf("xdg_popup#%d", u.id), fmt.Sprintf("grab refused, invalid serial %d", req.Serial))
+				SendXdgPopupPopupDone(wsc, u.id)
+				return nil
+			}
 			seat.Grab(u.surface)
 			return nil
 		} else {
-			return fmt.Errorf("grab_seat: could not find seat %v", seat)
+			return InvalidObject(packet, gseat, WlSeatInterface)
 		}
-	case 2:
+	case XdgPopupRequestReposition:
 		// reposition
 		u.parent.Configure(wsc)
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on xdg_popup: %v", packet.Opcode)
+		return UnknownOpcode(packet, XdgPopupInterface)
 	}
 
 }
@@ -12,21 +12,21 @@ type XDG_Positioner struct {
 	id                   uint32
 	size                 image.Rectangle
 	anchorRect           image.Rectangle
-	anchor               uint32
-	gravity              uint32
-	constraintAdjustment uint32
+	anchor               XdgPositionerAnchor
+	gravity              XdgPositionerGravity
+	constraintAdjustment XdgPositionerConstraintAdjustment
 	offset               image.Point
 	reactive             bool
 }
 
 func (u *XDG_Positioner) CalculateAnchorPoint() image.Point {
-	if u.anchor == 5 { // top left
+	if u.anchor == XdgPositionerAnchorTopLeft {
 		return u.offset.Add(image.Pt(u.anchorRect.Min.X, u.anchorRect.Min.Y))
-	} else if u.anchor == 6 { // bottom left
+	} else if u.anchor == XdgPositionerAnchorBottomLeft {
 		return u.offset.Add(image.Pt(u.anchorRect.Min.X, u.anchorRect.Max.Y))
-	} else if u.anchor == 7 { // top right
+	} else if u.anchor == XdgPositionerAnchorTopRight {
 		return u.offset.Add(image.Pt(u.anchorRect.Max.X, u.anchorRect.Min.Y))
-	} else if u.anchor == 8 { // bottom rights
+	} else if u.anchor == XdgPositionerAnchorBottomRight {
 		return u.offset.Add(image.Pt(u.anchorRect.Max.X, u.anchorRect.Max.Y))
 	} else {
 		return u.offset.Add(image.Pt(u.anchorRect.Min.X+u.anchorRect.Dx()/2, u.anchorRect.Min.Y+u.anchorRect.Dy()/2))
@@ -36,75 +36,70 @@ func (u *XDG_Positioner) CalculateAnchorPoint() image.Point {
 func (u *XDG_Positioner) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case XdgPositionerRequestDestroy:
 		// destroy
 		wsc.registry.Destroy(u.id)
 		return nil
-	case 1:
-		w := NewUintField()
-		h := NewUintField()
-		if err := ParsePacketStructure(packet.Data, w, h); err != nil {
+	case XdgPositionerRequestSetSize:
+		req, err := ParseXdgPositionerSetSizeRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_size %d %d", uint32(*w), uint32(*h)))
-		u.size = image.Rect(0, 0, int(*w), int(*h))
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_size %d %d", req.Width, req.Height))
+		u.size = image.Rect(0, 0, int(req.Width), int(req.Height))
 		return nil
-	case 2:
-		x := NewIntField()
-		y := NewIntField()
-		w := NewIntField()
-		h := NewIntField()
-		if err := ParsePacketStructure(packet.Data, x, y, w, h); err != nil {
+	case XdgPositionerRequestSetAnchorRect:
+		req, err := ParseXdgPositionerSetAnchorRectRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_anchor_rect %d %d %d %d", int32(*x), int32(*y), int32(*w), int32(*h)))
-		u.anchorRect = image.Rect(int(*x), int(*y), int(*x)+int(*w), int(*y)+int(*h))
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_anchor_rect %d %d %d %d", req.X, req.Y, req.Width, req.Height))
+		u.anchorRect = image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height))
 		return nil
-	case 3:
-		x := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x); err != nil {
+	case XdgPositionerRequestSetAnchor:
+		req, err := ParseXdgPositionerSetAnchorRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_anchor %d", uint32(*x)))
-		u.anchor = uint32(*x)
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_anchor %d", req.Anchor))
+		u.anchor = req.Anchor
 		return nil
-	case 4:
-		x := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x); err != nil {
+	case XdgPositionerRequestSetGravity:
+		req, err := ParseXdgPositionerSetGravityRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_gravity %d", uint32(*x)))
-		u.gravity = uint32(*x)
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_gravity %d", req.Gravity))
+		u.gravity = req.Gravity
 		return nil
-	case 5:
-		x := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x); err != nil {
+	case XdgPositionerRequestSetConstraintAdjustment:
+		req, err := ParseXdgPositionerSetConstraintAdjustmentRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_constraint_adjustment %d", uint32(*x)))
-		u.constraintAdjustment = uint32(*x)
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("set_constraint_adjustment %d", req.ConstraintAdjustment))
+		u.constraintAdjustment = req.ConstraintAdjustment
 		return nil
-	case 6:
-		x := NewIntField()
-		y := NewIntField()
-		if err := ParsePacketStructure(packet.Data, x, y); err != nil {
+	case XdgPositionerRequestSetOffset:
+		req, err := ParseXdgPositionerSetOffsetRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("offset %d %d", int32(*x), int32(*y)))
-		u.offset = image.Pt(int(*x), int(*y))
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), fmt.Sprintf("offset %d %d", req.X, req.Y))
+		u.offset = image.Pt(int(req.X), int(req.Y))
 		return nil
-	case 7:
+	case XdgPositionerRequestSetReactive:
 		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_positioner#%d", u.id), "set_reactive")
 		u.reactive = true
 		return nil
-	case 8:
+	case XdgPositionerRequestSetParentSize:
 
 		return nil
-	case 9:
+	case XdgPositionerRequestSetParentConfigure:
 
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on xdg_positioner: %v", packet.Opcode)
+		return UnknownOpcode(packet, XdgPositionerInterface)
 	}
 
 }
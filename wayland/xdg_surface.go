@@ -42,11 +42,12 @@ func (xp *XDG_Surface) Intersects(pointer image.Point) bool {
 	if xp.surface == nil {
 		return false // un undefed surface cannot be intersected
 	}
+	inputRegion := xp.surface.Snapshot().inputRegion
 	if xp.positioner == nil {
-		if xp.surface.commitedInputRegion == nil {
+		if inputRegion == nil {
 			return true
 		} else {
-			inArea, inRegion := xp.surface.commitedInputRegion.Intersects(pointer)
+			inArea, inRegion := inputRegion.Intersects(pointer)
 			if inArea && inRegion {
 				return true
 			} else if !inArea {
@@ -63,10 +64,10 @@ func (xp *XDG_Surface) Intersects(pointer image.Point) bool {
 	if pointer.In(bounds) {
 		// we are inside this popup
 		surfaceLocal := pointer.Add(tl)
-		if xp.surface.commitedInputRegion == nil {
+		if inputRegion == nil {
 			return true
 		} else {
-			inArea, inRegion := xp.surface.commitedInputRegion.Intersects(surfaceLocal)
+			inArea, inRegion := inputRegion.Intersects(surfaceLocal)
 			if inArea && inRegion {
 				return true
 			} else if !inArea {
@@ -82,18 +83,12 @@ func (u *XDG_Surface) Configure(wsc *WaylandServerConn) {
 	if !u.configuring {
 
 		if u.topLevel != nil {
-			wsc.SendMessage(
-				NewPacketBuilder(u.topLevel.id, 0x03).
-					WithUint(0).
-					Build())
+			SendXdgToplevelWmCapabilities(wsc, u.topLevel.id, nil)
 
 		}
 
-		u.serial += 1
-		wsc.SendMessage(
-			NewPacketBuilder(u.id, 0x00).
-				WithUint(u.serial).
-				Build())
+		u.serial = wsc.nextSerial(SerialConfigure, false)
+		SendXdgSurfaceConfigure(wsc, u.id, u.serial)
 
 		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), "configure")
 		u.configuring = true
@@ -112,18 +107,24 @@ func (u *XDG_Surface) Resize(wsc *WaylandServerConn, width int, height int) {
 func (u *XDG_Surface) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case XdgSurfaceRequestDestroy:
 		wsc.registry.Destroy(u.id)
 		u.server.workspace.RemoveTopLevel(u.uniq)
+		u.surface.unmap(wsc)
 		return nil
-	case 1:
-		new_id := NewUintField()
-		if err := ParsePacketStructure(packet.Data, new_id); err != nil {
+	case XdgSurfaceRequestGetToplevel:
+		req, err := ParseXdgSurfaceGetToplevelRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("get_xdg_toplevel#%d", req.Id))
+		if !u.surface.SetRole(XdgToplevelInterface) {
+			return NewProtocolError(u.id, XdgSurfaceErrorAlreadyConstructed, "wl_surface#%d already has the %s role", u.surface.id, u.surface.role)
+		}
+		topLevel := &XDG_Toplevel{server: u.server, id: req.Id}
+		if err := wsc.registry.New(req.Id, topLevel, wsc.registry.Version(packet.Address)); err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("get_xdg_toplevel#%d", uint32(*new_id)))
-		topLevel := &XDG_Toplevel{server: u.server, id: uint32(*new_id)}
-		wsc.registry.New(uint32(*new_id), topLevel)
 		u.topLevel = topLevel
 
 		uniq := wsc.index.Add(1)
@@ -136,29 +137,32 @@ func (u *XDG_Surface) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessa
 		} else {
 			return fmt.Errorf("top_level: could not find seat")
 		}
-	case 2:
-		new_id := NewUintField()
-		surface := NewUintField()
-		positioner := NewUintField()
-		if err := ParsePacketStructure(packet.Data, new_id, surface, positioner); err != nil {
+	case XdgSurfaceRequestGetPopup:
+		req, err := ParseXdgSurfaceGetPopupRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("get_popup#%d %d %d", uint32(*new_id), *surface, &positioner))
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("get_popup#%d %d %d", req.Id, req.Parent, req.Positioner))
+		if !u.surface.SetRole(XdgPopupInterface) {
+			return NewProtocolError(u.id, XdgSurfaceErrorAlreadyConstructed, "wl_surface#%d already has the %s role", u.surface.id, u.surface.role)
+		}
 
-		if surfaceObj, err := wsc.registry.Get(uint32(*surface)); err != nil {
-			return err
+		if surfaceObj, err := wsc.registry.Get(req.Parent); err != nil {
+			return InvalidObject(packet, req.Parent, XdgSurfaceInterface)
 		} else if parentSurface, ok := surfaceObj.(*XDG_Surface); ok {
 
-			wl_positioner, _ := wsc.registry.Get(uint32(*positioner))
+			wl_positioner, _ := wsc.registry.Get(req.Positioner)
 			if positioner, ok := wl_positioner.(*XDG_Positioner); ok {
 				utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("setting_offset#%v", positioner.anchorRect))
 				u.offset = positioner.CalculateAnchorPoint()
 
-				popup := &XDGPopup{server: u.server, id: uint32(*new_id), parent: parentSurface, surface: u, positioner: positioner}
+				popup := &XDGPopup{server: u.server, id: req.Id, parent: parentSurface, surface: u, positioner: positioner}
 				u.parent = parentSurface
 				u.positioner = positioner
 				u.parent.popup = popup
-				wsc.registry.New(uint32(*new_id), popup)
+				if err := wsc.registry.New(req.Id, popup, wsc.registry.Version(packet.Address)); err != nil {
+					return err
+				}
 
 				// find the parent window...
 				window := u.server.workspace.GetTopLevel(parentSurface.uniq)
@@ -171,25 +175,30 @@ func (u *XDG_Surface) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessa
 				popup.Configure(wsc)
 				return nil
 			}
+			return InvalidObject(packet, req.Positioner, XdgPositionerInterface)
 		}
-		return fmt.Errorf("could not setup xdgpopup")
-	case 3:
-		x := NewIntField()
-		y := NewIntField()
-		w := NewUintField()
-		h := NewUintField()
-		if err := ParsePacketStructure(packet.Data, x, y, w, h); err != nil {
+		return InvalidObject(packet, req.Parent, XdgSurfaceInterface)
+	case XdgSurfaceRequestSetWindowGeometry:
+		req, err := ParseXdgSurfaceSetWindowGeometryRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
-		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("set_window_geometry %d %d %d %d", uint32(*x), uint32(*y), uint32(*w), uint32(*h)))
-		u.windowGeometry = image.Rect(int(*x), int(*y), int(*x)+int(*w), int(*y)+int(*h))
+		utils.Debug(int(wsc.id), fmt.Sprintf("xdg_surface#%d", u.id), fmt.Sprintf("set_window_geometry %d %d %d %d", req.X, req.Y, req.Width, req.Height))
+		u.windowGeometry = image.Rect(int(req.X), int(req.Y), int(req.X)+int(req.Width), int(req.Y)+int(req.Height))
 		return nil
-	case 4:
-		// ack confgure
+	case XdgSurfaceRequestAckConfigure:
+		req, err := ParseXdgSurfaceAckConfigureRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		// only one configure is outstanding at a time, so it must be the last one sent
+		if u.serial == 0 || req.Serial != u.serial {
+			return NewProtocolError(u.id, XdgSurfaceErrorInvalidSerial, "serial %d was not sent in a configure", req.Serial)
+		}
 		u.configuring = false
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on xdg surface object: %v", packet.Data)
+		return UnknownOpcode(packet, XdgSurfaceInterface)
 	}
 
 }
@@ -19,59 +19,80 @@ func (u *XDG_Toplevel) Configure(wsc *WaylandServerConn, width int, height int)
 
 	//if width != u.size.X || height != u.size.Y {
 
-	wsc.SendMessage(NewPacketBuilder(u.id, 0x00).
-		WithUint(uint32(width)).
-		WithUint(uint32(height)).
-		WithUint(0).
-		Build())
+	SendXdgToplevelConfigure(wsc, u.id, int32(width), int32(height), nil)
 	u.size = image.Pt(width, height)
 	//}
 }
 
+// interactive checks the serial of a request that starts an interactive operation, which must come from a
+// press. The operations themselves are not supported as windows are tiled.
+func (u *XDG_Toplevel) interactive(wsc *WaylandServerConn, name string, serial uint32) {
+	if !wsc.validInputSerial(serial) {
+		utils.Debug(int(wsc.id), "xdg_toplevel", fmt.Sprintf("%s refused, invalid serial %d", name, serial))
+		return
+	}
+	utils.Debug(int(wsc.id), "xdg_toplevel", name+" ignored")
+}
+
 func (u *XDG_Toplevel) HandleMessage(wsc *WaylandServerConn, packet *WaylandMessage) error {
 
 	switch packet.Opcode {
-	case 0:
+	case XdgToplevelRequestDestroy:
 		// destroy
 		wsc.registry.Destroy(u.id)
 		return nil
-	case 1:
+	case XdgToplevelRequestSetParent:
 		// set parent
 		return nil
-	case 2:
+	case XdgToplevelRequestSetTitle:
 
-		title := NewStringField()
-		if err := ParsePacketStructure(packet.Data, title); err != nil {
+		req, err := ParseXdgToplevelSetTitleRequest(wsc, packet)
+		if err != nil {
 			return err
 		}
 
-		u.title = string(*title)
+		u.title = req.Title
 		utils.Debug(int(wsc.id), "xdg_toplevel", "set_title: "+u.title)
 		return nil
-	case 3:
+	case XdgToplevelRequestSetAppId:
 		// set app_id
 		return nil
-	case 4:
+	case XdgToplevelRequestShowWindowMenu:
+		req, err := ParseXdgToplevelShowWindowMenuRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		u.interactive(wsc, "show_window_menu", req.Serial)
 		return nil
-	case 5:
+	case XdgToplevelRequestMove:
+		req, err := ParseXdgToplevelMoveRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		u.interactive(wsc, "move", req.Serial)
 		return nil
-	case 6:
+	case XdgToplevelRequestResize:
+		req, err := ParseXdgToplevelResizeRequest(wsc, packet)
+		if err != nil {
+			return err
+		}
+		u.interactive(wsc, "resize", req.Serial)
 		return nil
-	case 7:
+	case XdgToplevelRequestSetMaxSize:
 		return nil
-	case 8:
+	case XdgToplevelRequestSetMinSize:
 		return nil
-	case 9:
+	case XdgToplevelRequestSetMaximized:
 		// maxiimize ignore
 		return nil
-	case 10:
+	case XdgToplevelRequestUnsetMaximized:
 		return nil
-	case 12:
+	case XdgToplevelRequestUnsetFullscreen:
 		return nil
-	case 13:
+	case XdgToplevelRequestSetMinimized:
 		return nil
 	default:
-		return fmt.Errorf("unknown opcode called on xdg top level object: %v", packet.Opcode)
+		return UnknownOpcode(packet, XdgToplevelInterface)
 	}
 
 }
@@ -33,7 +33,7 @@ func (do *DragOverlay) ProcessKeyboardEvent(pointer model.Pointer, kb model.Keyb
 	downKeys := kb.DownKeys()
 	if downKeys[model.KB_CTRL] && downKeys[model.KB_ALT] && downKeys[model.KB_ENTER] {
 		cmd := exec.Command("./elope")
-		cmd.Env = append(cmd.Env, "XDG_RUNTIME_DIR=/tmp/nyctal/", "WAYLAND_DISPLAY=nyctal-0")
+		cmd.Env = append(cmd.Env, "XDG_RUNTIME_DIR="+os.Getenv("XDG_RUNTIME_DIR"), "WAYLAND_DISPLAY="+os.Getenv("WAYLAND_DISPLAY"))
 		go func() {
 			stdoutStderr, err := cmd.CombinedOutput()
 			if err != nil {